@@ -0,0 +1,94 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vilderxyz/todos/service"
+)
+
+// Request object that must contain uri with Id.
+//
+// Id must be greater then 1.
+//
+// Example:
+//
+//	"http://localhost/todos/Id/archive"
+type ArchiveTodoRequest struct {
+	Id int64 `uri:"id" binding:"required,min=1"`
+}
+
+// Finds Todo object from database for given Id. Throws 404 status when not found.
+//
+// Then it archives or unarchives Todo and stores it back in database.
+//
+// It throws 400 status when Todo already is in requested state.
+func (s *Server) setTodoArchived(archived bool) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		req := ArchiveTodoRequest{}
+		if err := ctx.ShouldBindUri(&req); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+			return
+		}
+
+		message := "Archived todo"
+		if !archived {
+			message = "Unarchived todo"
+		}
+
+		res, err := s.todos().Update(ctx.Request.Context(), req.Id, service.Archived(archived), actor(ctx))
+		if err != nil {
+			ctx.JSON(errorStatus(err), errorResponse(ctx, err))
+			return
+		}
+
+		ctx.JSON(http.StatusOK, Response{
+			Message: message,
+			Data:    res,
+		})
+	}
+}
+
+// Request object for archiveDoneTodos.
+//
+// DoneBefore must be a date in given format "yyyy-mm-dd".
+//
+// Otherwise throws 400 status.
+//
+// Example:
+//
+//	{
+//		"done_before":	"2022-12-23"
+//	}
+type ArchiveDoneTodosRequest struct {
+	DoneBefore string `json:"done_before" binding:"required" time_format:"2006-01-02"`
+}
+
+// Archives all Todos finished before given date.
+//
+// Responds with number of archived Todos.
+func (s *Server) archiveDoneTodos(ctx *gin.Context) {
+	req := ArchiveDoneTodosRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	doneBefore, err := time.Parse(service.DateLayout, req.DoneBefore)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	archived, err := s.Queries.ArchiveDoneTodos(ctx.Request.Context(), doneBefore, actor(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Message: "Archived done todos",
+		Data:    gin.H{"archived": archived},
+	})
+}
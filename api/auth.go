@@ -0,0 +1,72 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Returns hash under which API key is stored.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Returns new random API key and its hash.
+func NewAPIKey() (string, string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	key := hex.EncodeToString(buf)
+	return key, HashAPIKey(key), nil
+}
+
+// Returns middleware that authenticates users by X-API-Key header
+// and stores their names in context.
+//
+// Requests without API key are authenticated by client certificate
+// mapped in ClientCertUsers, or are anonymous.
+//
+// API keys are looked up within AuthLimiter of client's IP address,
+// so made up keys cannot flood the database.
+//
+// Throws 429 status when there are too many lookups and
+// 401 status when API key is unknown or its user is disabled.
+func (s *Server) authenticate() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(apiKeyHeader)
+		if key == "" {
+			if user := s.ClientCertUsers.Lookup(ctx.Request.TLS); user != "" {
+				ctx.Set(userContextKey, user)
+			}
+			ctx.Next()
+			return
+		}
+
+		if !allow(ctx, s.AuthLimiter, "ip:"+ctx.ClientIP()) {
+			return
+		}
+
+		user, err := s.Queries.GetUserByKeyHash(ctx.Request.Context(), HashAPIKey(key))
+		if err != nil {
+			if err.Error() == "not found" {
+				ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ctx, fmt.Errorf("invalid api key")))
+				return
+			}
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(ctx, err))
+			return
+		}
+		if user.DisabledAt != nil {
+			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(ctx, fmt.Errorf("user is disabled")))
+			return
+		}
+
+		ctx.Set(userContextKey, user.Name)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,201 @@
+package api
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/certs"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/mock"
+)
+
+func TestAuthenticate(t *testing.T) {
+	disabledAt := time.Now()
+
+	testCases := []struct {
+		name          string
+		apiKey        string
+		clientCert    string
+		buildStubs    func(model *mock.MockDB)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name:   "StatusOK - authenticated user",
+			apiKey: "valid",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetUserByKeyHash(gomock.Any(), gomock.Eq(HashAPIKey("valid"))).
+					Times(1).
+					Return(db.User{Name: "alice"}, nil)
+				model.EXPECT().
+					DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Eq("alice")).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "StatusOK - anonymous client",
+			apiKey: "",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetUserByKeyHash(gomock.Any(), gomock.Any()).
+					Times(0)
+				model.EXPECT().
+					DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "StatusOK - client certificate user",
+			clientCert: "billing",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetUserByKeyHash(gomock.Any(), gomock.Any()).
+					Times(0)
+				model.EXPECT().
+					DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Eq("billing-service")).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "StatusOK - API key takes precedence over client certificate",
+			apiKey:     "valid",
+			clientCert: "billing",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetUserByKeyHash(gomock.Any(), gomock.Eq(HashAPIKey("valid"))).
+					Times(1).
+					Return(db.User{Name: "alice"}, nil)
+				model.EXPECT().
+					DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Eq("alice")).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:       "StatusOK - unmapped client certificate is anonymous",
+			clientCert: "reports",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Not("reports")).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "Unauthorized - unknown key",
+			apiKey: "unknown",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetUserByKeyHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, fmt.Errorf("not found"))
+				model.EXPECT().
+					DeleteOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "Unauthorized - disabled user",
+			apiKey: "disabled",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetUserByKeyHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{Name: "bob", DisabledAt: &disabledAt}, nil)
+				model.EXPECT().
+					DeleteOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalServerError",
+			apiKey: "valid",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetUserByKeyHash(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.User{}, sql.ErrConnDone)
+				model.EXPECT().
+					DeleteOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			model := mock.NewMockDB(ctrl)
+			tc.buildStubs(model)
+
+			server := newTestServer(t, model)
+			server.ClientCertUsers = certs.Users{"billing": "billing-service"}
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodDelete, fmt.Sprintf("/todos/%d", todo.Id), nil)
+			require.NoError(t, err)
+			if tc.apiKey != "" {
+				request.Header.Set(apiKeyHeader, tc.apiKey)
+			}
+			if tc.clientCert != "" {
+				request.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{
+					{{Subject: pkix.Name{CommonName: tc.clientCert}}},
+				}}
+			}
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestNewAPIKey(t *testing.T) {
+	key, hash, err := NewAPIKey()
+	require.NoError(t, err)
+	require.NotEmpty(t, key)
+	require.Equal(t, HashAPIKey(key), hash)
+
+	other, _, err := NewAPIKey()
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+}
@@ -0,0 +1,225 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/service"
+)
+
+// Modes of executing batch operations.
+const (
+	// All operations are executed in a single transaction
+	// and none of them is applied when any fails
+	BatchAtomic = "atomic"
+
+	// Operations are executed independently of each other
+	BatchBestEffort = "best_effort"
+)
+
+// Returned from atomic batch transaction to roll it back.
+var errRollback = errors.New("rollback")
+
+// Single operation of runBatch request.
+//
+// Op must be one of [ "create" , "update" , "delete" , "complete" ].
+//
+// Id must be greater then 1 for all operations except "create".
+//
+// Title, Description and Expiry are required for "create" and "update"
+// and follow the same rules as in createTodo and updateTodoTextInfo.
+//
+// "complete" marks Todo as done.
+type BatchOperation struct {
+	Op          string `json:"op" binding:"required,oneof=create update delete complete"`
+	Id          int64  `json:"id" binding:"omitempty,min=1"`
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Expiry      string `json:"expiry" time_format:"2006-01-02"`
+}
+
+// Request object for runBatch.
+//
+// Mode must be one of [ "atomic" , "best_effort" ] and defaults to "atomic".
+//
+// Operations must contain between 1 and 500 operations.
+//
+// Otherwise throws 400 status.
+//
+// Example:
+//
+//	{
+//		"mode":	"atomic"
+//		"operations": [
+//			{"op": "create", "title": "Clean house", "description": "Kitchen", "expiry": "2022-12-23"},
+//			{"op": "update", "id": 12, "title": "Wash car", "description": "Outside", "expiry": "2022-12-24"},
+//			{"op": "complete", "id": 13},
+//			{"op": "delete", "id": 14}
+//		]
+//	}
+type BatchRequest struct {
+	Mode       string           `json:"mode" binding:"omitempty,oneof=atomic best_effort"`
+	Operations []BatchOperation `json:"operations" binding:"required,min=1,max=500,dive"`
+}
+
+// Result of single batch operation.
+//
+// Status is the http status the operation would get as a single request.
+// In atomic mode operations that were rolled back or not executed
+// because of other operation's failure get 424 status.
+type BatchResult struct {
+	Index  int      `json:"index"`
+	Op     string   `json:"op"`
+	Status int      `json:"status"`
+	Data   *db.Todo `json:"data,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// Executes list of create, update, delete and complete operations.
+//
+// In atomic mode it responds with status of the first failed operation
+// and rolls back all of them. In best effort mode it always responds with 200 status.
+//
+// Results of every operation are sent back in request's order.
+func (s *Server) runBatch(ctx *gin.Context) {
+	req := BatchRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	c := ctx.Request.Context()
+	actor := actor(ctx)
+	results := make([]BatchResult, len(req.Operations))
+
+	if req.Mode == BatchBestEffort {
+		failed := 0
+		for i, op := range req.Operations {
+			results[i] = s.runOperation(c, s.Queries, false, i, op, actor)
+			if results[i].Error != "" {
+				failed++
+			}
+		}
+
+		ctx.JSON(http.StatusOK, Response{
+			Message: fmt.Sprintf("Executed %d operations, %d failed", len(results), failed),
+			Data:    results,
+		})
+		return
+	}
+
+	failed := -1
+	err := s.Queries.WithTx(c, func(tx db.DB) error {
+		for i, op := range req.Operations {
+			results[i] = s.runOperation(c, tx, true, i, op, actor)
+			if results[i].Error != "" {
+				failed = i
+				return errRollback
+			}
+		}
+		return nil
+	})
+
+	if failed >= 0 {
+		for i, op := range req.Operations {
+			switch {
+			case i < failed:
+				results[i] = BatchResult{Index: i, Op: op.Op, Status: http.StatusFailedDependency, Error: "rolled back"}
+			case i > failed:
+				results[i] = BatchResult{Index: i, Op: op.Op, Status: http.StatusFailedDependency, Error: "not executed"}
+			}
+		}
+
+		res := errorResponse(ctx, fmt.Errorf("operation %d failed: %v", failed, results[failed].Error))
+		res["data"] = results
+		ctx.JSON(results[failed].Status, res)
+		return
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Message: fmt.Sprintf("Executed %d operations", len(results)),
+		Data:    results,
+	})
+}
+
+// Executes single batch operation using given queries, which belong to transaction of atomic batch.
+func (s *Server) runOperation(ctx context.Context, queries db.DB, atomic bool, index int, op BatchOperation, actor string) BatchResult {
+	res := BatchResult{Index: index, Op: op.Op, Status: http.StatusOK}
+
+	todo, status, err := s.applyOperation(ctx, queries, atomic, op, actor)
+	if err != nil {
+		res.Status = status
+		res.Error = err.Error()
+		return res
+	}
+	res.Data = todo
+	return res
+}
+
+// Applies single batch operation following the same rules as corresponding handlers.
+//
+// Operations of atomic batch change Todos locked by its transaction,
+// other operations change every Todo in a transaction of their own.
+//
+// Returns modified Todo, or nil for deleted one, and http status of failure.
+func (s *Server) applyOperation(ctx context.Context, queries db.DB, atomic bool, op BatchOperation, actor string) (*db.Todo, int, error) {
+	if op.Op != "create" && op.Id == 0 {
+		return nil, http.StatusBadRequest, fmt.Errorf("id is required")
+	}
+
+	todos := service.New(queries, s.Workflow)
+	update := todos.Update
+	if atomic {
+		update = todos.Apply
+	}
+	var todo db.Todo
+	var err error
+
+	switch op.Op {
+	case "create", "update":
+		var info service.TodoInfo
+		info, err = batchTodoInfo(op)
+		if err != nil {
+			break
+		}
+		if op.Op == "create" {
+			todo, err = todos.CreateTodo(ctx, info, actor)
+		} else {
+			todo, err = update(ctx, op.Id, service.Info(info), actor)
+		}
+
+	case "complete":
+		todo, err = update(ctx, op.Id, service.Done(true), actor)
+
+	case "delete":
+		if err := todos.DeleteTodo(ctx, op.Id, actor); err != nil {
+			return nil, errorStatus(err), err
+		}
+		return nil, http.StatusOK, nil
+	}
+	if err != nil {
+		return nil, errorStatus(err), err
+	}
+	return &todo, http.StatusOK, nil
+}
+
+// Returns information of Todo created or updated by batch operation.
+func batchTodoInfo(op BatchOperation) (service.TodoInfo, error) {
+	expiry, err := service.ParseExpiry(op.Expiry)
+	if err != nil {
+		return service.TodoInfo{}, err
+	}
+	return service.TodoInfo{
+		Title:       op.Title,
+		Description: op.Description,
+		Expiry:      expiry,
+	}, nil
+}
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vilderxyz/todos/db"
+)
+
+// Returns strong ETag of Todos, derived from their Ids and times of their last changes.
+//
+// It changes when any of the Todos changes, and when Todos are added, removed or reordered.
+func todosETag(todos ...db.Todo) string {
+	hash := sha256.New()
+	buf := make([]byte, 16)
+	for _, todo := range todos {
+		binary.BigEndian.PutUint64(buf, uint64(todo.Id))
+		binary.BigEndian.PutUint64(buf[8:], uint64(todo.UpdatedAt.UnixNano()))
+		hash.Write(buf)
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)[:16]) + `"`
+}
+
+// Returns the latest time any of Todos changed, zero when there are none.
+func lastModified(todos ...db.Todo) time.Time {
+	var latest time.Time
+	for _, todo := range todos {
+		if todo.UpdatedAt.After(latest) {
+			latest = todo.UpdatedAt
+		}
+	}
+	return latest
+}
+
+// Sets ETag and Last-Modified headers of response and reports whether client's
+// copy is current, in which case it responds with 304 status.
+//
+// If-None-Match takes precedence. If-Modified-Since is evaluated only without it and
+// when modifiedSince is set, as time of the last change does not reflect removed Todos.
+func notModified(ctx *gin.Context, etag string, modified time.Time, modifiedSince bool) bool {
+	header := ctx.Writer.Header()
+	header.Set("ETag", etag)
+	header.Set("Cache-Control", "no-cache")
+	if !modified.IsZero() {
+		header.Set("Last-Modified", modified.UTC().Format(http.TimeFormat))
+	}
+
+	if match := ctx.GetHeader("If-None-Match"); match != "" {
+		if !etagMatches(match, etag) {
+			return false
+		}
+	} else {
+		since, err := http.ParseTime(ctx.GetHeader("If-Modified-Since"))
+		// Header has precision of seconds
+		if !modifiedSince || err != nil || modified.IsZero() || modified.Truncate(time.Second).After(since) {
+			return false
+		}
+	}
+	ctx.Status(http.StatusNotModified)
+	return true
+}
+
+// Reports whether list of ETags of If-None-Match header matches etag.
+// Weak ETags match their strong equivalents.
+func etagMatches(list, etag string) bool {
+	for _, candidate := range strings.Split(list, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/mock"
+)
+
+func TestConditionalGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	updated := todo
+	updated.UpdatedAt = time.Date(2022, 6, 1, 12, 0, 0, 500, time.UTC)
+	changed := updated
+	changed.UpdatedAt = updated.UpdatedAt.Add(time.Minute)
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		AnyTimes().
+		Return(updated, nil)
+	gomock.InOrder(
+		model.EXPECT().GetAllTodos(gomock.Any()).Times(3).Return([]db.Todo{updated}, nil),
+		model.EXPECT().GetAllTodos(gomock.Any()).Return([]db.Todo{changed}, nil),
+		model.EXPECT().GetAllTodos(gomock.Any()).Return([]db.Todo{}, nil),
+	)
+	server := newTestServer(t, model)
+
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		for name, values := range header {
+			request.Header[name] = values
+		}
+		recorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	path := fmt.Sprintf("/todos/%d", todo.Id)
+	recorder := get(path, nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	etag := recorder.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	require.Equal(t, "Wed, 01 Jun 2022 12:00:00 GMT", recorder.Header().Get("Last-Modified"))
+
+	recorder = get(path, http.Header{"If-None-Match": {`"other", W/` + etag}})
+	require.Equal(t, http.StatusNotModified, recorder.Code)
+	require.Empty(t, recorder.Body.String())
+	require.Equal(t, etag, recorder.Header().Get("ETag"))
+
+	recorder = get(path, http.Header{"If-Modified-Since": {"Wed, 01 Jun 2022 12:00:00 GMT"}})
+	require.Equal(t, http.StatusNotModified, recorder.Code)
+	recorder = get(path, http.Header{"If-Modified-Since": {"Wed, 01 Jun 2022 11:59:59 GMT"}})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// Listings change with every change of their Todos
+	recorder = get("/todos", nil)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	etag = recorder.Header().Get("ETag")
+	require.NotEqual(t, todosETag(updated), todosETag(changed))
+
+	recorder = get("/todos", http.Header{"If-None-Match": {etag}})
+	require.Equal(t, http.StatusNotModified, recorder.Code)
+
+	// Last change of remaining Todos does not show removed ones, so it is not trusted
+	recorder = get("/todos", http.Header{"If-Modified-Since": {"Wed, 01 Jun 2022 12:00:00 GMT"}})
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = get("/todos", http.Header{"If-None-Match": {etag}})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NotEqual(t, etag, recorder.Header().Get("ETag"))
+
+	recorder = get("/todos", http.Header{"If-None-Match": {etag}})
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, recorder.Header().Get("Last-Modified"))
+}
@@ -0,0 +1,567 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/service"
+)
+
+const (
+	// Default maximum nesting of fields in GraphQL query
+	DefaultGraphQLMaxDepth = 6
+
+	// Default maximum complexity of GraphQL query
+	DefaultGraphQLMaxComplexity = 5000
+
+	// Expected number of elements of list fields used to compute complexity
+	listComplexityFactor = 10
+)
+
+// Request object for graphQL sent with POST.
+//
+// Query is required, OperationName selects one of operations defined in Query.
+//
+// Example:
+//
+//	{
+//		"query":	"query($id: ID!) { todo(id: $id) { title history { actor } } }",
+//		"variables":	{"id": "123"}
+//	}
+type GraphQLRequest struct {
+	Query         string         `json:"query" binding:"required"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+// Request object for graphQL sent with GET, it cannot run mutations.
+//
+// Variables are encoded as JSON object.
+//
+// Example:
+//
+//	"http://localhost/graphql?query={todos(period:TODAY){id title}}"
+type GraphQLQueryRequest struct {
+	Query         string `form:"query" binding:"required"`
+	OperationName string `form:"operationName"`
+	Variables     string `form:"variables"`
+}
+
+// Context keys of values available to resolvers
+type (
+	actorContextKey   struct{}
+	loadersContextKey struct{}
+)
+
+// Executes GraphQL query or mutation.
+//
+// Throws 400 status with GraphQL errors when request is invalid
+// or exceeds depth or complexity limit, and 405 status for mutations sent with GET.
+// Errors of resolvers are sent with 200 status next to resolved data.
+func (s *Server) graphQL(ctx *gin.Context) {
+	req := GraphQLRequest{}
+	if ctx.Request.Method == http.MethodGet {
+		query := GraphQLQueryRequest{}
+		if err := ctx.ShouldBindQuery(&query); err != nil {
+			ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+			return
+		}
+		req.Query = query.Query
+		req.OperationName = query.OperationName
+		if query.Variables != "" {
+			if err := json.Unmarshal([]byte(query.Variables), &req.Variables); err != nil {
+				ctx.JSON(http.StatusBadRequest, errorResponse(ctx, fmt.Errorf("invalid variables: %w", err)))
+				return
+			}
+		}
+	} else if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+	if res := graphql.ValidateDocument(&s.schema, doc, nil); !res.IsValid {
+		ctx.JSON(http.StatusBadRequest, graphql.Result{Errors: res.Errors})
+		return
+	}
+
+	op := findOperation(doc, req.OperationName)
+	if op == nil {
+		ctx.JSON(http.StatusBadRequest, graphql.Result{Errors: gqlerrors.FormatErrors(fmt.Errorf("unknown operation %q", req.OperationName))})
+		return
+	}
+	if op.Operation != ast.OperationTypeQuery && ctx.Request.Method != http.MethodPost {
+		ctx.JSON(http.StatusMethodNotAllowed, graphql.Result{Errors: gqlerrors.FormatErrors(fmt.Errorf("%v must be sent with POST", op.Operation))})
+		return
+	}
+	if err := s.checkGraphQLLimits(doc, op); err != nil {
+		ctx.JSON(http.StatusBadRequest, graphql.Result{Errors: gqlerrors.FormatErrors(err)})
+		return
+	}
+
+	c := context.WithValue(ctx.Request.Context(), actorContextKey{}, actor(ctx))
+	c = context.WithValue(c, loadersContextKey{}, newLoaders(s.Queries))
+
+	ctx.JSON(http.StatusOK, graphql.Execute(graphql.ExecuteParams{
+		Schema:        s.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       c,
+	}))
+}
+
+// Returns operation of document with given name, or the only one when name is empty.
+func findOperation(doc *ast.Document, name string) *ast.OperationDefinition {
+	var found *ast.OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		if name == "" {
+			if found != nil {
+				return nil
+			}
+			found = op
+		} else if op.Name != nil && op.Name.Value == name {
+			return op
+		}
+	}
+	return found
+}
+
+// Throws an error when operation nests fields deeper than GraphQLMaxDepth
+// or its complexity exceeds GraphQLMaxComplexity.
+//
+// Every field costs 1 and fields of lists cost listComplexityFactor times more.
+// Introspection fields are free. Zero or negative limit disables the check.
+func (s *Server) checkGraphQLLimits(doc *ast.Document, op *ast.OperationDefinition) error {
+	fragments := map[string]*ast.FragmentDefinition{}
+	for _, def := range doc.Definitions {
+		if fragment, ok := def.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	root := s.schema.QueryType()
+	if op.Operation == ast.OperationTypeMutation {
+		root = s.schema.MutationType()
+	}
+
+	walker := &limitWalker{schema: s.schema, fragments: fragments, visiting: map[string]bool{}}
+	depth, complexity := walker.walk(root, op.SelectionSet)
+
+	if s.GraphQLMaxDepth > 0 && depth > s.GraphQLMaxDepth {
+		return fmt.Errorf("query depth %d exceeds limit of %d", depth, s.GraphQLMaxDepth)
+	}
+	if s.GraphQLMaxComplexity > 0 && complexity > s.GraphQLMaxComplexity {
+		return fmt.Errorf("query complexity %d exceeds limit of %d", complexity, s.GraphQLMaxComplexity)
+	}
+	return nil
+}
+
+// Measures depth and complexity of GraphQL selections.
+type limitWalker struct {
+	schema    graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+
+	// Fragments being walked, so cycles are not followed
+	visiting map[string]bool
+}
+
+// Returns depth and complexity of selection set of given type.
+func (w *limitWalker) walk(parent *graphql.Object, set *ast.SelectionSet) (int, int) {
+	if set == nil || parent == nil {
+		return 0, 0
+	}
+
+	depth, complexity := 0, 0
+	add := func(d, c int) {
+		if d > depth {
+			depth = d
+		}
+		complexity += c
+	}
+
+	for _, selection := range set.Selections {
+		switch selection := selection.(type) {
+		case *ast.Field:
+			def, ok := parent.Fields()[selection.Name.Value]
+			if !ok {
+				// Introspection fields
+				continue
+			}
+
+			factor := 1
+			t := def.Type
+			if nonNull, ok := t.(*graphql.NonNull); ok {
+				t = nonNull.OfType
+			}
+			if list, ok := t.(*graphql.List); ok {
+				factor = listComplexityFactor
+				t = list.OfType
+				if nonNull, ok := t.(*graphql.NonNull); ok {
+					t = nonNull.OfType
+				}
+			}
+			object, _ := t.(*graphql.Object)
+
+			d, c := w.walk(object, selection.SelectionSet)
+			add(d+1, 1+factor*c)
+
+		case *ast.InlineFragment:
+			object := parent
+			if selection.TypeCondition != nil {
+				object, _ = w.schema.Type(selection.TypeCondition.Name.Value).(*graphql.Object)
+			}
+			add(w.walk(object, selection.SelectionSet))
+
+		case *ast.FragmentSpread:
+			name := selection.Name.Value
+			fragment, ok := w.fragments[name]
+			if !ok || w.visiting[name] {
+				continue
+			}
+			object, _ := w.schema.Type(fragment.TypeCondition.Name.Value).(*graphql.Object)
+
+			w.visiting[name] = true
+			add(w.walk(object, fragment.SelectionSet))
+			delete(w.visiting, name)
+		}
+	}
+	return depth, complexity
+}
+
+// Returns name of the actor performing GraphQL request.
+func graphQLActor(ctx context.Context) string {
+	actor, _ := ctx.Value(actorContextKey{}).(string)
+	return actor
+}
+
+// Returns Id given as GraphQL ID argument.
+func idArgument(args map[string]any) (int64, error) {
+	value, _ := args["id"].(string)
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid id %q", value)
+	}
+	return id, nil
+}
+
+// Single changed field of history entry, values are encoded as JSON.
+type graphQLChange struct {
+	Field  string
+	Before string
+	After  string
+}
+
+// Returns changes of history entry ordered by field names.
+func graphQLChanges(changes db.Changes) ([]graphQLChange, error) {
+	res := make([]graphQLChange, 0, len(changes))
+	for field, change := range changes {
+		before, err := json.Marshal(change.Before)
+		if err != nil {
+			return nil, err
+		}
+		after, err := json.Marshal(change.After)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, graphQLChange{field, string(before), string(after)})
+	}
+	sort.Slice(res, func(i, j int) bool { return res[i].Field < res[j].Field })
+	return res, nil
+}
+
+// Returns GraphQL schema of Todos resolved with server's queries.
+//
+// Panics when schema is invalid.
+func (s *Server) graphQLSchema() graphql.Schema {
+	changeType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Change",
+		Description: "Value of single field before and after the change, encoded as JSON",
+		Fields: graphql.Fields{
+			"field":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"before": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"after":  &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	// Fields without resolver are resolved from struct fields of the same name
+	historyType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "HistoryEntry",
+		Description: "Single change of todo",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"actor":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"operation": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"changes": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(changeType))),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return graphQLChanges(p.Source.(db.History).Changes)
+				},
+			},
+		},
+	})
+
+	transitionType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Transition",
+		Description: "Move of todo between statuses",
+		Fields: graphql.Fields{
+			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"from":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"to":        &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"actor":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	todoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Todo",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
+			"title":       &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"description": &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"completion":  &graphql.Field{Type: graphql.NewNonNull(graphql.Float)},
+			"expiry":      &graphql.Field{Type: graphql.NewNonNull(graphql.DateTime)},
+			"isDone":      &graphql.Field{Type: graphql.NewNonNull(graphql.Boolean)},
+			"doneAt":      &graphql.Field{Type: graphql.DateTime},
+			"archivedAt":  &graphql.Field{Type: graphql.DateTime},
+			"status": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					return service.CurrentStatus(s.Workflow, p.Source.(db.Todo)), nil
+				},
+			},
+			"history": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(historyType))),
+				Description: "All changes of todo from the oldest one",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					loaders := p.Context.Value(loadersContextKey{}).(*loaders)
+					return loaders.history.load(p.Context, p.Source.(db.Todo).Id), nil
+				},
+			},
+			"transitions": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(transitionType))),
+				Description: "All status transitions of todo from the oldest one",
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					loaders := p.Context.Value(loadersContextKey{}).(*loaders)
+					return loaders.transitions.load(p.Context, p.Source.(db.Todo).Id), nil
+				},
+			},
+		},
+	})
+
+	periodType := graphql.NewEnum(graphql.EnumConfig{
+		Name:        "Period",
+		Description: "Period in which unfinished todos expire",
+		Values: graphql.EnumValueConfigMap{
+			"TODAY":    &graphql.EnumValueConfig{Value: "today"},
+			"TOMORROW": &graphql.EnumValueConfig{Value: "tomorrow"},
+			"WEEK":     &graphql.EnumValueConfig{Value: "week"},
+			"ALL":      &graphql.EnumValueConfig{Value: ""},
+		},
+	})
+
+	idArgs := graphql.FieldConfigArgument{
+		"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+	}
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"todos": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(todoType))),
+				Description: "Todos listed the same way as by GET /todos",
+				Args: graphql.FieldConfigArgument{
+					"period":   &graphql.ArgumentConfig{Type: periodType, DefaultValue: ""},
+					"archived": &graphql.ArgumentConfig{Type: graphql.Boolean, DefaultValue: false},
+				},
+				Resolve: s.resolveTodos,
+			},
+			"todo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: idArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, err := idArgument(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					if err := Validate(GetTodoByIdRequest{Id: id}); err != nil {
+						return nil, err
+					}
+					return s.todos().GetTodo(p.Context, id)
+				},
+			},
+		},
+	})
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"title":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"description": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"expiry":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String), Description: "Future date in format yyyy-mm-dd"},
+				},
+				Resolve: s.resolveCreateTodo,
+			},
+			"updateTodo": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"id":          &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"title":       &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"description": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"expiry":      &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String), Description: "Future date in format yyyy-mm-dd"},
+				},
+				Resolve: s.resolveUpdateTodo,
+			},
+			"updateCompletion": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"id":         &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"completion": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Float)},
+				},
+				Resolve: s.resolveUpdateCompletion,
+			},
+			"setDone": &graphql.Field{
+				Type: graphql.NewNonNull(todoType),
+				Args: graphql.FieldConfigArgument{
+					"id":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"done": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.Boolean), Description: "False reopens finished todo"},
+				},
+				Resolve: s.resolveSetDone,
+			},
+			"deleteTodo": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.ID),
+				Description: "Moves todo to trash and returns its id",
+				Args:        idArgs,
+				Resolve: func(p graphql.ResolveParams) (any, error) {
+					id, err := idArgument(p.Args)
+					if err != nil {
+						return nil, err
+					}
+					if err := Validate(DeleteTodoRequest{Id: id}); err != nil {
+						return nil, err
+					}
+					if err := s.todos().DeleteTodo(p.Context, id, graphQLActor(p.Context)); err != nil {
+						return nil, err
+					}
+					return strconv.FormatInt(id, 10), nil
+				},
+			},
+		},
+	})
+
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query:    query,
+		Mutation: mutation,
+	})
+	if err != nil {
+		panic(fmt.Sprintf("invalid graphql schema: %v", err))
+	}
+	return schema
+}
+
+// Lists Todos following the same rules as getTodos.
+func (s *Server) resolveTodos(p graphql.ResolveParams) (any, error) {
+	params := service.ListParams{}
+	params.Period, _ = p.Args["period"].(string)
+	params.Archived, _ = p.Args["archived"].(bool)
+
+	return s.todos().ListTodos(p.Context, params)
+}
+
+// Creates Todo following the same rules as createTodo.
+func (s *Server) resolveCreateTodo(p graphql.ResolveParams) (any, error) {
+	req := CreateTodoRequest{}
+	req.Title, _ = p.Args["title"].(string)
+	req.Description, _ = p.Args["description"].(string)
+	req.Expiry, _ = p.Args["expiry"].(string)
+	if err := Validate(req); err != nil {
+		return nil, err
+	}
+	expiry, err := service.ParseExpiry(req.Expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.todos().CreateTodo(p.Context, service.TodoInfo{
+		Title:       req.Title,
+		Description: req.Description,
+		Expiry:      expiry,
+	}, graphQLActor(p.Context))
+}
+
+// Updates Todo following the same rules as updateTodoTextInfo.
+func (s *Server) resolveUpdateTodo(p graphql.ResolveParams) (any, error) {
+	id, err := idArgument(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	req := UpdateTodoInfoRequest{Id: id}
+	req.Title, _ = p.Args["title"].(string)
+	req.Description, _ = p.Args["description"].(string)
+	req.Expiry, _ = p.Args["expiry"].(string)
+	if err := Validate(req); err != nil {
+		return nil, err
+	}
+	expiry, err := service.ParseExpiry(req.Expiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.todos().Update(p.Context, id, service.Info(service.TodoInfo{
+		Title:       req.Title,
+		Description: req.Description,
+		Expiry:      expiry,
+	}), graphQLActor(p.Context))
+}
+
+// Updates Todo following the same rules as updateTodoCompletionInfo.
+func (s *Server) resolveUpdateCompletion(p graphql.ResolveParams) (any, error) {
+	id, err := idArgument(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	completion, _ := p.Args["completion"].(float64)
+	req := UpdateTodoCompletionRequest{Id: id, Completion: float32(completion)}
+	if err := Validate(req); err != nil {
+		return nil, err
+	}
+
+	return s.todos().Update(p.Context, id, service.Completion(req.Completion), graphQLActor(p.Context))
+}
+
+// Updates Todo following the same rules as updateTodoDoneInfo.
+func (s *Server) resolveSetDone(p graphql.ResolveParams) (any, error) {
+	id, err := idArgument(p.Args)
+	if err != nil {
+		return nil, err
+	}
+	done, _ := p.Args["done"].(bool)
+	req := UpdateTodoDoneRequest{Id: id, IsDone: &done}
+	if err := Validate(req); err != nil {
+		return nil, err
+	}
+
+	return s.todos().Update(p.Context, id, service.Done(done), graphQLActor(p.Context))
+}
@@ -0,0 +1,232 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/mock"
+	"github.com/vilderxyz/todos/service"
+)
+
+// Body of GraphQL response.
+type graphQLResponse struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Sends GraphQL request with given method and returns status and decoded response.
+func sendGraphQL(t *testing.T, server *Server, method, query string, variables map[string]any) (int, graphQLResponse) {
+	var request *http.Request
+	var err error
+	if method == http.MethodGet {
+		request, err = http.NewRequest(method, "/graphql?query="+url.QueryEscape(query), nil)
+	} else {
+		body, _ := json.Marshal(GraphQLRequest{Query: query, Variables: variables})
+		request, err = http.NewRequest(method, "/graphql", bytes.NewReader(body))
+	}
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	server.Router.ServeHTTP(recorder, request)
+
+	var res graphQLResponse
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res), recorder.Body.String())
+	return recorder.Code, res
+}
+
+func TestGraphQLQueries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	server := newTestServer(t, model)
+
+	todos := []db.Todo{todo, todo, todo}
+	for i := range todos {
+		todos[i].Id = int64(i + 1)
+	}
+
+	// Related data of all todos is loaded with a single query
+	model.EXPECT().
+		GetAllTodos(gomock.Any()).
+		Times(1).
+		Return(todos, nil)
+	model.EXPECT().
+		GetHistoryOfTodos(gomock.Any(), gomock.Eq([]int64{1, 2, 3})).
+		Times(1).
+		Return([]db.History{
+			{Id: 1, TodoId: 1, Actor: "alice", Operation: db.OpCreate, Changes: db.Changes{"title": {After: "title"}}},
+			{Id: 2, TodoId: 3, Actor: "bob", Operation: db.OpCreate},
+		}, nil)
+	model.EXPECT().
+		GetTransitionsOfTodos(gomock.Any(), gomock.Eq([]int64{1, 2, 3})).
+		Times(1).
+		Return([]db.Transition{{Id: 1, TodoId: 2, To: "backlog", Actor: "alice"}}, nil)
+
+	status, res := sendGraphQL(t, server, http.MethodGet, `{
+		todos {
+			id
+			title
+			status
+			history { actor changes { field before after } }
+			transitions { to }
+		}
+	}`, nil)
+	require.Equal(t, http.StatusOK, status)
+	require.Empty(t, res.Errors)
+
+	list := res.Data["todos"].([]any)
+	require.Len(t, list, 3)
+	first := list[0].(map[string]any)
+	require.Equal(t, "1", first["id"])
+	require.Equal(t, todo.Title, first["title"])
+	require.Equal(t, "backlog", first["status"])
+	require.Equal(t, []any{map[string]any{
+		"actor":   "alice",
+		"changes": []any{map[string]any{"field": "title", "before": "null", "after": `"title"`}},
+	}}, first["history"])
+	require.Empty(t, first["transitions"])
+	require.Len(t, list[1].(map[string]any)["transitions"], 1)
+	require.Len(t, list[2].(map[string]any)["history"], 1)
+
+	model.EXPECT().
+		GetManyTodos(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, start, end time.Time) ([]db.Todo, error) {
+			require.Equal(t, service.PeriodEnd(service.Tomorrow, start), end)
+			return todos[:1], nil
+		})
+	status, res = sendGraphQL(t, server, http.MethodPost, `{ todos(period: TOMORROW) { id } }`, nil)
+	require.Equal(t, http.StatusOK, status)
+	require.Len(t, res.Data["todos"], 1)
+
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		Times(1).
+		Return(db.Todo{}, fmt.Errorf("not found"))
+	status, res = sendGraphQL(t, server, http.MethodPost, `query($id: ID!) { todo(id: $id) { id } }`, map[string]any{
+		"id": strconv.FormatInt(todo.Id, 10),
+	})
+	require.Equal(t, http.StatusOK, status)
+	require.Nil(t, res.Data)
+	require.Equal(t, "not found", res.Errors[0].Message)
+
+	status, res = sendGraphQL(t, server, http.MethodPost, `{ todos(period: TODAY, archived: true) { id } }`, nil)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "period cannot be used with archived", res.Errors[0].Message)
+}
+
+func TestGraphQLMutations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	server := newTestServer(t, model)
+
+	expiry := time.Now().AddDate(0, 0, 2).Format("2006-01-02")
+	create := fmt.Sprintf(`mutation { createTodo(title: "title", description: "desc", expiry: %q) { id status } }`, expiry)
+
+	// Mutations cannot be sent with GET
+	status, res := sendGraphQL(t, server, http.MethodGet, create, nil)
+	require.Equal(t, http.StatusMethodNotAllowed, status)
+	require.NotEmpty(t, res.Errors)
+
+	model.EXPECT().
+		CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, params db.CreateTodoParams, actor string) (db.Todo, error) {
+			require.Equal(t, "title", params.Title)
+			require.Equal(t, server.Workflow.Initial(), params.Status)
+			return db.Todo{Id: 7, Status: params.Status}, nil
+		})
+	status, res = sendGraphQL(t, server, http.MethodPost, create, nil)
+	require.Equal(t, http.StatusOK, status)
+	require.Empty(t, res.Errors)
+	require.Equal(t, map[string]any{"id": "7", "status": "backlog"}, res.Data["createTodo"])
+
+	// Validation rules of http API
+	status, res = sendGraphQL(t, server, http.MethodPost, `mutation { createTodo(title: "", description: "desc", expiry: "2020-01-01") { id } }`, nil)
+	require.Equal(t, http.StatusOK, status)
+	require.NotEmpty(t, res.Errors)
+
+	// Every update runs in its own transaction
+	expectTx(model)
+	expectTx(model)
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		Times(2).
+		Return(todo, nil)
+	model.EXPECT().
+		UpdateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, todo db.Todo, actor string) (db.Todo, error) {
+			return todo, nil
+		})
+	status, res = sendGraphQL(t, server, http.MethodPost, `mutation { setDone(id: "123", done: true) { isDone status } }`, nil)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, map[string]any{"isDone": true, "status": "done"}, res.Data["setDone"])
+
+	status, res = sendGraphQL(t, server, http.MethodPost, `mutation { updateCompletion(id: "123", completion: 10) { completion } }`, nil)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "requsted completion progress is lower then the actual one", res.Errors[0].Message)
+
+	model.EXPECT().
+		DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+		Times(1).
+		Return(nil)
+	status, res = sendGraphQL(t, server, http.MethodPost, `mutation { deleteTodo(id: "123") }`, nil)
+	require.Equal(t, http.StatusOK, status)
+	require.Equal(t, "123", res.Data["deleteTodo"])
+}
+
+func TestGraphQLLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mock.NewMockDB(ctrl))
+
+	status, res := sendGraphQL(t, server, http.MethodPost, `{ todos { id `, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.NotEmpty(t, res.Errors)
+
+	status, res = sendGraphQL(t, server, http.MethodPost, `{ todos { unknown } }`, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.NotEmpty(t, res.Errors)
+
+	server.GraphQLMaxDepth = 2
+	status, res = sendGraphQL(t, server, http.MethodPost, `{ todos { history { actor } } }`, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Equal(t, "query depth 3 exceeds limit of 2", res.Errors[0].Message)
+
+	// Depth of fragments counts too
+	status, res = sendGraphQL(t, server, http.MethodPost, `
+		{ todos { ...related } }
+		fragment related on Todo { transitions { to } }
+	`, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Equal(t, "query depth 3 exceeds limit of 2", res.Errors[0].Message)
+
+	// Every aliased list multiplies complexity
+	server.GraphQLMaxDepth = 0
+	server.GraphQLMaxComplexity = 50
+	status, res = sendGraphQL(t, server, http.MethodPost, `{
+		a: todos { id title }
+		b: todos { id title }
+		c: todos { id title }
+	}`, nil)
+	require.Equal(t, http.StatusBadRequest, status)
+	require.Equal(t, "query complexity 63 exceeds limit of 50", res.Errors[0].Message)
+}
@@ -0,0 +1,167 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/worker"
+	"gorm.io/gorm"
+)
+
+// Default time limit of a single readiness check.
+const DefaultHealthCheckTimeout = 2 * time.Second
+
+// Statuses of readiness checks and the whole application.
+const (
+	healthOK       = "ok"
+	healthFailing  = "failing"
+	healthDraining = "draining"
+)
+
+// Check of a dependency the application needs to serve requests.
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Result of a single readiness check.
+type healthCheckResult struct {
+	Status   string `json:"status"`
+	Error    string `json:"error,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// Body of readiness responses.
+type readinessResponse struct {
+	Status string                       `json:"status"`
+	Checks map[string]healthCheckResult `json:"checks,omitempty"`
+}
+
+// Returns check pinging database through connection pool.
+func DatabaseCheck(conn *gorm.DB) HealthCheck {
+	return HealthCheck{
+		Name: "database",
+		Check: func(ctx context.Context) error {
+			sqlDB, err := conn.DB()
+			if err != nil {
+				return err
+			}
+			return sqlDB.PingContext(ctx)
+		},
+	}
+}
+
+// Returns check failing while any migration is not applied.
+func MigrationsCheck(migrator *db.Migrator) HealthCheck {
+	return HealthCheck{
+		Name: "migrations",
+		Check: func(ctx context.Context) error {
+			pending, err := migrator.Pending(ctx)
+			if err != nil {
+				return err
+			}
+			if len(pending) > 0 {
+				last := pending[len(pending)-1]
+				return fmt.Errorf("%d migrations pending, up to %d_%v", len(pending), last.Version, last.Name)
+			}
+			return nil
+		},
+	}
+}
+
+// Returns check failing when any of background workers is stopped.
+func WorkersCheck(workers ...*worker.Worker) HealthCheck {
+	return HealthCheck{
+		Name: "workers",
+		Check: func(ctx context.Context) error {
+			for _, w := range workers {
+				if !w.Running() {
+					return fmt.Errorf("worker %v is stopped", w.Name())
+				}
+			}
+			return nil
+		},
+	}
+}
+
+// Responds with 200 status while the process is able to handle requests.
+//
+// It does not check any dependency, so failing database does not get the app restarted.
+func (s *Server) getHealth(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"status": healthOK})
+}
+
+// Responds with results of all readiness checks.
+//
+// Returns 200 status when all checks pass.
+//
+// Throws 503 status when any check fails or takes longer than HealthCheckTimeout,
+// and during graceful shutdown without running the checks.
+func (s *Server) getReadiness(ctx *gin.Context) {
+	if atomic.LoadInt32(&s.draining) == 1 {
+		ctx.JSON(http.StatusServiceUnavailable, readinessResponse{Status: healthDraining})
+		return
+	}
+
+	res := readinessResponse{Status: healthOK, Checks: make(map[string]healthCheckResult, len(s.ReadinessChecks))}
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for _, check := range s.ReadinessChecks {
+		wg.Add(1)
+		go func(check HealthCheck) {
+			defer wg.Done()
+			result := s.runHealthCheck(ctx.Request.Context(), check)
+
+			mu.Lock()
+			defer mu.Unlock()
+			res.Checks[check.Name] = result
+			if result.Status != healthOK {
+				res.Status = healthFailing
+			}
+		}(check)
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if res.Status != healthOK {
+		status = http.StatusServiceUnavailable
+	}
+	ctx.JSON(status, res)
+}
+
+// Runs check limited by HealthCheckTimeout and returns its result.
+func (s *Server) runHealthCheck(ctx context.Context, check HealthCheck) healthCheckResult {
+	if s.HealthCheckTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.HealthCheckTimeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	done := make(chan error, 1)
+	go func() {
+		done <- check.Check(ctx)
+	}()
+
+	// Checks ignoring ctx do not hold the response
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := healthCheckResult{Status: healthOK, Duration: time.Since(start).String()}
+	if err != nil {
+		result.Status = healthFailing
+		result.Error = err.Error()
+	}
+	return result
+}
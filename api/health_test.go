@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	server := NewServer(nil)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/healthz", nil)
+	require.NoError(t, err)
+	server.Router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.JSONEq(t, `{"status": "ok"}`, recorder.Body.String())
+}
+
+func TestReadiness(t *testing.T) {
+	passing := HealthCheck{Name: "passing", Check: func(ctx context.Context) error { return nil }}
+	failing := HealthCheck{Name: "failing", Check: func(ctx context.Context) error { return errors.New("broken") }}
+	// Ignores its context, so it must be cut off by the server
+	hanging := HealthCheck{Name: "hanging", Check: func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	}}
+
+	testCases := []struct {
+		name   string
+		checks []HealthCheck
+		status int
+		body   readinessResponse
+	}{
+		{
+			name:   "OK",
+			checks: []HealthCheck{passing},
+			status: http.StatusOK,
+			body: readinessResponse{Status: healthOK, Checks: map[string]healthCheckResult{
+				"passing": {Status: healthOK},
+			}},
+		},
+		{
+			name:   "Failing",
+			checks: []HealthCheck{passing, failing},
+			status: http.StatusServiceUnavailable,
+			body: readinessResponse{Status: healthFailing, Checks: map[string]healthCheckResult{
+				"passing": {Status: healthOK},
+				"failing": {Status: healthFailing, Error: "broken"},
+			}},
+		},
+		{
+			name:   "Timeout",
+			checks: []HealthCheck{hanging},
+			status: http.StatusServiceUnavailable,
+			body: readinessResponse{Status: healthFailing, Checks: map[string]healthCheckResult{
+				"hanging": {Status: healthFailing, Error: context.DeadlineExceeded.Error()},
+			}},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			server := NewServer(nil)
+			server.ReadinessChecks = tc.checks
+			server.HealthCheckTimeout = 50 * time.Millisecond
+
+			recorder := httptest.NewRecorder()
+			request, err := http.NewRequest(http.MethodGet, "/readyz", nil)
+			require.NoError(t, err)
+			server.Router.ServeHTTP(recorder, request)
+			require.Equal(t, tc.status, recorder.Code)
+
+			var body readinessResponse
+			require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &body))
+			for name, result := range body.Checks {
+				require.NotEmpty(t, result.Duration)
+				result.Duration = ""
+				body.Checks[name] = result
+			}
+			require.Equal(t, tc.body, body)
+		})
+	}
+}
+
+func TestReadinessDuringShutdown(t *testing.T) {
+	server := NewServer(nil)
+	server.ReadinessChecks = []HealthCheck{{Name: "passing", Check: func(ctx context.Context) error { return nil }}}
+	server.ShutdownDelay = 100 * time.Millisecond
+
+	require.NoError(t, server.Listen("127.0.0.1:0"))
+	go server.Serve()
+	url := "http://" + server.Addr().String() + "/readyz"
+
+	response, err := http.Get(url)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	shutdown := make(chan error, 1)
+	go func() {
+		shutdown <- server.Shutdown(context.Background())
+	}()
+
+	// Server keeps accepting requests for ShutdownDelay, failing readiness
+	require.Eventually(t, func() bool {
+		response, err := http.Get(url)
+		if err != nil {
+			return false
+		}
+		defer response.Body.Close()
+
+		var body readinessResponse
+		json.NewDecoder(response.Body).Decode(&body)
+		return response.StatusCode == http.StatusServiceUnavailable && body.Status == healthDraining
+	}, time.Second, 10*time.Millisecond)
+
+	require.NoError(t, <-shutdown)
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vilderxyz/todos/db"
+)
+
+// Default number of history entries on a single page.
+const defaultPerPage = 20
+
+// Returns name of the actor performing the request, recorded in Todo's history.
+//
+// It is authenticated user's name when available.
+// Otherwise it is the same key that identifies client for rate limiting.
+func actor(ctx *gin.Context) string {
+	if user := ctx.GetString(userContextKey); user != "" {
+		return user
+	}
+	return clientKey(ctx)
+}
+
+// Request object for getTodoHistory.
+//
+// Id must be greater then 1 and is taken from uri.
+//
+// Page and PerPage are optional query params.
+// Page must be greater then 1 and PerPage between 1 and 100.
+//
+// Otherwise throws 400 status.
+//
+// Example:
+//
+//	"http://localhost/todos/Id/history?page=2&per_page=10"
+type GetTodoHistoryRequest struct {
+	Id      int64 `uri:"id" binding:"required,min=1"`
+	Page    int   `form:"page" binding:"omitempty,min=1"`
+	PerPage int   `form:"per_page" binding:"omitempty,min=1,max=100"`
+}
+
+// Single page of Todo's history.
+type HistoryPage struct {
+	Entries []db.History `json:"entries"`
+	Page    int          `json:"page"`
+	PerPage int          `json:"per_page"`
+	Total   int64        `json:"total"`
+}
+
+// Returns page of history entries for Todo with given Id, from the oldest one.
+//
+// History is kept after Todo is deleted.
+//
+// Throws 404 status when Todo has no history at all.
+func (s *Server) getTodoHistory(ctx *gin.Context) {
+	req := GetTodoHistoryRequest{}
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.PerPage == 0 {
+		req.PerPage = defaultPerPage
+	}
+
+	entries, total, err := s.Queries.GetTodoHistory(ctx.Request.Context(), req.Id, req.PerPage, (req.Page-1)*req.PerPage)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return
+	}
+	if total == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(ctx, fmt.Errorf("not found")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Message: "Got todo's history",
+		Data: HistoryPage{
+			Entries: entries,
+			Page:    req.Page,
+			PerPage: req.PerPage,
+			Total:   total,
+		},
+	})
+}
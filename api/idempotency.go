@@ -0,0 +1,148 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vilderxyz/todos/idempotency"
+)
+
+const (
+	// Header that carries client's idempotency key
+	idempotencyKeyHeader = "Idempotency-Key"
+
+	// Header set on responses replayed for retried requests
+	idempotentReplayedHeader = "Idempotent-Replayed"
+
+	// Maximum length of idempotency key
+	maxIdempotencyKeyLength = 255
+
+	// Maximum size of body of request with idempotency key, enough for the largest batch
+	maxIdempotentBodySize = 1 << 20
+)
+
+// Default time after which idempotency keys can be reused.
+var DefaultIdempotencyTTL = 24 * time.Hour
+
+// Captures response body, so it can be stored for retried requests.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Returns fingerprint of request's method, path and body.
+func fingerprint(ctx *gin.Context, body []byte) string {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%v %v\n", ctx.Request.Method, ctx.Request.URL.Path)
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// Returns middleware that makes requests with Idempotency-Key header safe to retry.
+//
+// Keys are scoped to the client. Response of the first request is stored
+// and replayed for every retry with the same key until the key expires.
+//
+// Throws 409 status when request with the same key is still in progress,
+// 413 status when its body is larger than 1 MiB
+// and 422 status when the key was used for a different request.
+//
+// Responses with 5xx statuses are not stored, so such requests can be retried.
+func (s *Server) idempotent() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		key := ctx.GetHeader(idempotencyKeyHeader)
+		if key == "" {
+			ctx.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(ctx, fmt.Errorf("idempotency key is too long")))
+			return
+		}
+
+		ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, maxIdempotentBodySize)
+		body, err := io.ReadAll(ctx.Request.Body)
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			ctx.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, errorResponse(ctx, fmt.Errorf("request body is too large")))
+			return
+		}
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusBadRequest, errorResponse(ctx, err))
+			return
+		}
+		ctx.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key = clientKey(ctx) + ":" + key
+		sum := fingerprint(ctx, body)
+
+		record, reserved, err := s.IdempotencyStore.Reserve(key, sum, s.IdempotencyTTL)
+		if err != nil {
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(ctx, err))
+			return
+		}
+
+		if !reserved {
+			switch {
+			case !record.Done:
+				ctx.AbortWithStatusJSON(http.StatusConflict,
+					errorResponse(ctx, fmt.Errorf("request with this idempotency key is in progress")))
+			case record.Fingerprint != sum:
+				ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity,
+					errorResponse(ctx, fmt.Errorf("idempotency key was used for a different request")))
+			default:
+				for name, values := range record.Header {
+					ctx.Writer.Header()[name] = values
+				}
+				ctx.Header(idempotentReplayedHeader, "true")
+				ctx.Writer.WriteHeader(record.Status)
+				ctx.Writer.Write(record.Body)
+				ctx.Abort()
+			}
+			return
+		}
+
+		writer := &recordingWriter{ResponseWriter: ctx.Writer}
+		ctx.Writer = writer
+
+		defer func() {
+			if err := recover(); err != nil {
+				s.IdempotencyStore.Release(key)
+				panic(err)
+			}
+		}()
+		ctx.Next()
+
+		if writer.Status() >= http.StatusInternalServerError {
+			s.IdempotencyStore.Release(key)
+			return
+		}
+
+		err = s.IdempotencyStore.Complete(key, idempotency.Record{
+			Fingerprint: sum,
+			Status:      writer.Status(),
+			Header:      http.Header{"Content-Type": writer.Header().Values("Content-Type")},
+			Body:        writer.body.Bytes(),
+		})
+		if err != nil {
+			s.Logger.ErrorCtx(ctx.Request.Context(), "cannot store idempotent response", "error", err)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/mock"
+)
+
+func TestIdempotency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	gomock.InOrder(
+		model.EXPECT().
+			CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(todo, sql.ErrConnDone),
+		model.EXPECT().
+			CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(todo, nil),
+		model.EXPECT().
+			CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(todo, nil),
+	)
+
+	server := newTestServer(t, model)
+
+	send := func(key, title string) *httptest.ResponseRecorder {
+		data, err := json.Marshal(gin.H{
+			"title":       title,
+			"description": todo.Description,
+			"expiry":      "2222-05-22",
+		})
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodPost, "/todos", bytes.NewReader(data))
+		require.NoError(t, err)
+		request.RemoteAddr = "10.0.0.1:1234"
+		if key != "" {
+			request.Header.Set(idempotencyKeyHeader, key)
+		}
+		server.Router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// Failed requests can be retried
+	recorder := send("key", todo.Title)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	recorder = send("key", todo.Title)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Empty(t, recorder.Header().Get(idempotentReplayedHeader))
+	first := recorder.Body.String()
+
+	// Retries are replayed without touching database
+	recorder = send("key", todo.Title)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "true", recorder.Header().Get(idempotentReplayedHeader))
+	require.Equal(t, "application/json; charset=utf-8", recorder.Header().Get("Content-Type"))
+	require.Equal(t, first, recorder.Body.String())
+
+	recorder = send("key", "other title")
+	require.Equal(t, http.StatusUnprocessableEntity, recorder.Code)
+
+	// Requests without key are not deduplicated
+	recorder = send("", todo.Title)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// Request with the same key is in progress
+	_, reserved, err := server.IdempotencyStore.Reserve("ip:10.0.0.1:pending", "", time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	recorder = send("pending", todo.Title)
+	require.Equal(t, http.StatusConflict, recorder.Code)
+}
+
+func TestIdempotencyBodySize(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(todo, nil)
+
+	server := newTestServer(t, model)
+
+	send := func(description string) *httptest.ResponseRecorder {
+		data, err := json.Marshal(gin.H{
+			"title":       todo.Title,
+			"description": description,
+			"expiry":      "2222-05-22",
+		})
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodPost, "/todos", bytes.NewReader(data))
+		require.NoError(t, err)
+		request.Header.Set(idempotencyKeyHeader, description[:1])
+		server.Router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := send(strings.Repeat("a", maxIdempotentBodySize))
+	require.Equal(t, http.StatusRequestEntityTooLarge, recorder.Code)
+
+	recorder = send(strings.Repeat("b", maxIdempotentBodySize/2))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// Other failures of reading the body are client's errors
+	recorder = httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodPost, "/todos", iotest.ErrReader(io.ErrUnexpectedEOF))
+	require.NoError(t, err)
+	request.Header.Set(idempotencyKeyHeader, "c")
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusBadRequest, recorder.Code)
+}
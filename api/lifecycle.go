@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// Default timeouts of http server.
+var (
+	DefaultReadTimeout       = 10 * time.Second
+	DefaultReadHeaderTimeout = 5 * time.Second
+	DefaultWriteTimeout      = 30 * time.Second
+	DefaultIdleTimeout       = 2 * time.Minute
+)
+
+// Timeouts of http server serving the API, zero disables a timeout.
+//
+// ReadTimeout limits reading whole request and ReadHeaderTimeout its headers,
+// WriteTimeout limits time from reading request headers to writing the response
+// and IdleTimeout time keep-alive connection waits for the next request.
+type Timeouts struct {
+	Read       time.Duration
+	ReadHeader time.Duration
+	Write      time.Duration
+	Idle       time.Duration
+}
+
+// Listens on given address, ":0" picks a free port.
+// Connections are served with TLS when TLSConfig is set.
+//
+// Throws an error when address cannot be listened on or server already listens.
+func (s *Server) Listen(addr string) error {
+	if s.listener != nil {
+		return fmt.Errorf("server already listens on %v", s.listener.Addr())
+	}
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	if s.TLSConfig != nil {
+		listener = tls.NewListener(listener, s.TLSConfig)
+	}
+	s.listener = listener
+	s.httpServer = &http.Server{
+		Handler:           s.Router,
+		TLSConfig:         s.TLSConfig,
+		ReadTimeout:       s.Timeouts.Read,
+		ReadHeaderTimeout: s.Timeouts.ReadHeader,
+		WriteTimeout:      s.Timeouts.Write,
+		IdleTimeout:       s.Timeouts.Idle,
+	}
+	return nil
+}
+
+// Returns address server listens on, nil before Listen.
+func (s *Server) Addr() net.Addr {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// Serves requests until Shutdown is called.
+//
+// Returns nil after Shutdown and an error when serving failed.
+func (s *Server) Serve() error {
+	if s.httpServer == nil {
+		return fmt.Errorf("server does not listen")
+	}
+
+	scheme := "http"
+	if s.TLSConfig != nil {
+		scheme = "https"
+	}
+	s.Logger.Info("serving "+scheme, "addr", s.listener.Addr().String())
+	if err := s.httpServer.Serve(s.listener); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Fails readiness checks for ShutdownDelay, then stops accepting new connections
+// and waits for requests in progress to finish until ctx is done,
+// then closes all connections.
+//
+// Throws ctx's error when requests did not finish in time.
+func (s *Server) Shutdown(ctx context.Context) error {
+	atomic.StoreInt32(&s.draining, 1)
+	if s.httpServer == nil {
+		return nil
+	}
+
+	if s.ShutdownDelay > 0 {
+		s.Logger.Info("draining, readiness fails", "delay", s.ShutdownDelay)
+		select {
+		case <-time.After(s.ShutdownDelay):
+		case <-ctx.Done():
+		}
+	}
+
+	err := s.httpServer.Shutdown(ctx)
+	if err != nil {
+		s.httpServer.Close()
+	}
+	// Listener is closed by http server only once Serve is called
+	s.listener.Close()
+	return err
+}
+
+// Listens on given address and serves requests until Shutdown is called.
+func (s *Server) Start(addr string) error {
+	if err := s.Listen(addr); err != nil {
+		return err
+	}
+	return s.Serve()
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"sync"
+
+	"github.com/vilderxyz/todos/db"
+)
+
+// Batches loading of values by keys while resolving single GraphQL query.
+//
+// Load registers key and returns thunk resolved by GraphQL executor after
+// all fields of the same depth. The first thunk called fetches values of all
+// keys registered till then in a single query, instead of one query per key.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	values  map[K]V
+	errs    map[K]error
+}
+
+// Returns loader fetching values with given function.
+//
+// Keys missing in fetched map get zero values.
+func newLoader[K comparable, V any](fetch func(context.Context, []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:  fetch,
+		values: make(map[K]V),
+		errs:   make(map[K]error),
+	}
+}
+
+// Registers key and returns thunk resolving to its value.
+func (l *loader[K, V]) load(ctx context.Context, key K) func() (any, error) {
+	l.mu.Lock()
+	if !l.loaded(key) {
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (any, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		if !l.loaded(key) {
+			keys := l.pending
+			l.pending = nil
+
+			values, err := l.fetch(ctx, keys)
+			for _, k := range keys {
+				if err != nil {
+					l.errs[k] = err
+				} else {
+					l.values[k] = values[k]
+				}
+			}
+		}
+		if err := l.errs[key]; err != nil {
+			return nil, err
+		}
+		return l.values[key], nil
+	}
+}
+
+// Reports whether value of key is already fetched. Must be called with mu held.
+func (l *loader[K, V]) loaded(key K) bool {
+	_, ok := l.values[key]
+	_, failed := l.errs[key]
+	return ok || failed
+}
+
+// Loaders of data related to Todos, created for every GraphQL request.
+type loaders struct {
+	history     *loader[int64, []db.History]
+	transitions *loader[int64, []db.Transition]
+}
+
+// Returns loaders fetching with given queries.
+func newLoaders(queries db.DB) *loaders {
+	return &loaders{
+		history: newLoader(func(ctx context.Context, ids []int64) (map[int64][]db.History, error) {
+			entries, err := queries.GetHistoryOfTodos(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			res := make(map[int64][]db.History, len(ids))
+			for _, id := range ids {
+				res[id] = []db.History{}
+			}
+			for _, entry := range entries {
+				res[entry.TodoId] = append(res[entry.TodoId], entry)
+			}
+			return res, nil
+		}),
+		transitions: newLoader(func(ctx context.Context, ids []int64) (map[int64][]db.Transition, error) {
+			transitions, err := queries.GetTransitionsOfTodos(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			res := make(map[int64][]db.Transition, len(ids))
+			for _, id := range ids {
+				res[id] = []db.Transition{}
+			}
+			for _, transition := range transitions {
+				res[transition.TodoId] = append(res[transition.TodoId], transition)
+			}
+			return res, nil
+		}),
+	}
+}
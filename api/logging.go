@@ -0,0 +1,85 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vilderxyz/todos/logging"
+	"github.com/vilderxyz/todos/metrics"
+	"golang.org/x/exp/slog"
+)
+
+// Header carrying ID of request, sent by client or generated by server.
+const requestIDHeader = "X-Request-ID"
+
+// Request IDs accepted from clients, other ones are replaced with generated IDs.
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// Returns middleware that accepts request ID sent by client or generates a new one,
+// returns it in X-Request-ID header and adds it to request's context,
+// so records logged with that context carry it.
+func requestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(requestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+
+		ctx.Header(requestIDHeader, id)
+		ctx.Request = ctx.Request.WithContext(logging.WithRequestID(ctx.Request.Context(), id))
+		ctx.Next()
+	}
+}
+
+// Returns random request ID.
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// Returns middleware logging every request after it is handled
+// with its route, status, latency, user and errors reported by handlers.
+//
+// Server errors are logged at error level, client errors at warn level.
+func (s *Server) logRequests() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		level := slog.LevelInfo
+		switch {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = metrics.UnmatchedRoute
+		}
+
+		attrs := []slog.Attr{
+			slog.String("method", ctx.Request.Method),
+			slog.String("route", route),
+			slog.String("path", ctx.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("client_ip", ctx.ClientIP()),
+			slog.Int("bytes", ctx.Writer.Size()),
+		}
+		if user := ctx.GetString(userContextKey); user != "" {
+			attrs = append(attrs, slog.String("user", user))
+		}
+		if len(ctx.Errors) > 0 {
+			attrs = append(attrs, slog.String("error", strings.Join(ctx.Errors.Errors(), "; ")))
+		}
+		s.Logger.LogAttrs(ctx.Request.Context(), level, "request", attrs...)
+	}
+}
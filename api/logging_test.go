@@ -0,0 +1,90 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/logging"
+	"github.com/vilderxyz/todos/mock"
+)
+
+func TestRequestLogging(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		GetUserByKeyHash(gomock.Any(), gomock.Eq(HashAPIKey("valid"))).
+		Return(db.User{Name: "alice"}, nil)
+	gomock.InOrder(
+		model.EXPECT().
+			GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+			Return(todo, nil),
+		model.EXPECT().
+			GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+			Return(db.Todo{}, sql.ErrConnDone),
+	)
+
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, logging.Options{Level: "info", Format: logging.FormatJSON})
+	require.NoError(t, err)
+
+	server := NewServer(nil, func(s *Server) {
+		s.Logger = logger
+	})
+	server.Queries = model
+
+	send := func(requestID, apiKey string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/todos/123", nil)
+		require.NoError(t, err)
+		if requestID != "" {
+			request.Header.Set(requestIDHeader, requestID)
+		}
+		if apiKey != "" {
+			request.Header.Set(apiKeyHeader, apiKey)
+		}
+		server.Router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// Request ID sent by client is kept
+	recorder := send("client-id-1", "valid")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "client-id-1", recorder.Header().Get(requestIDHeader))
+
+	// Invalid request ID is replaced with generated one
+	recorder = send("not valid\tid", "")
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	generated := recorder.Header().Get(requestIDHeader)
+	require.Regexp(t, "^[0-9a-f]{32}$", generated)
+
+	var records []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		records = append(records, record)
+	}
+	require.Len(t, records, 2)
+
+	require.Equal(t, "INFO", records[0]["level"])
+	require.Equal(t, "client-id-1", records[0]["request_id"])
+	require.Equal(t, "/todos/:id", records[0]["route"])
+	require.Equal(t, "/todos/123", records[0]["path"])
+	require.Equal(t, float64(http.StatusOK), records[0]["status"])
+	require.Equal(t, "alice", records[0]["user"])
+	require.Contains(t, records[0], "latency")
+
+	require.Equal(t, "ERROR", records[1]["level"])
+	require.Equal(t, generated, records[1]["request_id"])
+	require.Equal(t, sql.ErrConnDone.Error(), records[1]["error"])
+	require.NotContains(t, records[1], "user")
+}
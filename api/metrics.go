@@ -0,0 +1,23 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vilderxyz/todos/metrics"
+)
+
+// Returns middleware recording every request in s.Metrics,
+// labelled with template of matched route instead of raw path.
+func (s *Server) instrument() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+		ctx.Next()
+
+		route := ctx.FullPath()
+		if route == "" {
+			route = metrics.UnmatchedRoute
+		}
+		s.Metrics.ObserveRequest(ctx.Request.Method, route, ctx.Writer.Status(), time.Since(start))
+	}
+}
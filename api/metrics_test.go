@@ -0,0 +1,50 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/metrics"
+	"github.com/vilderxyz/todos/mock"
+)
+
+func TestMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Any()).
+		Return(todo, nil).
+		Times(2)
+
+	server := NewServer(nil, func(s *Server) {
+		s.Metrics = metrics.New()
+	})
+	server.Queries = model
+
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		server.Router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	require.Equal(t, http.StatusOK, get("/todos/1").Code)
+	require.Equal(t, http.StatusOK, get("/todos/2").Code)
+	require.Equal(t, http.StatusNotFound, get("/unknown/path").Code)
+
+	recorder := get("/metrics")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// Requests are labelled with route templates, not raw paths
+	body := recorder.Body.String()
+	require.Contains(t, body, `todos_http_requests_total{method="GET",route="/todos/:id",status="200"} 2`)
+	require.Contains(t, body, `todos_http_requests_total{method="GET",route="unmatched",status="404"} 1`)
+	require.Contains(t, body, `todos_http_request_duration_seconds_count{method="GET",route="/todos/:id"} 2`)
+	require.NotContains(t, body, "/todos/1")
+}
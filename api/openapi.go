@@ -0,0 +1,464 @@
+package api
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vilderxyz/todos/db"
+	valid "github.com/vilderxyz/todos/validator"
+	"gorm.io/gorm"
+)
+
+// Interactive documentation page rendering /openapi.json.
+//
+//go:embed docs.html
+var docsPage []byte
+
+// Assets of Swagger UI loaded by documentation page, see swagger-ui/README.md.
+//
+//go:embed swagger-ui/swagger-ui-bundle.js swagger-ui/swagger-ui.css
+var swaggerUI embed.FS
+
+// Content Security Policy of documentation page allowing only embedded Swagger UI
+// and the page's own inline script, identified by its hash.
+var docsContentSecurityPolicy = func() string {
+	script := regexp.MustCompile(`(?s)<script>(.*?)</script>`).FindSubmatch(docsPage)
+	hash := sha256.Sum256(script[1])
+	return strings.Join([]string{
+		"default-src 'none'",
+		"script-src 'self' 'sha256-" + base64.StdEncoding.EncodeToString(hash[:]) + "'",
+		"style-src 'self' 'unsafe-inline'",
+		"img-src 'self' data:",
+		"connect-src 'self'",
+		"frame-ancestors 'none'",
+	}, "; ")
+}()
+
+// Route of setupRouter described in OpenAPI document.
+//
+// Request is a value of request object, its uri and form fields are described
+// as parameters and json ones as request body. Response is a value sent as Data
+// of Response, unless it is wrapped in rawResponse. Both are nil when route has none.
+type operation struct {
+	Id       string
+	Method   string
+	Path     string
+	Tag      string
+	Summary  string
+	Request  any
+	Response any
+}
+
+// Descriptions of all routes, every route of setupRouter must have one.
+var operations = []operation{
+	{"createTodo", "POST", "/todos", "todos", "Creates todo", CreateTodoRequest{}, db.Todo{}},
+	{"getTodoById", "GET", "/todos/:id", "todos", "Returns todo", GetTodoByIdRequest{}, db.Todo{}},
+	{"getTodos", "GET", "/todos", "todos", "Lists unfinished todos expiring in given period", GetTodosRequest{}, []db.Todo{}},
+	{"getTodoHistory", "GET", "/todos/:id/history", "history", "Returns page of todo's history", GetTodoHistoryRequest{}, HistoryPage{}},
+	{"updateTodoTextInfo", "PATCH", "/todos", "todos", "Changes title, description and expiry of todo", UpdateTodoInfoRequest{}, db.Todo{}},
+	{"updateTodoCompletionInfo", "PATCH", "/todos/completion", "todos", "Changes completion progress of todo", UpdateTodoCompletionRequest{}, db.Todo{}},
+	{"updateTodoDoneInfo", "PATCH", "/todos/done", "todos", "Finishes or reopens todo", UpdateTodoDoneRequest{}, db.Todo{}},
+	{"updateTodoStatus", "PATCH", "/todos/status", "statuses", "Moves todo to another status", UpdateTodoStatusRequest{}, db.Todo{}},
+	{"getTodoTransitions", "GET", "/todos/:id/transitions", "statuses", "Lists status transitions of todo", GetTodoTransitionsRequest{}, []db.Transition{}},
+	{"deleteTodo", "DELETE", "/todos/:id", "trash", "Moves todo to trash", DeleteTodoRequest{}, nil},
+	{"restoreTodo", "POST", "/todos/:id/restore", "trash", "Moves todo out of trash", RestoreTodoRequest{}, db.Todo{}},
+	{"getTrash", "GET", "/trash", "trash", "Lists trashed todos", nil, []db.Todo{}},
+	{"purgeTodo", "DELETE", "/trash/:id", "trash", "Permanently removes todo from trash", PurgeTodoRequest{}, nil},
+	{"purgeTrash", "DELETE", "/trash", "trash", "Permanently removes todos trashed longer than given time ago", PurgeTrashRequest{}, struct {
+		Purged int64 `json:"purged"`
+	}{}},
+	{"archiveTodo", "POST", "/todos/:id/archive", "archive", "Archives todo", ArchiveTodoRequest{}, db.Todo{}},
+	{"unarchiveTodo", "POST", "/todos/:id/unarchive", "archive", "Unarchives todo", ArchiveTodoRequest{}, db.Todo{}},
+	{"archiveDoneTodos", "POST", "/todos/archive", "archive", "Archives todos finished before given date", ArchiveDoneTodosRequest{}, struct {
+		Archived int64 `json:"archived"`
+	}{}},
+	{"runBatch", "POST", "/todos/batch", "batch", "Executes batch of operations", BatchRequest{}, []BatchResult{}},
+	{"graphQLQuery", "GET", "/graphql", "graphql", "Executes GraphQL query", GraphQLQueryRequest{}, rawResponse{graphQLResult{}}},
+	{"graphQL", "POST", "/graphql", "graphql", "Executes GraphQL query or mutation", GraphQLRequest{}, rawResponse{graphQLResult{}}},
+}
+
+// Response sent as it is instead of being Data of Response.
+type rawResponse struct {
+	body any
+}
+
+// Body of GraphQL responses.
+type graphQLResult struct {
+	Data   map[string]any `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Routes serving the documentation, health probes and metrics, they are not part of the API.
+var undocumentedRoutes = map[string]bool{
+	"GET /openapi.json":              true,
+	"GET /docs":                      true,
+	"GET /docs/swagger-ui/*filepath": true,
+	"GET /healthz":                   true,
+	"GET /readyz":                    true,
+	"GET /metrics":                   true,
+}
+
+// Matches gin path parameters, e.g. ":id".
+var pathParam = regexp.MustCompile(`:(\w+)`)
+
+// Responds with OpenAPI 3 document describing all routes.
+func (s *Server) getOpenAPI(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, openAPIDocument())
+}
+
+// Responds with interactive documentation page.
+func (s *Server) getDocs(ctx *gin.Context) {
+	ctx.Header("Content-Security-Policy", docsContentSecurityPolicy)
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
+
+// Responds with embedded Swagger UI asset named by filepath parameter.
+//
+// Throws 404 status when there is no such asset.
+func (s *Server) getDocsAsset(ctx *gin.Context) {
+	name := strings.TrimPrefix(ctx.Param("filepath"), "/")
+	data, err := fs.ReadFile(swaggerUI, path.Join("swagger-ui", name))
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, errorResponse(ctx, fmt.Errorf("not found")))
+		return
+	}
+	ctx.Header("Cache-Control", "public, max-age=86400")
+	ctx.Data(http.StatusOK, mime.TypeByExtension(path.Ext(name)), data)
+}
+
+// JSON object of OpenAPI document.
+type object = map[string]any
+
+// Returns OpenAPI 3 document built from operations.
+func openAPIDocument() object {
+	b := &specBuilder{schemas: object{
+		"Error": object{
+			"type":       "object",
+			"properties": object{"error": object{"type": "string"}},
+			"required":   []string{"error"},
+		},
+	}}
+
+	paths := object{}
+	for _, op := range operations {
+		path := pathParam.ReplaceAllString(op.Path, "{$1}")
+		item, ok := paths[path].(object)
+		if !ok {
+			item = object{}
+			paths[path] = item
+		}
+		item[strings.ToLower(op.Method)] = b.operation(op)
+	}
+
+	return object{
+		"openapi": "3.0.3",
+		"info": object{
+			"title":   "Todos API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": object{
+			"schemas": b.schemas,
+			"responses": object{
+				"BadRequest":   errorResponseSpec("Invalid request"),
+				"Unauthorized": errorResponseSpec("Invalid API key or disabled user"),
+				"NotFound":     errorResponseSpec("Todo not found"),
+				"RateLimited":  errorResponseSpec("Rate limit exceeded, retry after time given in Retry-After header"),
+				"ServerError":  errorResponseSpec("Internal error"),
+			},
+			"securitySchemes": object{
+				"apiKey": object{"type": "apiKey", "in": "header", "name": apiKeyHeader},
+			},
+		},
+		// Anonymous requests are allowed
+		"security": []any{object{}, object{"apiKey": []string{}}},
+	}
+}
+
+// Returns error response of components with given description.
+func errorResponseSpec(description string) object {
+	return object{
+		"description": description,
+		"content":     jsonContent(ref("Error")),
+	}
+}
+
+// Returns reference to schema of components.
+func ref(name string) object {
+	return object{"$ref": "#/components/schemas/" + name}
+}
+
+// Operations answering conditional requests with 304 status.
+var conditionalOperations = map[string]bool{
+	"getTodoById": true,
+	"getTodos":    true,
+}
+
+// Returns content of application/json media type with given schema.
+func jsonContent(schema object) object {
+	return object{"application/json": object{"schema": schema}}
+}
+
+// Builds schemas of Go types and collects named ones as components.
+type specBuilder struct {
+	schemas object
+}
+
+// Returns OpenAPI operation object describing single route.
+func (b *specBuilder) operation(op operation) object {
+	responses := object{
+		"200": object{
+			"description": "Success",
+			"content":     jsonContent(b.response(op.Response)),
+		},
+		"401": object{"$ref": "#/components/responses/Unauthorized"},
+		"429": object{"$ref": "#/components/responses/RateLimited"},
+		"500": object{"$ref": "#/components/responses/ServerError"},
+	}
+	spec := object{
+		"operationId": op.Id,
+		"summary":     op.Summary,
+		"tags":        []string{op.Tag},
+		"responses":   responses,
+	}
+
+	var parameters []any
+	if op.Request != nil {
+		t := reflect.TypeOf(op.Request)
+		parameters = b.parameters(t)
+		if hasBody(t) {
+			spec["requestBody"] = object{
+				"required": true,
+				"content":  jsonContent(b.schema(t)),
+			}
+		}
+		responses["400"] = object{"$ref": "#/components/responses/BadRequest"}
+		if _, ok := t.FieldByName("Id"); ok {
+			responses["404"] = object{"$ref": "#/components/responses/NotFound"}
+		}
+	}
+	if op.Method != http.MethodGet {
+		parameters = append(parameters, object{
+			"name":        idempotencyKeyHeader,
+			"in":          "header",
+			"description": "Replays the stored response when request is retried with the same key",
+			"schema":      object{"type": "string", "maxLength": maxIdempotencyKeyLength},
+		})
+	}
+	if conditionalOperations[op.Id] {
+		parameters = append(parameters, object{
+			"name":        "If-None-Match",
+			"in":          "header",
+			"description": "ETag of client's copy, which is not sent again when it is current",
+			"schema":      object{"type": "string"},
+		})
+		responses["304"] = object{"description": "Client's copy is current"}
+	}
+	if len(parameters) > 0 {
+		spec["parameters"] = parameters
+	}
+	return spec
+}
+
+// Returns schema of Response carrying given data.
+func (b *specBuilder) response(data any) object {
+	if raw, ok := data.(rawResponse); ok {
+		return b.schema(reflect.TypeOf(raw.body))
+	}
+	properties := object{"message": object{"type": "string"}}
+	if data != nil {
+		properties["data"] = b.schema(reflect.TypeOf(data))
+	}
+	return object{
+		"type":       "object",
+		"properties": properties,
+		"required":   []string{"message"},
+	}
+}
+
+// Returns path and query parameters of request object.
+func (b *specBuilder) parameters(t reflect.Type) []any {
+	var parameters []any
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		in, name := "path", field.Tag.Get("uri")
+		if name == "" {
+			in, name = "query", field.Tag.Get("form")
+		}
+		if name == "" {
+			continue
+		}
+
+		schema := b.schema(field.Type)
+		required := constrain(schema, field) || in == "path"
+		parameters = append(parameters, object{
+			"name":     name,
+			"in":       in,
+			"required": required,
+			"schema":   schema,
+		})
+	}
+	return parameters
+}
+
+// Reports whether request object has any fields bound from json body.
+func hasBody(t reflect.Type) bool {
+	for i := 0; i < t.NumField(); i++ {
+		if jsonName(t.Field(i)) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns schema of given type.
+//
+// Named structs are added to components and referenced.
+func (b *specBuilder) schema(t reflect.Type) object {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return object{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(gorm.DeletedAt{}):
+		return object{"type": "string", "format": "date-time", "nullable": true}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		schema := b.schema(t.Elem())
+		if _, ok := schema["$ref"]; !ok {
+			schema["nullable"] = true
+		}
+		return schema
+	case reflect.Bool:
+		return object{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32:
+		return object{"type": "integer", "format": "int32"}
+	case reflect.Int64:
+		return object{"type": "integer", "format": "int64"}
+	case reflect.Float32:
+		return object{"type": "number", "format": "float"}
+	case reflect.Float64:
+		return object{"type": "number", "format": "double"}
+	case reflect.String:
+		return object{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return object{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return object{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		if _, ok := b.schemas[t.Name()]; !ok {
+			// Placeholder stops recursion of self-referencing types
+			b.schemas[t.Name()] = object{}
+			b.schemas[t.Name()] = b.object(t)
+		}
+		return ref(t.Name())
+	}
+	// Interfaces accept any value
+	return object{}
+}
+
+// Returns schema of struct encoded as json object.
+func (b *specBuilder) object(t reflect.Type) object {
+	properties := object{}
+	var required []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := jsonName(field)
+		if name == "" {
+			continue
+		}
+
+		schema := b.schema(field.Type)
+		if constrain(schema, field) {
+			required = append(required, name)
+		}
+		properties[name] = schema
+	}
+
+	schema := object{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// Returns json name of struct field or empty string when it's not encoded.
+//
+// Fields bound from uri or query are not part of json body.
+func jsonName(field reflect.StructField) string {
+	if !field.IsExported() {
+		return ""
+	}
+	tag, ok := field.Tag.Lookup("json")
+	if !ok {
+		if field.Tag.Get("uri") != "" || field.Tag.Get("form") != "" {
+			return ""
+		}
+		return field.Name
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	switch name {
+	case "-":
+		return ""
+	case "":
+		return field.Name
+	}
+	return name
+}
+
+// Adds validation rules of field's binding tag to its schema
+// and reports whether the field is required.
+func constrain(schema object, field reflect.StructField) bool {
+	if field.Tag.Get("time_format") == "2006-01-02" {
+		schema["format"] = "date"
+	}
+
+	minimum, maximum := "minimum", "maximum"
+	switch schema["type"] {
+	case "string":
+		minimum, maximum = "minLength", "maxLength"
+	case "array":
+		minimum, maximum = "minItems", "maxItems"
+	}
+
+	required := false
+	for _, rule := range strings.Split(field.Tag.Get("binding"), ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "required":
+			required = true
+			delete(schema, "nullable")
+		case "min", "gte":
+			schema[minimum] = json.Number(param)
+		case "max", "lte":
+			schema[maximum] = json.Number(param)
+		case "oneof":
+			schema["enum"] = strings.Fields(param)
+		case "period":
+			schema["enum"] = valid.Periods
+		case "dive":
+			// Following rules apply to elements
+			return required
+		}
+	}
+	return required
+}
@@ -0,0 +1,168 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/mock"
+)
+
+// Returns OpenAPI document served by server.
+func getOpenAPIDocument(t *testing.T, server *Server) map[string]any {
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	require.NoError(t, err)
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var document map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &document))
+	return document
+}
+
+func TestOpenAPIRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mock.NewMockDB(ctrl))
+	document := getOpenAPIDocument(t, server)
+	require.Equal(t, "3.0.3", document["openapi"])
+	paths := document["paths"].(map[string]any)
+
+	// Every route is described
+	routes := map[string]bool{}
+	for _, route := range server.Router.Routes() {
+		if undocumentedRoutes[route.Method+" "+route.Path] {
+			continue
+		}
+		path := pathParam.ReplaceAllString(route.Path, "{$1}")
+		method := strings.ToLower(route.Method)
+		routes[method+" "+path] = true
+
+		item, ok := paths[path].(map[string]any)
+		require.True(t, ok, "no spec entry for %v %v", route.Method, route.Path)
+		require.Contains(t, item, method, "no spec entry for %v %v", route.Method, route.Path)
+	}
+
+	// Every description has its route and parameters of its path
+	for path, item := range paths {
+		for method, spec := range item.(map[string]any) {
+			require.True(t, routes[method+" "+path], "spec entry of missing route %v %v", method, path)
+
+			var names []string
+			parameters, _ := spec.(map[string]any)["parameters"].([]any)
+			for _, parameter := range parameters {
+				parameter := parameter.(map[string]any)
+				if parameter["in"] == "path" {
+					names = append(names, parameter["name"].(string))
+				}
+			}
+			for _, match := range regexp.MustCompile(`{(\w+)}`).FindAllStringSubmatch(path, -1) {
+				require.Contains(t, names, match[1], "no parameter %v of %v %v", match[1], method, path)
+			}
+		}
+	}
+}
+
+func TestOpenAPISchemas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	document := getOpenAPIDocument(t, newTestServer(t, mock.NewMockDB(ctrl)))
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any)
+	property := func(schema, name string) map[string]any {
+		return schemas[schema].(map[string]any)["properties"].(map[string]any)[name].(map[string]any)
+	}
+
+	create := schemas["CreateTodoRequest"].(map[string]any)
+	require.ElementsMatch(t, []any{"title", "description", "expiry"}, create["required"])
+	require.Equal(t, float64(1), property("CreateTodoRequest", "title")["minLength"])
+	require.Equal(t, "date", property("CreateTodoRequest", "expiry")["format"])
+
+	completion := property("UpdateTodoCompletionRequest", "completion")
+	require.Equal(t, "number", completion["type"])
+	require.Equal(t, float64(0), completion["minimum"])
+	require.Equal(t, float64(100), completion["maximum"])
+
+	operations := property("BatchRequest", "operations")
+	require.Equal(t, float64(1), operations["minItems"])
+	require.Equal(t, float64(500), operations["maxItems"])
+	require.Equal(t, "#/components/schemas/BatchOperation", operations["items"].(map[string]any)["$ref"])
+	require.Equal(t, []any{"create", "update", "delete", "complete"}, property("BatchOperation", "op")["enum"])
+
+	require.Equal(t, true, property("Todo", "done_at")["nullable"])
+	require.Equal(t, "date-time", property("Todo", "expiry")["format"])
+
+	// Query and path parameters
+	paths := document["paths"].(map[string]any)
+	parameters := paths["/todos"].(map[string]any)["get"].(map[string]any)["parameters"].([]any)
+	require.Len(t, parameters, 3)
+	require.Equal(t, "If-None-Match", parameters[2].(map[string]any)["name"])
+	period := parameters[0].(map[string]any)
+	require.Equal(t, "period", period["name"])
+	require.Equal(t, "query", period["in"])
+	require.Equal(t, []any{"today", "tomorrow", "week", ""}, period["schema"].(map[string]any)["enum"])
+
+	getTodo := paths["/todos/{id}"].(map[string]any)["get"].(map[string]any)
+	id := getTodo["parameters"].([]any)[0].(map[string]any)
+	require.Equal(t, "path", id["in"])
+	require.Equal(t, true, id["required"])
+	require.Equal(t, float64(1), id["schema"].(map[string]any)["minimum"])
+	require.Contains(t, getTodo["responses"], "404")
+	require.Contains(t, getTodo["responses"], "304")
+	require.NotContains(t, getTodo, "requestBody")
+}
+
+func TestDocs(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mock.NewMockDB(ctrl))
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/docs", nil)
+	require.NoError(t, err)
+	server.Router.ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Content-Type"), "text/html")
+	require.Contains(t, recorder.Body.String(), "/openapi.json")
+}
+
+func TestDocsAssets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := newTestServer(t, mock.NewMockDB(ctrl))
+	get := func(path string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+		server.Router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	// Swagger UI is served from the binary, not loaded from a CDN
+	recorder := get("/docs")
+	require.NotContains(t, recorder.Body.String(), "https://")
+	require.NotContains(t, recorder.Header().Get("Content-Security-Policy"), "https://")
+
+	recorder = get("/docs/swagger-ui/swagger-ui-bundle.js")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Content-Type"), "javascript")
+	require.Contains(t, recorder.Body.String(), "SwaggerUIBundle")
+
+	recorder = get("/docs/swagger-ui/swagger-ui.css")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Content-Type"), "text/css")
+
+	for _, path := range []string{"/docs/swagger-ui/", "/docs/swagger-ui/LICENSE", "/docs/swagger-ui/../docs.html"} {
+		recorder = get(path)
+		require.Equal(t, http.StatusNotFound, recorder.Code, path)
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vilderxyz/todos/ratelimit"
+)
+
+const (
+	// Header that carries client's API key
+	apiKeyHeader = "X-API-Key"
+
+	// Gin context key under which authenticated user's name is stored
+	userContextKey = "user"
+)
+
+var (
+	// Default limit for routes that only read todos
+	DefaultReadLimit = ratelimit.Limit{Rate: 20, Burst: 40}
+
+	// Default limit for routes that modify todos
+	DefaultWriteLimit = ratelimit.Limit{Rate: 5, Burst: 10}
+
+	// Default limit of API key lookups per IP address,
+	// enough for a single user using both read and write limits
+	DefaultAuthLimit = ratelimit.Limit{Rate: 25, Burst: 50}
+)
+
+// Returns key identifying the client for rate limiting.
+//
+// Authenticated user takes precedence over client's IP address.
+// Unverified API keys are never used, otherwise clients could get
+// a new bucket with every made up key.
+func clientKey(ctx *gin.Context) string {
+	if user := ctx.GetString(userContextKey); user != "" {
+		return "user:" + user
+	}
+	return "ip:" + ctx.ClientIP()
+}
+
+// Returns middleware that takes a token from limiter for every request.
+//
+// Sets RateLimit-Limit, RateLimit-Remaining and RateLimit-Reset headers.
+//
+// Throws 429 status with Retry-After header when there are no tokens left.
+func rateLimit(limiter *ratelimit.Limiter) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if allow(ctx, limiter, clientKey(ctx)) {
+			ctx.Next()
+		}
+	}
+}
+
+// Takes a token from limiter for given key and sets RateLimit headers.
+//
+// Reports whether request can go on, otherwise it is aborted with 429 status
+// or 500 status when limiter fails.
+func allow(ctx *gin.Context, limiter *ratelimit.Limiter, key string) bool {
+	res, err := limiter.Allow(key)
+	if err != nil {
+		ctx.AbortWithStatusJSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return false
+	}
+
+	if res.Limit > 0 {
+		header := ctx.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+		header.Set("RateLimit-Reset", ceilSeconds(res.Reset))
+	}
+
+	if !res.Allowed {
+		ctx.Header("Retry-After", ceilSeconds(res.RetryAfter))
+		ctx.AbortWithStatusJSON(http.StatusTooManyRequests, errorResponse(ctx, fmt.Errorf("rate limit exceeded")))
+		return false
+	}
+	return true
+}
+
+// Formats duration as whole seconds rounded up.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,108 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/mock"
+	"github.com/vilderxyz/todos/ratelimit"
+)
+
+func TestRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		Times(3).
+		Return(todo, nil)
+	model.EXPECT().
+		DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+		Times(1).
+		Return(nil)
+	model.EXPECT().
+		GetUserByKeyHash(gomock.Any(), gomock.Eq(HashAPIKey("secret"))).
+		Times(1).
+		Return(db.User{Name: "user"}, nil)
+
+	server := newTestServer(t, model)
+	server.ReadLimiter.Limit = ratelimit.Limit{Rate: 1, Burst: 2}
+	server.WriteLimiter.Limit = ratelimit.Limit{Rate: 1, Burst: 1}
+
+	send := func(method, apiKey string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		url := fmt.Sprintf("/todos/%d", todo.Id)
+		request, err := http.NewRequest(method, url, nil)
+		require.NoError(t, err)
+		if apiKey != "" {
+			request.Header.Set(apiKeyHeader, apiKey)
+		}
+		server.Router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := send(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "2", recorder.Header().Get("RateLimit-Limit"))
+	require.Equal(t, "1", recorder.Header().Get("RateLimit-Remaining"))
+
+	recorder = send(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "0", recorder.Header().Get("RateLimit-Remaining"))
+
+	recorder = send(http.MethodGet, "")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "1", recorder.Header().Get("Retry-After"))
+	require.Equal(t, "2", recorder.Header().Get("RateLimit-Reset"))
+
+	// Writes are limited separately from reads
+	recorder = send(http.MethodDelete, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	recorder = send(http.MethodDelete, "")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+
+	// Authenticated users have their own buckets
+	recorder = send(http.MethodGet, "secret")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Equal(t, "1", recorder.Header().Get("RateLimit-Remaining"))
+}
+
+func TestRateLimitAPIKeys(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		GetUserByKeyHash(gomock.Any(), gomock.Any()).
+		Times(2).
+		Return(db.User{}, fmt.Errorf("not found"))
+
+	server := newTestServer(t, model)
+	server.AuthLimiter.Limit = ratelimit.Limit{Rate: 1, Burst: 2}
+
+	send := func(apiKey string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, "/todos", nil)
+		require.NoError(t, err)
+		request.Header.Set(apiKeyHeader, apiKey)
+		server.Router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := send("first")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+	recorder = send("second")
+	require.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// Made up keys do not get buckets of their own and are not looked up any more
+	recorder = send("third")
+	require.Equal(t, http.StatusTooManyRequests, recorder.Code)
+	require.Equal(t, "1", recorder.Header().Get("Retry-After"))
+}
@@ -1,33 +1,80 @@
 package api
 
-import (
-	"github.com/gin-contrib/cors"
-	"github.com/gin-gonic/gin"
-)
+import "github.com/gin-gonic/gin"
 
 // Setups all available http routes
 func (s *Server) setupRouter() {
-	router := gin.Default()
+	router := gin.New()
+	router.Use(requestID(), traceRequests(), s.logRequests(), gin.Recovery(), s.secureHeaders())
 
-	router.Use(cors.New(cors.Config{
-		AllowOrigins: []string{"*"},
-		AllowMethods: []string{"PATCH", "POST", "GET", "DELETE"},
-		AllowHeaders: []string{"Content-Type"},
-	}))
+	if s.Metrics != nil {
+		router.Use(s.instrument())
+	}
 
-	router.POST("/todos", s.createTodo)
+	if cors := s.cors(); cors != nil {
+		router.Use(cors)
+	}
+	router.Use(s.queryTimeout(), s.authenticate())
 
-	router.GET("/todos/:id", s.getTodoById)
+	// Probes, metrics and documentation are not rate limited
+	router.GET("/healthz", s.getHealth)
+	router.GET("/readyz", s.getReadiness)
 
-	router.GET("/todos", s.getTodos)
+	if s.Metrics != nil {
+		router.GET("/metrics", gin.WrapH(s.Metrics.Handler()))
+	}
 
-	router.PATCH("/todos", s.updateTodoTextInfo)
+	if s.DocsEnabled {
+		router.GET("/openapi.json", s.getOpenAPI)
+		router.GET("/docs", s.getDocs)
+		router.GET("/docs/swagger-ui/*filepath", s.getDocsAsset)
+	}
 
-	router.PATCH("/todos/completion", s.updateTodoCompletionInfo)
+	reads := router.Group("/", rateLimit(s.ReadLimiter))
+	writes := router.Group("/", rateLimit(s.WriteLimiter), s.idempotent())
 
-	router.PATCH("/todos/done", s.updateTodoDoneInfo)
+	writes.POST("/todos", s.createTodo)
 
-	router.DELETE("/todos/:id", s.deleteTodo)
+	reads.GET("/todos/:id", s.getTodoById)
+
+	reads.GET("/todos", s.getTodos)
+
+	reads.GET("/todos/:id/history", s.getTodoHistory)
+
+	writes.PATCH("/todos", s.updateTodoTextInfo)
+
+	writes.PATCH("/todos/completion", s.updateTodoCompletionInfo)
+
+	writes.PATCH("/todos/done", s.updateTodoDoneInfo)
+
+	writes.PATCH("/todos/status", s.updateTodoStatus)
+
+	reads.GET("/todos/:id/transitions", s.getTodoTransitions)
+
+	writes.DELETE("/todos/:id", s.deleteTodo)
+
+	writes.POST("/todos/:id/restore", s.restoreTodo)
+
+	reads.GET("/trash", s.getTrash)
+
+	writes.DELETE("/trash/:id", s.purgeTodo)
+
+	writes.DELETE("/trash", s.purgeTrash)
+
+	writes.POST("/todos/:id/archive", s.setTodoArchived(true))
+
+	writes.POST("/todos/:id/unarchive", s.setTodoArchived(false))
+
+	writes.POST("/todos/archive", s.archiveDoneTodos)
+
+	writes.POST("/todos/batch", s.runBatch)
+
+	// Queries can be sent with GET, mutations only with POST
+	if s.GraphQLEnabled {
+		reads.GET("/graphql", s.graphQL)
+
+		writes.POST("/graphql", s.graphQL)
+	}
 
 	s.Router = router
 }
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"time"
+
+	"github.com/gin-contrib/cors"
+	"github.com/gin-gonic/gin"
+)
+
+// Default time browsers cache results of preflight requests.
+const DefaultCORSMaxAge = 12 * time.Hour
+
+// Content Security Policy of API responses, which never load any resources.
+const apiContentSecurityPolicy = "default-src 'none'; frame-ancestors 'none'"
+
+// Settings of Cross-Origin Resource Sharing.
+type CORS struct {
+	// Origins allowed to call the API from browsers, "*" allows all
+	// and none allows only requests from the API's own origin
+	AllowOrigins []string
+
+	// Methods and request headers allowed in cross-origin requests,
+	// headers read by the API are always allowed
+	AllowMethods []string
+	AllowHeaders []string
+
+	// Whether browsers send cookies and authorization with cross-origin requests
+	AllowCredentials bool
+
+	// Time browsers cache results of preflight requests
+	MaxAge time.Duration
+}
+
+// Security headers sent with every response.
+type SecurityHeaders struct {
+	// Value of Strict-Transport-Security header, empty omits it
+	HSTS string
+
+	// Value of X-Frame-Options header, empty omits it
+	FrameOptions string
+}
+
+// Returns middleware answering preflight requests and adding CORS headers
+// to responses of allowed origins.
+//
+// Throws 403 status for requests of other origins.
+// Returns nil when no origins are allowed.
+func (s *Server) cors() gin.HandlerFunc {
+	if len(s.CORS.AllowOrigins) == 0 {
+		return nil
+	}
+	return cors.New(cors.Config{
+		AllowOrigins:     s.CORS.AllowOrigins,
+		AllowMethods:     s.CORS.AllowMethods,
+		AllowHeaders:     append([]string{"Content-Type", apiKeyHeader, idempotencyKeyHeader, requestIDHeader, "traceparent", "tracestate"}, s.CORS.AllowHeaders...),
+		AllowCredentials: s.CORS.AllowCredentials,
+		MaxAge:           s.CORS.MaxAge,
+		ExposeHeaders: []string{
+			"RateLimit-Limit",
+			"RateLimit-Remaining",
+			"RateLimit-Reset",
+			"Retry-After",
+			idempotentReplayedHeader,
+			requestIDHeader,
+		},
+	})
+}
+
+// Returns middleware adding security headers to every response.
+//
+// Responses get Content Security Policy that blocks all resources,
+// handlers serving HTML replace it with their own.
+func (s *Server) secureHeaders() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		header := ctx.Writer.Header()
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("Referrer-Policy", "no-referrer")
+		header.Set("Content-Security-Policy", apiContentSecurityPolicy)
+		if s.SecurityHeaders.FrameOptions != "" {
+			header.Set("X-Frame-Options", s.SecurityHeaders.FrameOptions)
+		}
+		if s.SecurityHeaders.HSTS != "" {
+			header.Set("Strict-Transport-Security", s.SecurityHeaders.HSTS)
+		}
+		ctx.Next()
+	}
+}
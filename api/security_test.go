@@ -0,0 +1,87 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCORS(t *testing.T) {
+	preflight := func(server *Server, method, headers string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(http.MethodOptions, "/todos", nil)
+		require.NoError(t, err)
+		request.Header.Set("Origin", "https://todos.example.com")
+		request.Header.Set("Access-Control-Request-Method", method)
+		request.Header.Set("Access-Control-Request-Headers", headers)
+
+		recorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	server := NewServer(nil, func(s *Server) {
+		s.CORS = CORS{
+			AllowOrigins:     []string{"https://todos.example.com"},
+			AllowMethods:     []string{http.MethodGet, http.MethodPut},
+			AllowHeaders:     []string{"Authorization", "If-Match"},
+			AllowCredentials: true,
+			MaxAge:           time.Hour,
+		}
+	})
+
+	recorder := preflight(server, http.MethodPut, "Authorization, If-Match")
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	require.Equal(t, "https://todos.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+	require.Equal(t, "GET,PUT", recorder.Header().Get("Access-Control-Allow-Methods"))
+	require.Contains(t, recorder.Header().Get("Access-Control-Allow-Headers"), "Authorization,If-Match")
+	require.Contains(t, recorder.Header().Get("Access-Control-Allow-Headers"), http.CanonicalHeaderKey(apiKeyHeader))
+	require.Equal(t, "true", recorder.Header().Get("Access-Control-Allow-Credentials"))
+	require.Equal(t, "3600", recorder.Header().Get("Access-Control-Max-Age"))
+
+	// Without origins only requests of the API's own origin are served
+	server = NewServer(nil, func(s *Server) {
+		s.CORS.AllowOrigins = nil
+	})
+	recorder = preflight(server, http.MethodPost, "Content-Type")
+	require.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	get := func(server *Server, path string) http.Header {
+		request, err := http.NewRequest(http.MethodGet, path, nil)
+		require.NoError(t, err)
+
+		recorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(recorder, request)
+		return recorder.Header()
+	}
+
+	server := NewServer(nil)
+	header := get(server, "/healthz")
+	require.Equal(t, "nosniff", header.Get("X-Content-Type-Options"))
+	require.Equal(t, "DENY", header.Get("X-Frame-Options"))
+	require.Equal(t, "no-referrer", header.Get("Referrer-Policy"))
+	require.Equal(t, apiContentSecurityPolicy, header.Get("Content-Security-Policy"))
+	require.Empty(t, header.Get("Strict-Transport-Security"))
+
+	// Unmatched routes get them as well
+	require.Equal(t, "nosniff", get(server, "/missing").Get("X-Content-Type-Options"))
+
+	// Documentation page may load Swagger UI and run its own script only
+	header = get(server, "/docs")
+	require.Contains(t, header.Get("Content-Security-Policy"), "script-src 'self' 'sha256-")
+	require.Contains(t, header.Get("Content-Security-Policy"), "frame-ancestors 'none'")
+
+	server = NewServer(nil, func(s *Server) {
+		s.SecurityHeaders = SecurityHeaders{
+			HSTS:         "max-age=31536000; includeSubDomains",
+			FrameOptions: "SAMEORIGIN",
+		}
+	})
+	header = get(server, "/healthz")
+	require.Equal(t, "max-age=31536000; includeSubDomains", header.Get("Strict-Transport-Security"))
+	require.Equal(t, "SAMEORIGIN", header.Get("X-Frame-Options"))
+}
@@ -1,14 +1,26 @@
 package api
 
 import (
-	"log"
-	"os"
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gin-gonic/gin/binding"
 	"github.com/go-playground/validator/v10"
+	"github.com/graphql-go/graphql"
+	"github.com/vilderxyz/todos/certs"
 	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/idempotency"
+	"github.com/vilderxyz/todos/metrics"
+	"github.com/vilderxyz/todos/ratelimit"
+	"github.com/vilderxyz/todos/service"
 	valid "github.com/vilderxyz/todos/validator"
+	"github.com/vilderxyz/todos/workflow"
+	"golang.org/x/exp/slog"
 	"gorm.io/gorm"
 )
 
@@ -16,35 +28,161 @@ import (
 type Server struct {
 	Queries db.DB
 	Router  *gin.Engine
+
+	// Limiters for read and write route groups
+	ReadLimiter  *ratelimit.Limiter
+	WriteLimiter *ratelimit.Limiter
+
+	// Limiter of API key lookups per IP address, taken before authentication
+	AuthLimiter *ratelimit.Limiter
+
+	// Storage of idempotency keys and time after which they can be reused
+	IdempotencyStore idempotency.Store
+	IdempotencyTTL   time.Duration
+
+	// Allowed transitions between Todo statuses
+	Workflow *workflow.Machine
+
+	// Time limit of database queries executed for a single request
+	QueryTimeout time.Duration
+
+	// Limits of GraphQL queries, zero disables them
+	GraphQLMaxDepth      int
+	GraphQLMaxComplexity int
+
+	// Cross-origin requests allowed from browsers and security headers of responses
+	CORS            CORS
+	SecurityHeaders SecurityHeaders
+
+	// Optional routes, /graphql and documentation
+	GraphQLEnabled bool
+	DocsEnabled    bool
+
+	// Timeouts of http server, applied by Listen
+	Timeouts Timeouts
+
+	// TLS of connections, nil serves plain http
+	TLSConfig *tls.Config
+
+	// Users of clients authenticated by verified TLS certificates
+	ClientCertUsers certs.Users
+
+	// Checks of /readyz and time limit of each of them
+	ReadinessChecks    []HealthCheck
+	HealthCheckTimeout time.Duration
+
+	// Logger of requests and server's events
+	Logger *slog.Logger
+
+	// Collectors of request metrics served at /metrics, nil disables both
+	Metrics *metrics.Metrics
+
+	// Time /readyz fails during shutdown before server stops accepting connections,
+	// so load balancers stop sending requests first
+	ShutdownDelay time.Duration
+
+	schema     graphql.Schema
+	listener   net.Listener
+	httpServer *http.Server
+
+	// Set to 1 once Shutdown is called
+	draining int32
 }
 
+// Option that configures Server before its routes are set up.
+type Option func(*Server)
+
 // Creates a new Server instance with database connection
 // and returns pointer to it
-func NewServer(conn *gorm.DB) *Server {
+func NewServer(conn *gorm.DB, opts ...Option) *Server {
 	server := &Server{
-		Queries: db.New(conn),
+		Queries:          db.New(conn),
+		Workflow:         workflow.Default,
+		IdempotencyStore: idempotency.NewMemoryStore(),
+		IdempotencyTTL:   DefaultIdempotencyTTL,
+		QueryTimeout:     DefaultQueryTimeout,
+
+		GraphQLMaxDepth:      DefaultGraphQLMaxDepth,
+		GraphQLMaxComplexity: DefaultGraphQLMaxComplexity,
+
+		CORS: CORS{
+			AllowOrigins: []string{"*"},
+			AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+			MaxAge:       DefaultCORSMaxAge,
+		},
+		SecurityHeaders: SecurityHeaders{FrameOptions: "DENY"},
+		GraphQLEnabled:  true,
+		DocsEnabled:     true,
+
+		Timeouts: Timeouts{
+			Read:       DefaultReadTimeout,
+			ReadHeader: DefaultReadHeaderTimeout,
+			Write:      DefaultWriteTimeout,
+			Idle:       DefaultIdleTimeout,
+		},
+		HealthCheckTimeout: DefaultHealthCheckTimeout,
+		Logger:             slog.Default(),
+	}
+	if conn != nil {
+		server.ReadinessChecks = []HealthCheck{DatabaseCheck(conn)}
 	}
+	server.schema = server.graphQLSchema()
 
-	// Registers custom period validator
-	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
-		v.RegisterValidation("period", valid.ValidPeriod)
+	store := ratelimit.NewMemoryStore()
+	server.ReadLimiter = ratelimit.New("read", DefaultReadLimit, store)
+	server.WriteLimiter = ratelimit.New("write", DefaultWriteLimit, store)
+	server.AuthLimiter = ratelimit.New("auth", DefaultAuthLimit, store)
+
+	for _, opt := range opts {
+		opt(server)
 	}
 
+	registerValidators()
 	server.setupRouter()
 	return server
 }
 
-// Runs Gin router on given address
-func (s *Server) Start(addr string) error {
-	listen := os.Getenv("LISTEN_ADDR")
-	log.Println("Serving at: ", listen)
-	return s.Router.Run(addr)
+// Guards registration of custom validators
+var registerOnce sync.Once
+
+// Registers custom period validator
+func registerValidators() {
+	registerOnce.Do(func() {
+		if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+			v.RegisterValidation("period", valid.ValidPeriod)
+		}
+	})
+}
+
+// Validates request object with binding rules of its fields,
+// the same way handlers validate bound requests.
+func Validate(req any) error {
+	registerValidators()
+	return binding.Validator.ValidateStruct(req)
+}
+
+// Returns Service applying rules of Todos with server's current queries and workflow.
+func (s *Server) todos() *service.Service {
+	return service.New(s.Queries, s.Workflow)
+}
+
+// Returns http status of error returned by Service.
+//
+// Missing Todos get 404 status, broken rules 400 status and other errors 500 status.
+func errorStatus(err error) int {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, service.ErrInvalid), errors.Is(err, service.ErrConflict):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
 }
 
 // Helps handling errors much faster.
 //
-// Prints an error and sends it back to the client's side
-func errorResponse(err error) gin.H {
-	log.Println(err)
+// Reports an error to be logged with the request and sends it back to the client's side
+func errorResponse(ctx *gin.Context, err error) gin.H {
+	ctx.Error(err)
 	return gin.H{"error": err.Error()}
 }
@@ -0,0 +1,144 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/mock"
+)
+
+func TestOptions(t *testing.T) {
+	server := NewServer(nil, func(s *Server) {
+		s.CORS.AllowOrigins = []string{"https://todos.example.com"}
+		s.GraphQLEnabled = false
+		s.DocsEnabled = false
+	})
+
+	for _, route := range server.Router.Routes() {
+		require.NotContains(t, []string{"/graphql", "/docs", "/openapi.json"}, route.Path)
+	}
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		request, err := http.NewRequest(http.MethodOptions, "/todos", nil)
+		require.NoError(t, err)
+		request.Header.Set("Origin", origin)
+		request.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+		recorder := httptest.NewRecorder()
+		server.Router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := preflight("https://todos.example.com")
+	require.Equal(t, http.StatusNoContent, recorder.Code)
+	require.Equal(t, "https://todos.example.com", recorder.Header().Get("Access-Control-Allow-Origin"))
+
+	recorder = preflight("https://evil.example.com")
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+	require.Empty(t, recorder.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestLifecycle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	started := make(chan struct{})
+	model := mock.NewMockDB(ctrl)
+	gomock.InOrder(
+		model.EXPECT().
+			GetAllTodos(gomock.Any()).
+			Return([]db.Todo{todo}, nil),
+		model.EXPECT().
+			GetAllTodos(gomock.Any()).
+			DoAndReturn(func(ctx context.Context) ([]db.Todo, error) {
+				close(started)
+				time.Sleep(200 * time.Millisecond)
+				return []db.Todo{todo}, nil
+			}),
+	)
+
+	server := newTestServer(t, model)
+	require.Nil(t, server.Addr())
+	require.Error(t, server.Serve())
+
+	require.NoError(t, server.Listen("127.0.0.1:0"))
+	require.Error(t, server.Listen("127.0.0.1:0"))
+	url := "http://" + server.Addr().String() + "/todos"
+
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve()
+	}()
+
+	response, err := http.Get(url)
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	// Request in progress finishes before Shutdown returns
+	slow := make(chan int, 1)
+	go func() {
+		response, err := http.Get(url)
+		if err != nil {
+			slow <- 0
+			return
+		}
+		response.Body.Close()
+		slow <- response.StatusCode
+	}()
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, server.Shutdown(ctx))
+	require.Equal(t, http.StatusOK, <-slow)
+	require.NoError(t, <-served)
+
+	// New connections are refused
+	_, err = http.Get(url)
+	require.Error(t, err)
+}
+
+func TestStartError(t *testing.T) {
+	listening := NewServer(nil)
+	require.NoError(t, listening.Listen("127.0.0.1:0"))
+	defer listening.Shutdown(context.Background())
+
+	server := NewServer(nil)
+	require.Error(t, server.Start(listening.Addr().String()))
+}
+
+func TestTLS(t *testing.T) {
+	// Certificate of test server is trusted by its client
+	certified := httptest.NewTLSServer(http.NotFoundHandler())
+	certified.Close()
+
+	server := NewServer(nil, func(s *Server) {
+		s.TLSConfig = certified.TLS
+	})
+	require.NoError(t, server.Listen("127.0.0.1:0"))
+	served := make(chan error, 1)
+	go func() {
+		served <- server.Serve()
+	}()
+
+	response, err := certified.Client().Get("https://" + server.Addr().String() + "/healthz")
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	// Plain http is rejected
+	response, err = http.Get("http://" + server.Addr().String() + "/healthz")
+	require.NoError(t, err)
+	response.Body.Close()
+	require.Equal(t, http.StatusBadRequest, response.StatusCode)
+
+	require.NoError(t, server.Shutdown(context.Background()))
+	require.NoError(t, <-served)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vilderxyz/todos/service"
+)
+
+// Request object for updateTodoStatus.
+//
+// Status must be one of statuses of configured workflow.
+//
+// Id must be greater then 1.
+//
+// Otherwise throws 400 status.
+//
+// Example:
+//
+//	{
+//		"id":		123
+//		"status":	"in_progress"
+//	}
+type UpdateTodoStatusRequest struct {
+	Id     int64  `json:"id" binding:"required,min=1"`
+	Status string `json:"status" binding:"required"`
+}
+
+// Finds Todo object from database for given Id. Throws 404 status when not found.
+//
+// Then it moves Todo to requested status and stores it back in database.
+//
+// It throws 400 status when workflow does not allow such transition.
+func (s *Server) updateTodoStatus(ctx *gin.Context) {
+	req := UpdateTodoStatusRequest{}
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+	res, err := s.todos().Update(ctx.Request.Context(), req.Id, service.Status(req.Status), actor(ctx))
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Message: "Updated todo's status",
+		Data:    res,
+	})
+}
+
+// Request object that must contain uri with Id.
+//
+// Id must be greater then 1.
+//
+// Example:
+//
+//	"http://localhost/todos/Id/transitions"
+type GetTodoTransitionsRequest struct {
+	Id int64 `uri:"id" binding:"required,min=1"`
+}
+
+// Returns all status transitions of Todo with given Id from the oldest one.
+//
+// Throws 404 status when Todo has none.
+func (s *Server) getTodoTransitions(ctx *gin.Context) {
+	req := GetTodoTransitionsRequest{}
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	transitions, err := s.Queries.GetTodoTransitions(ctx.Request.Context(), req.Id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return
+	}
+	if len(transitions) == 0 {
+		ctx.JSON(http.StatusNotFound, errorResponse(ctx, fmt.Errorf("not found")))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Message: "Got todo's status transitions",
+		Data:    transitions,
+	})
+}
@@ -1,7 +1,9 @@
 package api
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -13,6 +15,8 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/vilderxyz/todos/db"
 	"github.com/vilderxyz/todos/mock"
+	"github.com/vilderxyz/todos/workflow"
+	"gorm.io/gorm"
 )
 
 // Global mock object for testing
@@ -48,9 +52,10 @@ func getCreateTodoCases(t *testing.T) []CreateTodoCase {
 					Title:       todo.Title,
 					Description: todo.Description,
 					Expiry:      expiryTime,
+					Status:      workflow.Backlog,
 				}
 				model.EXPECT().
-					CreateOneTodo(gomock.Eq(req)).
+					CreateOneTodo(gomock.Any(), gomock.Eq(req), gomock.Any()).
 					Times(1).
 					Return(todo, err)
 			},
@@ -66,7 +71,7 @@ func getCreateTodoCases(t *testing.T) []CreateTodoCase {
 			},
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					CreateOneTodo(gomock.Any()).
+					CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -82,7 +87,7 @@ func getCreateTodoCases(t *testing.T) []CreateTodoCase {
 			},
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					CreateOneTodo(gomock.Any()).
+					CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -98,7 +103,7 @@ func getCreateTodoCases(t *testing.T) []CreateTodoCase {
 			},
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					CreateOneTodo(gomock.Any()).
+					CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -119,9 +124,10 @@ func getCreateTodoCases(t *testing.T) []CreateTodoCase {
 					Title:       todo.Title,
 					Description: todo.Description,
 					Expiry:      expiryTime,
+					Status:      workflow.Backlog,
 				}
 				model.EXPECT().
-					CreateOneTodo(gomock.Eq(req)).
+					CreateOneTodo(gomock.Any(), gomock.Eq(req), gomock.Any()).
 					Times(1).
 					Return(todo, sql.ErrConnDone)
 			},
@@ -146,7 +152,7 @@ func getGetTodoCases(t *testing.T) []GetTodoCase {
 			todoId: todo.Id,
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, nil)
 			},
@@ -159,7 +165,7 @@ func getGetTodoCases(t *testing.T) []GetTodoCase {
 			todoId: -todo.Id,
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetOneTodoById(gomock.Any()).
+					GetOneTodoById(gomock.Any(), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -171,7 +177,7 @@ func getGetTodoCases(t *testing.T) []GetTodoCase {
 			todoId: todo.Id,
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, fmt.Errorf("not found"))
 			},
@@ -184,7 +190,7 @@ func getGetTodoCases(t *testing.T) []GetTodoCase {
 			todoId: todo.Id,
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, sql.ErrConnDone)
 			},
@@ -205,7 +211,7 @@ type UpdateTodoTextCase struct {
 func getUpdateTodoTextCases(t *testing.T) []UpdateTodoTextCase {
 	updatedTitle := "t"
 	updatedDesc := "d"
-	updatedExpiry := "2022-05-30"
+	updatedExpiry := "2222-05-30"
 
 	return []UpdateTodoTextCase{
 		{
@@ -220,8 +226,9 @@ func getUpdateTodoTextCases(t *testing.T) []UpdateTodoTextCase {
 				expiryTime, err := time.Parse("2006-01-02", updatedExpiry)
 				require.NoError(t, err)
 
+				expectTx(model)
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, nil)
 
@@ -230,7 +237,7 @@ func getUpdateTodoTextCases(t *testing.T) []UpdateTodoTextCase {
 				todo.Expiry = expiryTime
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(1).
 					Return(todo, nil)
 			},
@@ -248,7 +255,7 @@ func getUpdateTodoTextCases(t *testing.T) []UpdateTodoTextCase {
 			buildStubs: func(model *mock.MockDB) {
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -265,7 +272,7 @@ func getUpdateTodoTextCases(t *testing.T) []UpdateTodoTextCase {
 			},
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -282,7 +289,7 @@ func getUpdateTodoTextCases(t *testing.T) []UpdateTodoTextCase {
 			},
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -301,8 +308,9 @@ func getUpdateTodoTextCases(t *testing.T) []UpdateTodoTextCase {
 				expiryTime, err := time.Parse("2006-01-02", updatedExpiry)
 				require.NoError(t, err)
 
+				expectTx(model)
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, fmt.Errorf("not found"))
 
@@ -311,7 +319,7 @@ func getUpdateTodoTextCases(t *testing.T) []UpdateTodoTextCase {
 				todo.Expiry = expiryTime
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -330,8 +338,9 @@ func getUpdateTodoTextCases(t *testing.T) []UpdateTodoTextCase {
 				expiryTime, err := time.Parse("2006-01-02", updatedExpiry)
 				require.NoError(t, err)
 
+				expectTx(model)
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, sql.ErrConnDone)
 
@@ -340,7 +349,7 @@ func getUpdateTodoTextCases(t *testing.T) []UpdateTodoTextCase {
 				todo.Expiry = expiryTime
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -359,8 +368,9 @@ func getUpdateTodoTextCases(t *testing.T) []UpdateTodoTextCase {
 				expiryTime, err := time.Parse("2006-01-02", updatedExpiry)
 				require.NoError(t, err)
 
+				expectTx(model)
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, nil)
 
@@ -369,7 +379,7 @@ func getUpdateTodoTextCases(t *testing.T) []UpdateTodoTextCase {
 				todo.Expiry = expiryTime
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(1).
 					Return(todo, sql.ErrConnDone)
 			},
@@ -398,15 +408,16 @@ func getUpdateTodoCompletionCases(t *testing.T) []UpdateTodoCompletionCase {
 				"id":         todo.Id,
 			},
 			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, nil)
 
 				todo.Completion = float32(updatedCompletion)
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(1).
 					Return(todo, nil)
 			},
@@ -421,13 +432,13 @@ func getUpdateTodoCompletionCases(t *testing.T) []UpdateTodoCompletionCase {
 			},
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(0)
 
 				todo.Completion = float32(updatedCompletion)
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -441,15 +452,16 @@ func getUpdateTodoCompletionCases(t *testing.T) []UpdateTodoCompletionCase {
 				"completion": updatedCompletion,
 			},
 			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, fmt.Errorf("not found"))
 
 				todo.Completion = float32(updatedCompletion)
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -463,15 +475,16 @@ func getUpdateTodoCompletionCases(t *testing.T) []UpdateTodoCompletionCase {
 				"completion": updatedCompletion,
 			},
 			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, sql.ErrConnDone)
 
 				todo.Completion = float32(updatedCompletion)
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -485,15 +498,16 @@ func getUpdateTodoCompletionCases(t *testing.T) []UpdateTodoCompletionCase {
 				"completion": updatedCompletion,
 			},
 			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, nil)
 
 				todo.Completion = float32(updatedCompletion)
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -509,15 +523,16 @@ func getUpdateTodoCompletionCases(t *testing.T) []UpdateTodoCompletionCase {
 			buildStubs: func(model *mock.MockDB) {
 				todo.Completion = 0
 
+				expectTx(model)
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, nil)
 
 				todo.Completion = float32(updatedCompletion)
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(1).
 					Return(todo, sql.ErrConnDone)
 			},
@@ -545,15 +560,17 @@ func getUpdateTodoDoneCases(t *testing.T) []UpdateTodoDoneCase {
 				"id":      todo.Id,
 			},
 			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, nil)
 
 				todo.IsDone = true
+				todo.Status = workflow.Done
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(1).
 					Return(todo, nil)
 			},
@@ -568,13 +585,13 @@ func getUpdateTodoDoneCases(t *testing.T) []UpdateTodoDoneCase {
 			},
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(0)
 
 				todo.IsDone = true
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -588,15 +605,16 @@ func getUpdateTodoDoneCases(t *testing.T) []UpdateTodoDoneCase {
 				"id":      todo.Id,
 			},
 			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, fmt.Errorf("not found"))
 
 				todo.IsDone = true
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -610,15 +628,16 @@ func getUpdateTodoDoneCases(t *testing.T) []UpdateTodoDoneCase {
 				"id":      todo.Id,
 			},
 			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, sql.ErrConnDone)
 
 				todo.IsDone = true
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -633,15 +652,16 @@ func getUpdateTodoDoneCases(t *testing.T) []UpdateTodoDoneCase {
 			},
 			buildStubs: func(model *mock.MockDB) {
 				todo.IsDone = true
+				expectTx(model)
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, nil)
 
 				todo.IsDone = true
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -656,15 +676,18 @@ func getUpdateTodoDoneCases(t *testing.T) []UpdateTodoDoneCase {
 			},
 			buildStubs: func(model *mock.MockDB) {
 				todo.IsDone = false
+				todo.Status = workflow.InProgress
+				expectTx(model)
 				model.EXPECT().
-					GetOneTodoById(gomock.Eq(todo.Id)).
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
 					Times(1).
 					Return(todo, nil)
 
 				todo.IsDone = true
+				todo.Status = workflow.Done
 
 				model.EXPECT().
-					UpdateOneTodo(gomock.Eq(todo)).
+					UpdateOneTodo(gomock.Any(), gomock.Eq(todo), gomock.Any()).
 					Times(1).
 					Return(todo, sql.ErrConnDone)
 			},
@@ -691,7 +714,7 @@ func getDeleteTodoCases(t *testing.T) []DeleteTodoCase {
 			todoId: todo.Id,
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					DeleteOneTodo(gomock.Eq(todo.Id)).
+					DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
 					Times(1).
 					Return(nil)
 			},
@@ -704,7 +727,7 @@ func getDeleteTodoCases(t *testing.T) []DeleteTodoCase {
 			todoId: -todo.Id,
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					DeleteOneTodo(gomock.Eq(todo.Id)).
+					DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -716,7 +739,7 @@ func getDeleteTodoCases(t *testing.T) []DeleteTodoCase {
 			todoId: todo.Id,
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					DeleteOneTodo(gomock.Eq(todo.Id)).
+					DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
 					Times(1).
 					Return(fmt.Errorf("not found"))
 			},
@@ -729,7 +752,7 @@ func getDeleteTodoCases(t *testing.T) []DeleteTodoCase {
 			todoId: todo.Id,
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					DeleteOneTodo(gomock.Eq(todo.Id)).
+					DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
 					Times(1).
 					Return(sql.ErrConnDone)
 			},
@@ -759,7 +782,7 @@ func getGetTodosCases(t *testing.T) []GetTodosCase {
 			period: "",
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetAllTodos().
+					GetAllTodos(gomock.Any()).
 					Times(1).
 					Return(todos, nil)
 			},
@@ -772,7 +795,7 @@ func getGetTodosCases(t *testing.T) []GetTodosCase {
 			period: "????",
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetAllTodos().
+					GetAllTodos(gomock.Any()).
 					Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -784,7 +807,7 @@ func getGetTodosCases(t *testing.T) []GetTodosCase {
 			period: "",
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetAllTodos().
+					GetAllTodos(gomock.Any()).
 					Times(1).
 					Return(todos, sql.ErrConnDone)
 			},
@@ -797,7 +820,7 @@ func getGetTodosCases(t *testing.T) []GetTodosCase {
 			period: "today",
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetManyTodos(gomock.Any(), gomock.Any()).
+					GetManyTodos(gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(todos, nil)
 			},
@@ -810,7 +833,7 @@ func getGetTodosCases(t *testing.T) []GetTodosCase {
 			period: "today",
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetManyTodos(gomock.Any(), gomock.Any()).
+					GetManyTodos(gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(todos, sql.ErrConnDone)
 			},
@@ -823,7 +846,7 @@ func getGetTodosCases(t *testing.T) []GetTodosCase {
 			period: "tomorrow",
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetManyTodos(gomock.Any(), gomock.Any()).
+					GetManyTodos(gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(todos, nil)
 			},
@@ -836,7 +859,7 @@ func getGetTodosCases(t *testing.T) []GetTodosCase {
 			period: "tomorrow",
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetManyTodos(gomock.Any(), gomock.Any()).
+					GetManyTodos(gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(todos, sql.ErrConnDone)
 			},
@@ -849,7 +872,7 @@ func getGetTodosCases(t *testing.T) []GetTodosCase {
 			period: "week",
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetManyTodos(gomock.Any(), gomock.Any()).
+					GetManyTodos(gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(todos, nil)
 			},
@@ -862,7 +885,7 @@ func getGetTodosCases(t *testing.T) []GetTodosCase {
 			period: "week",
 			buildStubs: func(model *mock.MockDB) {
 				model.EXPECT().
-					GetManyTodos(gomock.Any(), gomock.Any()).
+					GetManyTodos(gomock.Any(), gomock.Any(), gomock.Any()).
 					Times(1).
 					Return(todos, sql.ErrConnDone)
 			},
@@ -873,3 +896,957 @@ func getGetTodosCases(t *testing.T) []GetTodosCase {
 	}
 
 }
+
+type GetTodoHistoryCase struct {
+	name          string
+	todoId        int64
+	query         string
+	buildStubs    func(model *mock.MockDB)
+	checkResponse func(recorder *httptest.ResponseRecorder)
+}
+
+func getGetTodoHistoryCases(t *testing.T) []GetTodoHistoryCase {
+	entries := []db.History{
+		{
+			Id:        1,
+			TodoId:    todo.Id,
+			Actor:     "user",
+			Operation: db.OpUpdate,
+			Changes:   db.Changes{"title": {Before: "old", After: todo.Title}},
+			CreatedAt: time.Now(),
+		},
+	}
+
+	return []GetTodoHistoryCase{
+		{
+			name:   "StatusOK - default page",
+			todoId: todo.Id,
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetTodoHistory(gomock.Any(), gomock.Eq(todo.Id), gomock.Eq(20), gomock.Eq(0)).
+					Times(1).
+					Return(entries, int64(1), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "StatusOK - given page",
+			todoId: todo.Id,
+			query:  "page=3&per_page=5",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetTodoHistory(gomock.Any(), gomock.Eq(todo.Id), gomock.Eq(5), gomock.Eq(10)).
+					Times(1).
+					Return(entries, int64(11), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest - invalid id",
+			todoId: -todo.Id,
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetTodoHistory(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest - page size too big",
+			todoId: todo.Id,
+			query:  "per_page=1000",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetTodoHistory(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound",
+			todoId: todo.Id,
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetTodoHistory(gomock.Any(), gomock.Eq(todo.Id), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, int64(0), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError - database connection",
+			todoId: todo.Id,
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetTodoHistory(gomock.Any(), gomock.Eq(todo.Id), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil, int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+}
+
+type TrashCase struct {
+	name          string
+	method        string
+	url           string
+	buildStubs    func(model *mock.MockDB)
+	checkResponse func(recorder *httptest.ResponseRecorder)
+}
+
+func getTrashCases(t *testing.T) []TrashCase {
+	trashed := todo
+	trashed.DeletedAt = gorm.DeletedAt{Time: time.Now(), Valid: true}
+
+	return []TrashCase{
+		{
+			name:   "StatusOK - get trash",
+			method: http.MethodGet,
+			url:    "/trash",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetTrashedTodos(gomock.Any()).
+					Times(1).
+					Return([]db.Todo{trashed}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError - get trash database connection",
+			method: http.MethodGet,
+			url:    "/trash",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetTrashedTodos(gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:   "StatusOK - restore",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/todos/%d/restore", todo.Id),
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					RestoreOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+					Times(1).
+					Return(todo, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest - restore invalid id",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/todos/%d/restore", -todo.Id),
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					RestoreOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound - restore todo not in trash",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/todos/%d/restore", todo.Id),
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					RestoreOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+					Times(1).
+					Return(db.Todo{}, fmt.Errorf("not found"))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "StatusOK - purge todo",
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("/trash/%d", todo.Id),
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					PurgeOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound - purge todo not in trash",
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("/trash/%d", todo.Id),
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					PurgeOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+					Times(1).
+					Return(fmt.Errorf("not found"))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError - purge todo database connection",
+			method: http.MethodDelete,
+			url:    fmt.Sprintf("/trash/%d", todo.Id),
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					PurgeOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+					Times(1).
+					Return(sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:   "StatusOK - purge trash",
+			method: http.MethodDelete,
+			url:    "/trash?older_than=720h",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					PurgeTrash(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, before time.Time, _ string) (int64, error) {
+						require.WithinDuration(t, time.Now().Add(-720*time.Hour), before, time.Minute)
+						return 3, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "StatusOK - purge whole trash",
+			method: http.MethodDelete,
+			url:    "/trash?older_than=0s",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					PurgeTrash(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, before time.Time, _ string) (int64, error) {
+						require.WithinDuration(t, time.Now(), before, time.Minute)
+						return 3, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest - purge trash without older_than",
+			method: http.MethodDelete,
+			url:    "/trash",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					PurgeTrash(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest - purge trash invalid older_than",
+			method: http.MethodDelete,
+			url:    "/trash?older_than=month",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					PurgeTrash(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest - purge trash negative older_than",
+			method: http.MethodDelete,
+			url:    "/trash?older_than=-1h",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					PurgeTrash(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError - purge trash database connection",
+			method: http.MethodDelete,
+			url:    "/trash?older_than=0s",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					PurgeTrash(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+}
+
+type StatusCase struct {
+	name          string
+	method        string
+	url           string
+	body          gin.H
+	buildStubs    func(model *mock.MockDB)
+	checkResponse func(recorder *httptest.ResponseRecorder)
+}
+
+func getStatusCases(t *testing.T) []StatusCase {
+	backlog := todo
+	backlog.Status = workflow.Backlog
+	backlog.IsDone = false
+
+	done := todo
+	done.Status = workflow.Done
+	done.IsDone = true
+
+	reopened := done
+	reopened.Status = workflow.Reopened
+	reopened.IsDone = false
+
+	inProgress := backlog
+	inProgress.Status = workflow.InProgress
+
+	return []StatusCase{
+		{
+			name:   "StatusOK - change status",
+			method: http.MethodPatch,
+			url:    "/todos/status",
+			body:   gin.H{"id": todo.Id, "status": workflow.InProgress},
+			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(backlog, nil)
+				model.EXPECT().
+					UpdateOneTodo(gomock.Any(), gomock.Eq(inProgress), gomock.Any()).
+					Times(1).
+					Return(inProgress, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "StatusOK - change status of todo without one",
+			method: http.MethodPatch,
+			url:    "/todos/status",
+			body:   gin.H{"id": todo.Id, "status": workflow.Reopened},
+			buildStubs: func(model *mock.MockDB) {
+				legacy := done
+				legacy.Status = ""
+				expectTx(model)
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(legacy, nil)
+				model.EXPECT().
+					UpdateOneTodo(gomock.Any(), gomock.Eq(reopened), gomock.Any()).
+					Times(1).
+					Return(reopened, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest - unknown status",
+			method: http.MethodPatch,
+			url:    "/todos/status",
+			body:   gin.H{"id": todo.Id, "status": "cancelled"},
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest - transition not allowed",
+			method: http.MethodPatch,
+			url:    "/todos/status",
+			body:   gin.H{"id": todo.Id, "status": workflow.InProgress},
+			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(done, nil)
+				model.EXPECT().
+					UpdateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), "allowed: reopened")
+			},
+		},
+		{
+			name:   "NotFound - change status",
+			method: http.MethodPatch,
+			url:    "/todos/status",
+			body:   gin.H{"id": todo.Id, "status": workflow.InProgress},
+			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(db.Todo{}, fmt.Errorf("not found"))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "StatusOK - reopen done todo",
+			method: http.MethodPatch,
+			url:    "/todos/done",
+			body:   gin.H{"id": todo.Id, "is_done": false},
+			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(done, nil)
+				model.EXPECT().
+					UpdateOneTodo(gomock.Any(), gomock.Eq(reopened), gomock.Any()).
+					Times(1).
+					Return(reopened, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest - reopen unfinished todo",
+			method: http.MethodPatch,
+			url:    "/todos/done",
+			body:   gin.H{"id": todo.Id, "is_done": false},
+			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(backlog, nil)
+				model.EXPECT().
+					UpdateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "StatusOK - lower completion of reopened todo",
+			method: http.MethodPatch,
+			url:    "/todos/completion",
+			body:   gin.H{"id": todo.Id, "completion": 10},
+			buildStubs: func(model *mock.MockDB) {
+				before := reopened
+				before.Completion = 100
+				after := reopened
+				after.Completion = 10
+				expectTx(model)
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(before, nil)
+				model.EXPECT().
+					UpdateOneTodo(gomock.Any(), gomock.Eq(after), gomock.Any()).
+					Times(1).
+					Return(after, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "StatusOK - get transitions",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/todos/%d/transitions", todo.Id),
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetTodoTransitions(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return([]db.Transition{{TodoId: todo.Id, To: workflow.Backlog}}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound - get transitions",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/todos/%d/transitions", todo.Id),
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetTodoTransitions(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(nil, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError - get transitions database connection",
+			method: http.MethodGet,
+			url:    fmt.Sprintf("/todos/%d/transitions", todo.Id),
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetTodoTransitions(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+}
+
+type ArchiveCase struct {
+	name          string
+	method        string
+	url           string
+	body          gin.H
+	buildStubs    func(model *mock.MockDB)
+	checkResponse func(recorder *httptest.ResponseRecorder)
+}
+
+func getArchiveCases(t *testing.T) []ArchiveCase {
+	archivedAt := time.Now()
+	archived := todo
+	archived.ArchivedAt = &archivedAt
+
+	active := todo
+	active.ArchivedAt = nil
+
+	anyTodo := gomock.AssignableToTypeOf(db.Todo{})
+
+	return []ArchiveCase{
+		{
+			name:   "StatusOK - archive",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/todos/%d/archive", todo.Id),
+			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(active, nil)
+				model.EXPECT().
+					UpdateOneTodo(gomock.Any(), anyTodo, gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, todo db.Todo, actor string) (db.Todo, error) {
+						require.NotNil(t, todo.ArchivedAt)
+						return todo, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest - archive archived todo",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/todos/%d/archive", todo.Id),
+			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(archived, nil)
+				model.EXPECT().
+					UpdateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "NotFound - archive",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/todos/%d/archive", todo.Id),
+			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(db.Todo{}, fmt.Errorf("not found"))
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name:   "StatusOK - unarchive",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/todos/%d/unarchive", todo.Id),
+			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(archived, nil)
+				model.EXPECT().
+					UpdateOneTodo(gomock.Any(), gomock.Eq(active), gomock.Any()).
+					Times(1).
+					Return(active, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest - unarchive active todo",
+			method: http.MethodPost,
+			url:    fmt.Sprintf("/todos/%d/unarchive", todo.Id),
+			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(active, nil)
+				model.EXPECT().
+					UpdateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "StatusOK - archive done todos",
+			method: http.MethodPost,
+			url:    "/todos/archive",
+			body:   gin.H{"done_before": "2022-05-01"},
+			buildStubs: func(model *mock.MockDB) {
+				doneBefore, err := time.Parse("2006-01-02", "2022-05-01")
+				require.NoError(t, err)
+				model.EXPECT().
+					ArchiveDoneTodos(gomock.Any(), gomock.Eq(doneBefore), gomock.Any()).
+					Times(1).
+					Return(int64(5), nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest - archive done todos invalid date",
+			method: http.MethodPost,
+			url:    "/todos/archive",
+			body:   gin.H{"done_before": "2022-13-01"},
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					ArchiveDoneTodos(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError - archive done todos database connection",
+			method: http.MethodPost,
+			url:    "/todos/archive",
+			body:   gin.H{"done_before": "2022-05-01"},
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					ArchiveDoneTodos(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(int64(0), sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name:   "StatusOK - get archived todos",
+			method: http.MethodGet,
+			url:    "/todos?archived=true",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetAllTodos(gomock.Any()).
+					Times(0)
+				model.EXPECT().
+					GetArchivedTodos(gomock.Any()).
+					Times(1).
+					Return([]db.Todo{archived}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name:   "BadRequest - get archived todos with period",
+			method: http.MethodGet,
+			url:    "/todos?archived=true&period=today",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetArchivedTodos(gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name:   "InternalError - get archived todos database connection",
+			method: http.MethodGet,
+			url:    "/todos?archived=true",
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					GetArchivedTodos(gomock.Any()).
+					Times(1).
+					Return(nil, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+}
+
+type BatchCase struct {
+	name          string
+	body          gin.H
+	buildStubs    func(model *mock.MockDB)
+	checkResponse func(recorder *httptest.ResponseRecorder)
+}
+
+// Runs transaction on the same mock
+func expectTx(model *mock.MockDB) {
+	model.EXPECT().
+		WithTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, fn func(db.DB) error) error {
+			return fn(model)
+		})
+}
+
+// Decodes results of batch operations from response body
+func batchResults(t *testing.T, recorder *httptest.ResponseRecorder) []BatchResult {
+	var res struct {
+		Data []BatchResult `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &res))
+	return res.Data
+}
+
+func getBatchCases(t *testing.T) []BatchCase {
+	batchTodo := todo
+	batchTodo.IsDone = false
+	batchTodo.Status = workflow.InProgress
+
+	done := batchTodo
+	done.IsDone = true
+	done.Status = workflow.Done
+
+	operations := []gin.H{
+		{"op": "create", "title": "title", "description": "desc", "expiry": "2222-05-22"},
+		{"op": "complete", "id": todo.Id},
+		{"op": "delete", "id": todo.Id},
+	}
+
+	return []BatchCase{
+		{
+			name: "StatusOK - atomic",
+			body: gin.H{"operations": operations},
+			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
+				model.EXPECT().
+					CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(batchTodo, nil)
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(batchTodo, nil)
+				model.EXPECT().
+					UpdateOneTodo(gomock.Any(), gomock.Eq(done), gomock.Any()).
+					Times(1).
+					Return(done, nil)
+				model.EXPECT().
+					DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+					Times(1).
+					Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				results := batchResults(t, recorder)
+				require.Len(t, results, 3)
+				for _, res := range results {
+					require.Equal(t, http.StatusOK, res.Status)
+				}
+				require.Nil(t, results[2].Data)
+			},
+		},
+		{
+			name: "NotFound - atomic rolled back",
+			body: gin.H{"mode": BatchAtomic, "operations": operations},
+			buildStubs: func(model *mock.MockDB) {
+				expectTx(model)
+				model.EXPECT().
+					CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(batchTodo, nil)
+				model.EXPECT().
+					GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+					Times(1).
+					Return(db.Todo{}, fmt.Errorf("not found"))
+				model.EXPECT().
+					DeleteOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+				results := batchResults(t, recorder)
+				require.Len(t, results, 3)
+				require.Equal(t, http.StatusFailedDependency, results[0].Status)
+				require.Equal(t, http.StatusNotFound, results[1].Status)
+				require.Equal(t, http.StatusFailedDependency, results[2].Status)
+			},
+		},
+		{
+			name: "InternalError - atomic commit",
+			body: gin.H{"operations": operations[:1]},
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					WithTx(gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, fn func(db.DB) error) error {
+						require.NoError(t, fn(model))
+						return sql.ErrConnDone
+					})
+				model.EXPECT().
+					CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(batchTodo, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "StatusOK - best effort",
+			body: gin.H{
+				"mode": BatchBestEffort,
+				"operations": []gin.H{
+					{"op": "create", "title": "title", "description": "desc", "expiry": "2010-05-22"},
+					{"op": "complete", "id": todo.Id},
+					{"op": "update", "id": todo.Id, "title": "t", "description": "d", "expiry": "2222-05-22"},
+					{"op": "delete"},
+				},
+			},
+			buildStubs: func(model *mock.MockDB) {
+				// Every change of existing Todo locks it in a transaction of its own
+				model.EXPECT().
+					WithTx(gomock.Any(), gomock.Any()).
+					Times(2).
+					DoAndReturn(func(ctx context.Context, fn func(db.DB) error) error {
+						return fn(model)
+					})
+				model.EXPECT().
+					CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(0)
+				gomock.InOrder(
+					model.EXPECT().
+						GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+						Return(done, nil),
+					model.EXPECT().
+						GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+						Return(batchTodo, nil),
+				)
+				model.EXPECT().
+					UpdateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					DoAndReturn(func(_ context.Context, todo db.Todo, actor string) (db.Todo, error) {
+						require.Equal(t, "t", todo.Title)
+						return todo, nil
+					})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				results := batchResults(t, recorder)
+				require.Len(t, results, 4)
+				require.Equal(t, http.StatusBadRequest, results[0].Status)
+				require.Equal(t, http.StatusBadRequest, results[1].Status)
+				require.Equal(t, http.StatusOK, results[2].Status)
+				require.Equal(t, http.StatusBadRequest, results[3].Status)
+			},
+		},
+		{
+			name: "BadRequest - unknown operation",
+			body: gin.H{"operations": []gin.H{{"op": "archive", "id": todo.Id}}},
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					WithTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest - unknown mode",
+			body: gin.H{"mode": "eventually", "operations": operations},
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					WithTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "BadRequest - no operations",
+			body: gin.H{"operations": []gin.H{}},
+			buildStubs: func(model *mock.MockDB) {
+				model.EXPECT().
+					WithTx(gomock.Any(), gomock.Any()).
+					Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+}
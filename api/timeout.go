@@ -0,0 +1,31 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Default time limit of database queries executed for a single request.
+var DefaultQueryTimeout = 5 * time.Second
+
+// Returns middleware that cancels request's context after s.QueryTimeout,
+// so database queries of a single request cannot run longer.
+//
+// Queries are cancelled as well when client disconnects.
+// Zero or negative timeout disables the limit.
+func (s *Server) queryTimeout() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if s.QueryTimeout <= 0 {
+			ctx.Next()
+			return
+		}
+
+		c, cancel := context.WithTimeout(ctx.Request.Context(), s.QueryTimeout)
+		defer cancel()
+
+		ctx.Request = ctx.Request.WithContext(c)
+		ctx.Next()
+	}
+}
@@ -0,0 +1,97 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/mock"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestQueryTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, id int64) (db.Todo, error) {
+			deadline, ok := ctx.Deadline()
+			require.True(t, ok)
+			require.WithinDuration(t, time.Now().Add(time.Second), deadline, 100*time.Millisecond)
+
+			<-ctx.Done()
+			return db.Todo{}, ctx.Err()
+		})
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		Times(1).
+		DoAndReturn(func(ctx context.Context, id int64) (db.Todo, error) {
+			_, ok := ctx.Deadline()
+			require.False(t, ok)
+			return todo, nil
+		})
+
+	server := newTestServer(t, model)
+	server.QueryTimeout = time.Second
+
+	send := func() *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/todos/%d", todo.Id), nil)
+		require.NoError(t, err)
+		server.Router.ServeHTTP(recorder, request)
+		return recorder
+	}
+
+	recorder := send()
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+
+	// Zero timeout disables the limit
+	server.QueryTimeout = 0
+	recorder = send()
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+// Connections whose queries end only when their context is done.
+// Other methods of gorm.ConnPool are not used by the queries under test.
+type blockingConnPool struct {
+	gorm.ConnPool
+}
+
+func (blockingConnPool) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestQueryTimeoutOfQueries(t *testing.T) {
+	conn, err := gorm.Open(postgres.New(postgres.Config{Conn: blockingConnPool{}}), &gorm.Config{
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+
+	server := NewServer(nil)
+	server.Queries = db.New(conn)
+	server.QueryTimeout = 50 * time.Millisecond
+
+	// Timed out queries find no rows, yet they are not reported as missing Todos
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err = server.Queries.GetOneTodoById(ctx, todo.Id)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/todos/%d", todo.Id), nil)
+	require.NoError(t, err)
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+}
@@ -1,12 +1,10 @@
 package api
 
 import (
-	"fmt"
 	"net/http"
-	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/service"
 )
 
 // General response object for successful requests.
@@ -41,27 +39,23 @@ type CreateTodoRequest struct {
 func (s *Server) createTodo(ctx *gin.Context) {
 	req := CreateTodoRequest{}
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
 		return
 	}
 
-	expiryTime, err := time.Parse("2006-01-02", req.Expiry)
+	expiryTime, err := service.ParseExpiry(req.Expiry)
 	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-	if expiryTime.Before(time.Now()) {
-		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("wrong date")))
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
 		return
 	}
 
-	res, err := s.Queries.CreateOneTodo(db.CreateTodoParams{
+	res, err := s.todos().CreateTodo(ctx.Request.Context(), service.TodoInfo{
 		Title:       req.Title,
 		Description: req.Description,
 		Expiry:      expiryTime,
-	})
+	}, actor(ctx))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(ctx, err))
 		return
 	}
 
@@ -81,22 +75,24 @@ type GetTodoByIdRequest struct {
 	Id int64 `uri:"id" binding:"required,min=1"`
 }
 
-// Returns Todo object for given Id.
+// Returns Todo object for given Id with its ETag and Last-Modified.
+//
+// Returns 304 status when If-None-Match or If-Modified-Since shows
+// client's copy is current.
 //
 // Throws 404 status when not found.
 func (s *Server) getTodoById(ctx *gin.Context) {
 	req := GetTodoByIdRequest{}
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
 		return
 	}
-	res, err := s.Queries.GetOneTodoById(req.Id)
+	res, err := s.todos().GetTodo(ctx.Request.Context(), req.Id)
 	if err != nil {
-		if err.Error() == "not found" {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(ctx, err))
+		return
+	}
+	if notModified(ctx, todosETag(res), res.UpdatedAt, true) {
 		return
 	}
 
@@ -137,37 +133,23 @@ type UpdateTodoInfoRequest struct {
 func (s *Server) updateTodoTextInfo(ctx *gin.Context) {
 	req := UpdateTodoInfoRequest{}
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-
-	expiryTime, err := time.Parse("2006-01-02", req.Expiry)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-	if expiryTime.Before(time.Now()) {
-		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("wrong date")))
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
 		return
 	}
 
-	todo, err := s.Queries.GetOneTodoById(req.Id)
+	expiryTime, err := service.ParseExpiry(req.Expiry)
 	if err != nil {
-		if err.Error() == "not found" {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
 		return
 	}
 
-	todo.Description = req.Description
-	todo.Expiry = expiryTime
-	todo.Title = req.Title
-
-	res, err := s.Queries.UpdateOneTodo(todo)
+	res, err := s.todos().Update(ctx.Request.Context(), req.Id, service.Info(service.TodoInfo{
+		Title:       req.Title,
+		Description: req.Description,
+		Expiry:      expiryTime,
+	}), actor(ctx))
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(ctx, err))
 		return
 	}
 
@@ -200,34 +182,18 @@ type UpdateTodoCompletionRequest struct {
 //
 // Then it replaces its Completion parameter with requested one and stores it back in database.
 //
-// It throws 400 status when requested completion value is lower than the actual one.
+// It throws 400 status when requested completion value is lower than the actual one,
+// unless Todo was reopened.
 func (s *Server) updateTodoCompletionInfo(ctx *gin.Context) {
 	req := UpdateTodoCompletionRequest{}
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
 		return
 	}
 
-	todo, err := s.Queries.GetOneTodoById(req.Id)
+	res, err := s.todos().Update(ctx.Request.Context(), req.Id, service.Completion(req.Completion), actor(ctx))
 	if err != nil {
-		if err.Error() == "not found" {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	if todo.Completion >= req.Completion {
-		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("requsted completion progress is lower then the actual one")))
-		return
-	}
-
-	todo.Completion = req.Completion
-
-	res, err := s.Queries.UpdateOneTodo(todo)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(ctx, err))
 		return
 	}
 
@@ -237,9 +203,9 @@ func (s *Server) updateTodoCompletionInfo(ctx *gin.Context) {
 	})
 }
 
-// Request object for updateTodoCompletionInfo.
+// Request object for updateTodoDoneInfo.
 //
-// IsDone must be true.
+// IsDone must be given. False reopens finished Todo.
 //
 // Id must be greater then 1.
 //
@@ -252,41 +218,25 @@ func (s *Server) updateTodoCompletionInfo(ctx *gin.Context) {
 //	}
 type UpdateTodoDoneRequest struct {
 	Id     int64 `json:"id" binding:"required,min=1"`
-	IsDone bool  `json:"is_done" binding:"required"`
+	IsDone *bool `json:"is_done" binding:"required"`
 }
 
 // Finds Todo object from database for given Id. Throws 404 status when not found.
 //
-// Then it replaces its IsDone parameter with requested one and stores it back in database.
+// Then it moves Todo to "done" status or reopens it and stores it back in database.
 //
-// It throws 400 status when Todo is already finished.
+// It throws 400 status when Todo is already finished or not finished when reopening,
+// and when workflow does not allow such transition.
 func (s *Server) updateTodoDoneInfo(ctx *gin.Context) {
 	req := UpdateTodoDoneRequest{}
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
 		return
 	}
 
-	todo, err := s.Queries.GetOneTodoById(req.Id)
+	res, err := s.todos().Update(ctx.Request.Context(), req.Id, service.Done(*req.IsDone), actor(ctx))
 	if err != nil {
-		if err.Error() == "not found" {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	if todo.IsDone || !req.IsDone {
-		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("todo is already done")))
-		return
-	}
-
-	todo.IsDone = req.IsDone
-
-	res, err := s.Queries.UpdateOneTodo(todo)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		ctx.JSON(errorStatus(err), errorResponse(ctx, err))
 		return
 	}
 
@@ -306,23 +256,18 @@ type DeleteTodoRequest struct {
 	Id int64 `uri:"id" binding:"required,min=1"`
 }
 
-// Deletes Todo with given Id.
+// Moves Todo with given Id to trash.
 //
 // Throws 404 when it deleted nothing.
 func (s *Server) deleteTodo(ctx *gin.Context) {
 	req := DeleteTodoRequest{}
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
 		return
 	}
 
-	err := s.Queries.DeleteOneTodo(req.Id)
-	if err != nil {
-		if err.Error() == "not found" {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+	if err := s.todos().DeleteTodo(ctx.Request.Context(), req.Id, actor(ctx)); err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(ctx, err))
 		return
 	}
 
@@ -331,93 +276,64 @@ func (s *Server) deleteTodo(ctx *gin.Context) {
 	})
 }
 
-// Request object with period query. Can be omitted.
+// Request object with period and archived queries. Both can be omitted.
 //
 // Period must be string and of one [ "today" , "tomorrow" , "week" , ""].
 //
+// Archived must be boolean and cannot be combined with Period.
+//
 // Otherwise throws 400 status.
 //
+// Archived Todos are excluded unless Archived is true.
+//
 // Examples:
 //	"http://localhost/todos" 				- gets all finished and unfinished Todos
 //	"http://localhost/todos?period=today"    - gets all unfinished Todos that expires after today
 //	"http://localhost/todos?period=tomorrow" - gets all unfinished Todos that expires after tomorrow
 //	"http://localhost/todos?period=week" 	- gets all unfinished Todos that expires after Sunday this week
+//	"http://localhost/todos?archived=true" 	- gets only archived Todos
 type GetTodosRequest struct {
-	Period string `form:"period" binding:"period"`
+	Period   string `form:"period" binding:"period"`
+	Archived bool   `form:"archived"`
+}
+
+// Messages of responses with Todos of given period.
+var periodMessages = map[string]string{
+	service.Today:    "Got all todos for today",
+	service.Tomorrow: "Got all todos for tomorrow",
+	service.Week:     "Got all todos for this week",
 }
 
-// Gets slice of Todo objects depending on given Period query.
+// Gets slice of Todo objects depending on given Period query,
+// with ETag and Last-Modified of the whole slice.
+//
+// Returns 304 status when If-None-Match matches the ETag.
 func (s *Server) getTodos(ctx *gin.Context) {
 	req := GetTodosRequest{}
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
 		return
 	}
 
-	var todos []db.Todo
-	var err error
-	var message string
-
-	switch req.Period {
-	case "today":
-		day := time.Now().AddDate(0, 0, 1).Format("2006-01-02")
-		endTime, err := time.Parse("2006-01-02", day)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-
-		todos, err = s.Queries.GetManyTodos(time.Now(), endTime)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-		message = "Got all todos for today"
-
-	case "tomorrow":
-		day := time.Now().AddDate(0, 0, 2).Format("2006-01-02")
-		endTime, err := time.Parse("2006-01-02", day)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-
-		todos, err = s.Queries.GetManyTodos(time.Now(), endTime)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-		message = "Got all todos for tomorrow"
-
-	case "week":
-		addDays := 8 - int(time.Now().Weekday())
-		day := time.Now().AddDate(0, 0, addDays).Format("2006-01-02")
-		endTime, err := time.Parse("2006-01-02", day)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-
-		todos, err = s.Queries.GetManyTodos(time.Now(), endTime)
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-		message = "Got all todos for this week"
-
-	case "":
-		todos, err = s.Queries.GetAllTodos()
-		if err != nil {
-			ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-			return
-		}
-		message = "Got all todos"
-
-	default:
-		ctx.JSON(http.StatusBadRequest, errorResponse(fmt.Errorf("wrong param")))
+	todos, err := s.todos().ListTodos(ctx.Request.Context(), service.ListParams{
+		Period:   req.Period,
+		Archived: req.Archived,
+	})
+	if err != nil {
+		ctx.JSON(errorStatus(err), errorResponse(ctx, err))
+		return
+	}
+	if notModified(ctx, todosETag(todos...), lastModified(todos...), false) {
 		return
 	}
 
+	message := "Got all todos"
+	if req.Archived {
+		message = "Got all archived todos"
+	} else if req.Period != "" {
+		message = periodMessages[req.Period]
+	}
+
 	ctx.JSON(http.StatusOK, Response{
 		Message: message,
 		Data:    todos,
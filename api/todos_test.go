@@ -213,3 +213,166 @@ func TestGetTodos(t *testing.T) {
 		})
 	}
 }
+
+func TestGetTodoHistory(t *testing.T) {
+
+	testCases := getGetTodoHistoryCases(t)
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			model := mock.NewMockDB(ctrl)
+			tc.buildStubs(model)
+
+			server := newTestServer(t, model)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/todos/%d/history?%v", tc.todoId, tc.query)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestMutationActor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Eq("ip:10.0.0.1")).
+		Times(1).
+		Return(nil)
+
+	server := newTestServer(t, model)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/todos/%d", todo.Id)
+	request, err := http.NewRequest(http.MethodDelete, url, nil)
+	require.NoError(t, err)
+	request.RemoteAddr = "10.0.0.1:1234"
+
+	server.Router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+}
+
+func TestTrash(t *testing.T) {
+
+	testCases := getTrashCases(t)
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			model := mock.NewMockDB(ctrl)
+			tc.buildStubs(model)
+
+			server := newTestServer(t, model)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(tc.method, tc.url, nil)
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestStatus(t *testing.T) {
+
+	testCases := getStatusCases(t)
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			model := mock.NewMockDB(ctrl)
+			tc.buildStubs(model)
+
+			server := newTestServer(t, model)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(tc.method, tc.url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestArchive(t *testing.T) {
+
+	testCases := getArchiveCases(t)
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			model := mock.NewMockDB(ctrl)
+			tc.buildStubs(model)
+
+			server := newTestServer(t, model)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(tc.method, tc.url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestBatch(t *testing.T) {
+
+	testCases := getBatchCases(t)
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			model := mock.NewMockDB(ctrl)
+			tc.buildStubs(model)
+
+			server := newTestServer(t, model)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := "/todos/batch"
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.Router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/vilderxyz/todos/logging"
+	"github.com/vilderxyz/todos/metrics"
+	"github.com/vilderxyz/todos/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/semconv/v1.17.0/httpconv"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Returns middleware running every request in a server span named after its route,
+// e.g. "GET /todos/:id", which continues trace of traceparent header sent by client.
+//
+// Spans of database queries made by handlers are its children.
+func traceRequests() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		route := ctx.FullPath()
+		if route == "" {
+			route = metrics.UnmatchedRoute
+		}
+
+		parent := otel.GetTextMapPropagator().Extract(ctx.Request.Context(), propagation.HeaderCarrier(ctx.Request.Header))
+		c, span := tracing.Tracer().Start(parent, ctx.Request.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(httpconv.ServerRequest("", ctx.Request)...),
+			trace.WithAttributes(
+				semconv.HTTPRoute(route),
+				attribute.String("http.request_id", logging.RequestID(ctx.Request.Context())),
+			),
+		)
+		defer span.End()
+
+		ctx.Request = ctx.Request.WithContext(c)
+		ctx.Next()
+
+		status := ctx.Writer.Status()
+		span.SetAttributes(semconv.HTTPStatusCode(status))
+		span.SetStatus(httpconv.ServerStatus(status))
+		for _, err := range ctx.Errors {
+			span.RecordError(err.Err)
+		}
+	}
+}
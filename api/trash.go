@@ -0,0 +1,139 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Gets slice of Todo objects that were moved to trash.
+func (s *Server) getTrash(ctx *gin.Context) {
+	todos, err := s.Queries.GetTrashedTodos(ctx.Request.Context())
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Message: "Got all todos from trash",
+		Data:    todos,
+	})
+}
+
+// Request object that must contain uri with Id.
+//
+// Id must be greater then 1.
+//
+// Example:
+//
+//	"http://localhost/todos/Id/restore"
+type RestoreTodoRequest struct {
+	Id int64 `uri:"id" binding:"required,min=1"`
+}
+
+// Moves Todo with given Id out of trash.
+//
+// Throws 404 when Todo is not in trash.
+func (s *Server) restoreTodo(ctx *gin.Context) {
+	req := RestoreTodoRequest{}
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	res, err := s.Queries.RestoreOneTodo(ctx.Request.Context(), req.Id, actor(ctx))
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(ctx, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Message: "Restored todo",
+		Data:    res,
+	})
+}
+
+// Request object that must contain uri with Id.
+//
+// Id must be greater then 1.
+//
+// Example:
+//
+//	"http://localhost/trash/Id"
+type PurgeTodoRequest struct {
+	Id int64 `uri:"id" binding:"required,min=1"`
+}
+
+// Permanently removes Todo with given Id from trash.
+//
+// Throws 404 when Todo is not in trash.
+func (s *Server) purgeTodo(ctx *gin.Context) {
+	req := PurgeTodoRequest{}
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+
+	err := s.Queries.PurgeOneTodo(ctx.Request.Context(), req.Id, actor(ctx))
+	if err != nil {
+		if err.Error() == "not found" {
+			ctx.JSON(http.StatusNotFound, errorResponse(ctx, err))
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Message: "Purged todo",
+	})
+}
+
+// Request object that must contain query with time Todos must be kept
+// in trash for to get purged, e.g. "720h", "0s" purges all of them.
+//
+// Otherwise throws 400 status.
+//
+// Example:
+//
+//	"http://localhost/trash?older_than=720h"
+type PurgeTrashRequest struct {
+	OlderThan string `form:"older_than" binding:"required"`
+}
+
+// Permanently removes Todos moved to trash longer than given time ago.
+//
+// Responds with number of removed Todos.
+func (s *Server) purgeTrash(ctx *gin.Context) {
+	req := PurgeTrashRequest{}
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+	olderThan, err := time.ParseDuration(req.OlderThan)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, err))
+		return
+	}
+	if olderThan < 0 {
+		ctx.JSON(http.StatusBadRequest, errorResponse(ctx, errors.New("older_than must not be negative")))
+		return
+	}
+
+	purged, err := s.Queries.PurgeTrash(ctx.Request.Context(), time.Now().Add(-olderThan), actor(ctx))
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, errorResponse(ctx, err))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, Response{
+		Message: "Purged trash",
+		Data:    gin.H{"purged": purged},
+	})
+}
@@ -0,0 +1,113 @@
+// Package cache keeps results of database queries in memory of the application,
+// so repeated reads of unchanged Todos do not hit the database.
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// Least recently used values by key, removed after their TTL.
+//
+// Values stored after Clear are dropped when they were loaded before it,
+// so reads racing with writes do not bring back stale values.
+type LRU[V any] struct {
+	size int
+	ttl  time.Duration
+
+	mu sync.Mutex
+	// Elements of order by their keys, the most recently used at the front
+	items map[string]*list.Element
+	order *list.List
+	// Incremented by every Clear
+	generation uint64
+
+	now func() time.Time
+}
+
+// Value of LRU with its key and expiry time.
+type entry[V any] struct {
+	key     string
+	value   V
+	expires time.Time
+}
+
+// Returns LRU holding at most size values, each of them for ttl.
+func NewLRU[V any](size int, ttl time.Duration) *LRU[V] {
+	return &LRU[V]{
+		size:  size,
+		ttl:   ttl,
+		items: map[string]*list.Element{},
+		order: list.New(),
+		now:   time.Now,
+	}
+}
+
+// Returns value of key and reports whether it was found and did not expire.
+func (c *LRU[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	element, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	e := element.Value.(*entry[V])
+	if !c.now().Before(e.expires) {
+		c.remove(element)
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(element)
+	return e.value, true
+}
+
+// Returns generation of LRU, which must be passed to Put of values loaded after.
+func (c *LRU[V]) Generation() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.generation
+}
+
+// Stores value of key loaded in given generation, unless LRU was cleared since.
+// The least recently used value is removed when LRU is full.
+func (c *LRU[V]) Put(key string, generation uint64, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	if element, ok := c.items[key]; ok {
+		c.remove(element)
+	}
+	c.items[key] = c.order.PushFront(&entry[V]{key: key, value: value, expires: c.now().Add(c.ttl)})
+	if c.order.Len() > c.size {
+		c.remove(c.order.Back())
+	}
+}
+
+// Removes all values.
+func (c *LRU[V]) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+	c.items = map[string]*list.Element{}
+	c.order.Init()
+}
+
+// Returns number of stored values, expired ones included.
+func (c *LRU[V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// Removes element, mu must be held.
+func (c *LRU[V]) remove(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*entry[V]).key)
+}
@@ -0,0 +1,112 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/metrics"
+	"github.com/vilderxyz/todos/mock"
+)
+
+func TestLRU(t *testing.T) {
+	now := time.Now()
+	c := NewLRU[int](2, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Put("a", c.Generation(), 1)
+	c.Put("b", c.Generation(), 2)
+	value, ok := c.Get("a")
+	require.True(t, ok)
+	require.Equal(t, 1, value)
+
+	// The least recently used value is removed
+	c.Put("c", c.Generation(), 3)
+	_, ok = c.Get("b")
+	require.False(t, ok)
+	require.Equal(t, 2, c.Len())
+
+	// Values loaded before Clear are not stored
+	generation := c.Generation()
+	c.Clear()
+	c.Put("a", generation, 1)
+	_, ok = c.Get("a")
+	require.False(t, ok)
+	require.Equal(t, 0, c.Len())
+
+	c.Put("a", c.Generation(), 1)
+	now = now.Add(time.Minute)
+	_, ok = c.Get("a")
+	require.False(t, ok)
+	require.Equal(t, 0, c.Len())
+}
+
+func TestDB(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	todo := db.Todo{Id: 1, Title: "title"}
+	model := mock.NewMockDB(ctrl)
+	gomock.InOrder(
+		model.EXPECT().GetAllTodos(gomock.Any()).Return([]db.Todo{todo}, nil),
+		model.EXPECT().DeleteOneTodo(gomock.Any(), int64(1), "actor").Return(nil),
+		model.EXPECT().GetAllTodos(gomock.Any()).Return([]db.Todo{}, nil),
+	)
+	model.EXPECT().GetOneTodoById(gomock.Any(), int64(1)).Times(2).Return(todo, nil)
+	model.EXPECT().GetOneTodoById(gomock.Any(), int64(2)).Times(2).Return(db.Todo{}, errors.New("not found"))
+	model.EXPECT().
+		WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(db.DB) error) error {
+			return fn(model)
+		})
+
+	m := metrics.New()
+	queries := DB(model, 10, time.Minute, m)
+	ctx := context.Background()
+
+	todos, err := queries.GetAllTodos(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []db.Todo{todo}, todos)
+
+	// Cached Todos cannot be changed by callers
+	todos[0].Title = "changed"
+	todos, err = queries.GetAllTodos(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []db.Todo{todo}, todos)
+
+	// Every change clears the cache
+	require.NoError(t, queries.DeleteOneTodo(ctx, 1, "actor"))
+	todos, err = queries.GetAllTodos(ctx)
+	require.NoError(t, err)
+	require.Empty(t, todos)
+
+	for i := 0; i < 2; i++ {
+		res, err := queries.GetOneTodoById(ctx, 1)
+		require.NoError(t, err)
+		require.Equal(t, todo, res)
+
+		// Errors are not cached
+		_, err = queries.GetOneTodoById(ctx, 2)
+		require.EqualError(t, err, "not found")
+	}
+
+	// Reads within transactions lock Todos, so they reach the database
+	err = queries.WithTx(ctx, func(tx db.DB) error {
+		_, err := tx.GetOneTodoById(ctx, 1)
+		return err
+	})
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	m.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Contains(t, recorder.Body.String(), `todos_cache_lookups_total{query="GetAllTodos",result="hit"} 1`)
+	require.Contains(t, recorder.Body.String(), `todos_cache_lookups_total{query="GetAllTodos",result="miss"} 2`)
+	require.Contains(t, recorder.Body.String(), `todos_cache_lookups_total{query="GetOneTodoById",result="hit"} 1`)
+	require.Contains(t, recorder.Body.String(), `todos_cache_lookups_total{query="GetOneTodoById",result="miss"} 3`)
+}
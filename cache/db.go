@@ -0,0 +1,126 @@
+package cache
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/metrics"
+)
+
+// Decorator of db.DB caching listings and single Todos.
+//
+// Every change made with it clears the whole cache, after the change or its
+// transaction finishes. Changes made by other instances of the application
+// are seen once cached results expire.
+//
+// Queries within transactions are never cached, as they lock Todos they read.
+// Listings of periods are not cached either, as their ranges start at current time.
+type cachedDB struct {
+	db.DB
+	cache   *LRU[any]
+	metrics *metrics.Metrics
+}
+
+// Returns queries caching at most size results for ttl.
+// Lookups are recorded in m, unless it is nil.
+func DB(queries db.DB, size int, ttl time.Duration, m *metrics.Metrics) db.DB {
+	return &cachedDB{DB: queries, cache: NewLRU[any](size, ttl), metrics: m}
+}
+
+// Returns cached result of query under key, or result of load that is cached
+// when it succeeds.
+func (q *cachedDB) cached(query, key string, load func() (any, error)) (any, error) {
+	value, ok := q.cache.Get(key)
+	if q.metrics != nil {
+		q.metrics.ObserveCache(query, ok)
+	}
+	if ok {
+		return value, nil
+	}
+
+	generation := q.cache.Generation()
+	value, err := load()
+	if err != nil {
+		return nil, err
+	}
+	q.cache.Put(key, generation, value)
+	return value, nil
+}
+
+// Returns cached Todos of listing query, copied so callers cannot change cached ones.
+func (q *cachedDB) todos(query string, load func() ([]db.Todo, error)) ([]db.Todo, error) {
+	value, err := q.cached(query, query, func() (any, error) {
+		todos, err := load()
+		return append([]db.Todo{}, todos...), err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return append([]db.Todo{}, value.([]db.Todo)...), nil
+}
+
+func (q *cachedDB) GetAllTodos(ctx context.Context) ([]db.Todo, error) {
+	return q.todos("GetAllTodos", func() ([]db.Todo, error) {
+		return q.DB.GetAllTodos(ctx)
+	})
+}
+
+func (q *cachedDB) GetArchivedTodos(ctx context.Context) ([]db.Todo, error) {
+	return q.todos("GetArchivedTodos", func() ([]db.Todo, error) {
+		return q.DB.GetArchivedTodos(ctx)
+	})
+}
+
+func (q *cachedDB) GetOneTodoById(ctx context.Context, id int64) (db.Todo, error) {
+	key := "GetOneTodoById:" + strconv.FormatInt(id, 10)
+	value, err := q.cached("GetOneTodoById", key, func() (any, error) {
+		return q.DB.GetOneTodoById(ctx, id)
+	})
+	if err != nil {
+		return db.Todo{}, err
+	}
+	return value.(db.Todo), nil
+}
+
+func (q *cachedDB) CreateOneTodo(ctx context.Context, params db.CreateTodoParams, actor string) (db.Todo, error) {
+	defer q.cache.Clear()
+	return q.DB.CreateOneTodo(ctx, params, actor)
+}
+
+func (q *cachedDB) UpdateOneTodo(ctx context.Context, todo db.Todo, actor string) (db.Todo, error) {
+	defer q.cache.Clear()
+	return q.DB.UpdateOneTodo(ctx, todo, actor)
+}
+
+func (q *cachedDB) DeleteOneTodo(ctx context.Context, id int64, actor string) error {
+	defer q.cache.Clear()
+	return q.DB.DeleteOneTodo(ctx, id, actor)
+}
+
+func (q *cachedDB) RestoreOneTodo(ctx context.Context, id int64, actor string) (db.Todo, error) {
+	defer q.cache.Clear()
+	return q.DB.RestoreOneTodo(ctx, id, actor)
+}
+
+func (q *cachedDB) PurgeOneTodo(ctx context.Context, id int64, actor string) error {
+	defer q.cache.Clear()
+	return q.DB.PurgeOneTodo(ctx, id, actor)
+}
+
+func (q *cachedDB) PurgeTrash(ctx context.Context, before time.Time, actor string) (int64, error) {
+	defer q.cache.Clear()
+	return q.DB.PurgeTrash(ctx, before, actor)
+}
+
+func (q *cachedDB) ArchiveDoneTodos(ctx context.Context, doneBefore time.Time, actor string) (int64, error) {
+	defer q.cache.Clear()
+	return q.DB.ArchiveDoneTodos(ctx, doneBefore, actor)
+}
+
+// Runs fn with uncached queries of the transaction and clears cache when it finishes.
+func (q *cachedDB) WithTx(ctx context.Context, fn func(db.DB) error) error {
+	defer q.cache.Clear()
+	return q.DB.WithTx(ctx, fn)
+}
@@ -0,0 +1,264 @@
+// Package certs configures TLS of servers with certificates reloaded
+// when their files change and authenticates clients by their certificates.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Modes of client certificate verification.
+const (
+	ClientAuthOptional = "optional"
+	ClientAuthRequire  = "require"
+)
+
+// Settings of TLS.
+type Options struct {
+	// PEM files of certificate chain and private key of the server
+	CertFile string
+	KeyFile  string
+
+	// PEM file of CAs verifying client certificates, empty disables mutual TLS
+	ClientCAFile string
+
+	// ClientAuthOptional verifies certificates sent by clients,
+	// ClientAuthRequire also rejects clients without them
+	ClientAuth string
+
+	// Minimum TLS version, "1.2" or "1.3"
+	MinVersion string
+
+	// Names of TLS 1.2 cipher suites, empty uses Go's defaults
+	CipherSuites []string
+}
+
+// Returns TLS configuration of servers with certificate served by returned Reloader.
+//
+// Throws an error when any file cannot be loaded or setting is invalid.
+func NewConfig(opts Options) (*tls.Config, *Reloader, error) {
+	minVersion, err := ParseVersion(opts.MinVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+	cipherSuites, err := ParseCipherSuites(opts.CipherSuites)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reloader, err := NewReloader(opts.CertFile, opts.KeyFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	config := &tls.Config{
+		GetCertificate: reloader.GetCertificate,
+		MinVersion:     minVersion,
+		CipherSuites:   cipherSuites,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+
+	if opts.ClientCAFile != "" {
+		data, err := os.ReadFile(opts.ClientCAFile)
+		if err != nil {
+			return nil, nil, fmt.Errorf("cannot read client CA file: %w", err)
+		}
+		config.ClientCAs = x509.NewCertPool()
+		if !config.ClientCAs.AppendCertsFromPEM(data) {
+			return nil, nil, fmt.Errorf("no certificates found in client CA file %v", opts.ClientCAFile)
+		}
+		switch opts.ClientAuth {
+		case ClientAuthOptional:
+			config.ClientAuth = tls.VerifyClientCertIfGiven
+		case ClientAuthRequire:
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		default:
+			return nil, nil, fmt.Errorf("unknown client auth %q", opts.ClientAuth)
+		}
+	}
+	return config, reloader, nil
+}
+
+// Returns TLS version of its number, "1.2" or "1.3".
+//
+// Throws an error for other versions, older ones are insecure.
+func ParseVersion(s string) (uint16, error) {
+	switch s {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unsupported TLS version %q, must be 1.2 or 1.3", s)
+}
+
+// Returns IDs of cipher suites with given names, e.g. "TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256".
+// TLS 1.3 suites cannot be configured, so they are rejected.
+//
+// Throws an error for unknown and insecure suites.
+func ParseCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	secure := map[string]*tls.CipherSuite{}
+	for _, suite := range tls.CipherSuites() {
+		secure[suite.Name] = suite
+	}
+
+	var ids []uint16
+	for _, name := range names {
+		suite, ok := secure[name]
+		if !ok || !supportsTLS12(suite) {
+			return nil, fmt.Errorf("unknown or insecure TLS 1.2 cipher suite %q", name)
+		}
+		ids = append(ids, suite.ID)
+	}
+	return ids, nil
+}
+
+// Reports whether cipher suite can be used with TLS 1.2.
+func supportsTLS12(suite *tls.CipherSuite) bool {
+	for _, version := range suite.SupportedVersions {
+		if version == tls.VersionTLS12 {
+			return true
+		}
+	}
+	return false
+}
+
+// Serves certificate of the server and reloads it when its files change,
+// so renewed certificates are used without restart.
+type Reloader struct {
+	certFile string
+	keyFile  string
+
+	mu   sync.RWMutex
+	cert *tls.Certificate
+	// Latest modification time of both files when certificate was loaded
+	modTime time.Time
+}
+
+// Returns Reloader of certificate loaded from PEM files.
+//
+// Throws an error when certificate cannot be loaded.
+func NewReloader(certFile, keyFile string) (*Reloader, error) {
+	r := &Reloader{certFile: certFile, keyFile: keyFile}
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Returns current certificate, it is the GetCertificate callback of tls.Config.
+func (r *Reloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.cert, nil
+}
+
+// Loads certificate again when any of its files was modified since the last load
+// and reports whether it did.
+//
+// Throws an error when files cannot be loaded, current certificate is kept then.
+func (r *Reloader) Reload() (bool, error) {
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	changed := r.cert == nil || !modTime.Equal(r.modTime)
+	r.mu.RUnlock()
+	if !changed {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return false, fmt.Errorf("cannot load certificate: %w", err)
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.modTime = modTime
+	r.mu.Unlock()
+	return true, nil
+}
+
+// Returns the latest modification time of files.
+//
+// Throws an error when any of them cannot be accessed.
+func latestModTime(paths ...string) (time.Time, error) {
+	var latest time.Time
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return latest, fmt.Errorf("cannot load certificate: %w", err)
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// Checks files for changes every interval until ctx is done.
+//
+// Reloads and failures are logged, failed reloads are retried with the next check.
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration, logger *slog.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		reloaded, err := r.Reload()
+		if err != nil {
+			logger.Error("cannot reload TLS certificate, keeping the current one", "error", err)
+		} else if reloaded {
+			logger.Info("reloaded TLS certificate", "file", r.certFile)
+		}
+	}
+}
+
+// Users of clients, by common names of their certificates.
+type Users map[string]string
+
+// Returns Users of pairs in format "common_name=user".
+//
+// Throws an error when pair is malformed or common name repeats.
+func ParseUsers(pairs []string) (Users, error) {
+	users := Users{}
+	for _, pair := range pairs {
+		name, user, ok := strings.Cut(pair, "=")
+		name, user = strings.TrimSpace(name), strings.TrimSpace(user)
+		if !ok || name == "" || user == "" {
+			return nil, fmt.Errorf("invalid client user %q, must be common_name=user", pair)
+		}
+		if _, ok := users[name]; ok {
+			return nil, fmt.Errorf("common name %q is mapped to more than one user", name)
+		}
+		users[name] = user
+	}
+	return users, nil
+}
+
+// Returns user of client certificate verified on connection with given state,
+// empty when there is none or its common name is not mapped to a user.
+func (u Users) Lookup(state *tls.ConnectionState) string {
+	if state == nil || len(state.VerifiedChains) == 0 || len(state.VerifiedChains[0]) == 0 {
+		return ""
+	}
+	return u[state.VerifiedChains[0][0].Subject.CommonName]
+}
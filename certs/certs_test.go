@@ -0,0 +1,219 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slog"
+)
+
+// Certificate with its private key.
+type keyPair struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	der  []byte
+}
+
+// Returns certificate with given common name signed by parent, self-signed when parent is nil.
+func issue(t *testing.T, name string, parent *keyPair) *keyPair {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+
+	signer, signerKey := template, key
+	if parent != nil {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	return &keyPair{cert: cert, key: key, der: der}
+}
+
+// Writes certificate and key of pair as PEM files in dir and returns their paths.
+func (p *keyPair) write(t *testing.T, dir string) (certFile, keyFile string) {
+	key, err := x509.MarshalECPrivateKey(p.key)
+	require.NoError(t, err)
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: p.der}), 0o600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: key}), 0o600))
+	return certFile, keyFile
+}
+
+// Returns TLS certificate of pair used by clients.
+func (p *keyPair) tls() tls.Certificate {
+	return tls.Certificate{Certificate: [][]byte{p.der}, PrivateKey: p.key}
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	first := issue(t, "first", nil)
+	certFile, keyFile := first.write(t, dir)
+
+	reloader, err := NewReloader(certFile, keyFile)
+	require.NoError(t, err)
+	cert, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, first.der, cert.Certificate[0])
+
+	reloaded, err := reloader.Reload()
+	require.NoError(t, err)
+	require.False(t, reloaded)
+
+	// Files are replaced, e.g. by certificate renewal
+	second := issue(t, "second", nil)
+	second.write(t, dir)
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reloader.Watch(ctx, 10*time.Millisecond, slog.Default())
+	require.Eventually(t, func() bool {
+		cert, _ := reloader.GetCertificate(nil)
+		return string(cert.Certificate[0]) == string(second.der)
+	}, time.Second, 10*time.Millisecond)
+
+	// Broken files do not replace working certificate
+	require.NoError(t, os.WriteFile(keyFile, []byte("broken"), 0o600))
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+	_, err = reloader.Reload()
+	require.Error(t, err)
+	cert, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, second.der, cert.Certificate[0])
+
+	_, err = NewReloader(filepath.Join(dir, "missing.pem"), keyFile)
+	require.Error(t, err)
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := issue(t, "ca", nil)
+	certFile, keyFile := issue(t, "server", ca).write(t, dir)
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.der}), 0o600))
+
+	config, _, err := NewConfig(Options{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: caFile,
+		ClientAuth:   ClientAuthOptional,
+		MinVersion:   "1.2",
+	})
+	require.NoError(t, err)
+
+	users := Users{"billing": "billing-service"}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(users.Lookup(r.TLS)))
+	}))
+	server.Listener = tls.NewListener(server.Listener, config)
+	server.Start()
+	defer server.Close()
+	url := "https://" + server.Listener.Addr().String()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	get := func(certs ...tls.Certificate) (string, error) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			Certificates: certs,
+		}}}
+		response, err := client.Get(url)
+		if err != nil {
+			return "", err
+		}
+		defer response.Body.Close()
+		body := make([]byte, 64)
+		n, _ := response.Body.Read(body)
+		return string(body[:n]), nil
+	}
+
+	user, err := get(issue(t, "billing", ca).tls())
+	require.NoError(t, err)
+	require.Equal(t, "billing-service", user)
+
+	// Unknown common names and missing certificates are anonymous
+	user, err = get(issue(t, "reports", ca).tls())
+	require.NoError(t, err)
+	require.Empty(t, user)
+	user, err = get()
+	require.NoError(t, err)
+	require.Empty(t, user)
+
+	// Certificates of other CAs are not accepted, so clients do not send them
+	user, err = get(issue(t, "billing", nil).tls())
+	require.NoError(t, err)
+	require.Empty(t, user)
+
+	config, _, err = NewConfig(Options{
+		CertFile:     certFile,
+		KeyFile:      keyFile,
+		ClientCAFile: caFile,
+		ClientAuth:   ClientAuthRequire,
+		MinVersion:   "1.3",
+	})
+	require.NoError(t, err)
+	require.Equal(t, tls.RequireAndVerifyClientCert, config.ClientAuth)
+	require.Equal(t, uint16(tls.VersionTLS13), config.MinVersion)
+
+	_, _, err = NewConfig(Options{CertFile: certFile, KeyFile: keyFile, ClientCAFile: keyFile, ClientAuth: ClientAuthRequire, MinVersion: "1.2"})
+	require.Error(t, err)
+}
+
+func TestParse(t *testing.T) {
+	version, err := ParseVersion("1.3")
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS13), version)
+	_, err = ParseVersion("1.0")
+	require.Error(t, err)
+
+	suites, err := ParseCipherSuites([]string{"TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256"})
+	require.NoError(t, err)
+	require.Equal(t, []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}, suites)
+	_, err = ParseCipherSuites([]string{"TLS_RSA_WITH_RC4_128_SHA"})
+	require.Error(t, err)
+	_, err = ParseCipherSuites([]string{"TLS_AES_128_GCM_SHA256"})
+	require.Error(t, err)
+
+	users, err := ParseUsers([]string{"billing=billing-service", " reports = reports "})
+	require.NoError(t, err)
+	require.Equal(t, Users{"billing": "billing-service", "reports": "reports"}, users)
+	_, err = ParseUsers([]string{"billing"})
+	require.Error(t, err)
+	_, err = ParseUsers([]string{"billing=a", "billing=b"})
+	require.Error(t, err)
+}
@@ -0,0 +1,33 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/vilderxyz/todos/api"
+	"github.com/vilderxyz/todos/db"
+)
+
+// Archives Todo with given Id.
+func (c *Client) ArchiveTodo(ctx context.Context, id int64) (db.Todo, error) {
+	var todo db.Todo
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/todos/%d/archive", id), nil, nil, &todo)
+	return todo, err
+}
+
+// Moves Todo with given Id out of archive.
+func (c *Client) UnarchiveTodo(ctx context.Context, id int64) (db.Todo, error) {
+	var todo db.Todo
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/todos/%d/unarchive", id), nil, nil, &todo)
+	return todo, err
+}
+
+// Archives all Todos finished before given date and returns how many were archived.
+func (c *Client) ArchiveDoneTodos(ctx context.Context, req api.ArchiveDoneTodosRequest) (int64, error) {
+	var res struct {
+		Archived int64 `json:"archived"`
+	}
+	err := c.do(ctx, http.MethodPost, "/todos/archive", nil, req, &res)
+	return res.Archived, err
+}
@@ -0,0 +1,18 @@
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/vilderxyz/todos/api"
+)
+
+// Executes list of operations and returns results of every one of them.
+//
+// When atomic batch fails, results are returned together with *Error
+// of the failed operation.
+func (c *Client) Batch(ctx context.Context, req api.BatchRequest) ([]api.BatchResult, error) {
+	var results []api.BatchResult
+	err := c.do(ctx, http.MethodPost, "/todos/batch", nil, req, &results)
+	return results, err
+}
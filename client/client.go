@@ -0,0 +1,256 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	mathrand "math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// Header that carries client's API key
+	apiKeyHeader = "X-API-Key"
+
+	// Header that makes retried mutating requests safe
+	idempotencyKeyHeader = "Idempotency-Key"
+)
+
+// Client of Todos http API.
+//
+// Methods mirror routes of the API server and return data sent in responses.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+	retry      RetryPolicy
+}
+
+// Policy of retrying failed requests.
+//
+// Requests are retried after network errors and responses with 429, 502, 503
+// and 504 statuses, waiting exponentially longer between attempts, but not
+// shorter than Retry-After header asks for. Requests that modify todos are
+// sent with Idempotency-Key header, so the server applies them only once.
+type RetryPolicy struct {
+	// Maximum number of attempts including the first one, 1 disables retries
+	MaxAttempts int
+
+	// Backoff before the first retry, doubled for every next one
+	MinBackoff time.Duration
+
+	// Maximum backoff, requests that should wait longer are not retried
+	MaxBackoff time.Duration
+}
+
+// Policy used by clients created without WithRetry option.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts: 3,
+	MinBackoff:  100 * time.Millisecond,
+	MaxBackoff:  5 * time.Second,
+}
+
+// Option that configures Client.
+type Option func(*Client)
+
+// Sends given API key with every request.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.apiKey = key
+	}
+}
+
+// Uses given http client to send requests.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// Retries failed requests following given policy.
+func WithRetry(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// Returns Client of API served at baseURL, e.g. "http://localhost:8090".
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		retry:      DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Body of API responses.
+type envelope struct {
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Error   string          `json:"error"`
+
+	// Errors of GraphQL queries
+	Errors GraphQLErrors `json:"errors"`
+}
+
+// Sends request with body encoded as JSON and decodes data of the response into data,
+// retrying it following client's policy.
+//
+// Body and data can be nil. Data is decoded for error responses too when they carry any.
+// Throws *Error for responses with error status.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, data any) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	header := http.Header{}
+	if body != nil {
+		header.Set("Content-Type", "application/json")
+	}
+	if c.apiKey != "" {
+		header.Set(apiKeyHeader, c.apiKey)
+	}
+	if method != http.MethodGet && c.retry.MaxAttempts > 1 {
+		key, err := newIdempotencyKey()
+		if err != nil {
+			return err
+		}
+		header.Set(idempotencyKeyHeader, key)
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := c.send(ctx, method, target, header, payload, data)
+		wait, retry := c.backoff(attempt, err)
+		if !retry {
+			return err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// Sends single request and decodes its response.
+func (c *Client) send(ctx context.Context, method, target string, header http.Header, payload []byte, data any) error {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return err
+	}
+	req.Header = header.Clone()
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	var env envelope
+	decodeErr := json.NewDecoder(res.Body).Decode(&env)
+	if decodeErr == io.EOF {
+		decodeErr = nil
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		apiErr := &Error{StatusCode: res.StatusCode, Message: env.Error}
+		if apiErr.Message == "" && len(env.Errors) > 0 {
+			apiErr.Message = env.Errors[0].Message
+		}
+		if seconds, err := strconv.Atoi(res.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(seconds) * time.Second
+		}
+		if data != nil && len(env.Data) > 0 {
+			json.Unmarshal(env.Data, data)
+		}
+		return apiErr
+	}
+
+	if decodeErr != nil {
+		return fmt.Errorf("cannot decode response: %w", decodeErr)
+	}
+	if data != nil && len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, data); err != nil {
+			return fmt.Errorf("cannot decode response data: %w", err)
+		}
+	}
+	if len(env.Errors) > 0 {
+		return env.Errors
+	}
+	return nil
+}
+
+// Returns how long to wait before retrying request that failed with err
+// and false when it should not be retried.
+func (c *Client) backoff(attempt int, err error) (time.Duration, bool) {
+	if err == nil || attempt >= c.retry.MaxAttempts {
+		return 0, false
+	}
+
+	var retryAfter time.Duration
+	if apiErr, ok := err.(*Error); ok {
+		switch apiErr.StatusCode {
+		case http.StatusTooManyRequests, http.StatusBadGateway,
+			http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+			retryAfter = apiErr.RetryAfter
+		default:
+			return 0, false
+		}
+	} else if _, ok := err.(*url.Error); !ok {
+		// Only network errors are retried, not malformed requests or responses
+		return 0, false
+	}
+
+	wait := c.retry.MinBackoff << (attempt - 1)
+	if wait > c.retry.MaxBackoff || wait <= 0 {
+		wait = c.retry.MaxBackoff
+	}
+	// Jitter spreads retries of many clients
+	wait = wait/2 + time.Duration(mathrand.Int63n(int64(wait/2)+1))
+
+	if retryAfter > wait {
+		wait = retryAfter
+	}
+	if wait > c.retry.MaxBackoff {
+		return 0, false
+	}
+	return wait, true
+}
+
+// Returns random key identifying single logical request across its retries.
+func newIdempotencyKey() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
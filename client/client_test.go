@@ -0,0 +1,503 @@
+package client
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/api"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/mock"
+)
+
+var todo = db.Todo{
+	Id:          123,
+	Title:       "Clean house",
+	Description: "Kitchen",
+	Expiry:      time.Date(2222, 5, 22, 0, 0, 0, 0, time.UTC),
+	Status:      "backlog",
+}
+
+// Returns Client of api.Server using given mock and served by httptest.Server.
+func newTestClient(t *testing.T, model *mock.MockDB, opts ...Option) *Client {
+	server := api.NewServer(nil)
+	server.Queries = model
+	return serve(t, server.Router, opts...)
+}
+
+// Returns Client of given handler served by httptest.Server.
+func serve(t *testing.T, handler http.Handler, opts ...Option) *Client {
+	httpServer := httptest.NewServer(handler)
+	t.Cleanup(httpServer.Close)
+	return New(httpServer.URL, opts...)
+}
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+func TestTodos(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	model := mock.NewMockDB(ctrl)
+	client := newTestClient(t, model)
+
+	model.EXPECT().
+		CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, params db.CreateTodoParams, actor string) (db.Todo, error) {
+			require.Equal(t, todo.Title, params.Title)
+			require.Equal(t, todo.Expiry, params.Expiry)
+			return todo, nil
+		})
+	created, err := client.CreateTodo(ctx, api.CreateTodoRequest{
+		Title:       todo.Title,
+		Description: todo.Description,
+		Expiry:      "2222-05-22",
+	})
+	require.NoError(t, err)
+	require.Equal(t, todo, created)
+
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		Times(1).
+		Return(todo, nil)
+	recieved, err := client.GetTodo(ctx, todo.Id)
+	require.NoError(t, err)
+	require.Equal(t, todo, recieved)
+
+	model.EXPECT().
+		GetManyTodos(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return([]db.Todo{todo}, nil)
+	todos, err := client.ListTodos(ctx, ListOptions{Period: "week"})
+	require.NoError(t, err)
+	require.Equal(t, []db.Todo{todo}, todos)
+
+	model.EXPECT().
+		GetArchivedTodos(gomock.Any()).
+		Times(1).
+		Return(nil, nil)
+	todos, err = client.ListTodos(ctx, ListOptions{Archived: true})
+	require.NoError(t, err)
+	require.Empty(t, todos)
+
+	model.EXPECT().
+		DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+		Times(1).
+		Return(nil)
+	require.NoError(t, client.DeleteTodo(ctx, todo.Id))
+}
+
+func TestUpdates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	model := mock.NewMockDB(ctrl)
+	client := newTestClient(t, model)
+
+	model.EXPECT().
+		WithTx(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ context.Context, fn func(db.DB) error) error {
+			return fn(model)
+		})
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		AnyTimes().
+		Return(todo, nil)
+	model.EXPECT().
+		UpdateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ context.Context, todo db.Todo, actor string) (db.Todo, error) {
+			return todo, nil
+		})
+
+	updated, err := client.UpdateTodo(ctx, api.UpdateTodoInfoRequest{
+		Id:          todo.Id,
+		Title:       "Wash car",
+		Description: todo.Description,
+		Expiry:      "2222-05-22",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "Wash car", updated.Title)
+
+	updated, err = client.UpdateCompletion(ctx, api.UpdateTodoCompletionRequest{Id: todo.Id, Completion: 60})
+	require.NoError(t, err)
+	require.Equal(t, float32(60), updated.Completion)
+
+	updated, err = client.SetDone(ctx, todo.Id, true)
+	require.NoError(t, err)
+	require.True(t, updated.IsDone)
+
+	_, err = client.SetDone(ctx, todo.Id, false)
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+	require.Equal(t, "todo is not done", apiErr.Message)
+}
+
+func TestErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		GetUserByKeyHash(gomock.Any(), gomock.Eq(api.HashAPIKey("secret"))).
+		Times(2).
+		Return(db.User{Name: "alice"}, nil)
+	client := newTestClient(t, model, WithAPIKey("secret"))
+
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		Times(1).
+		Return(db.Todo{}, fmt.Errorf("not found"))
+	_, err := client.GetTodo(ctx, todo.Id)
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, http.StatusNotFound, apiErr.StatusCode)
+
+	model.EXPECT().
+		GetAllTodos(gomock.Any()).
+		Times(1).
+		Return(nil, sql.ErrConnDone)
+	_, err = client.ListTodos(ctx, ListOptions{})
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+}
+
+func TestTrash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	model := mock.NewMockDB(ctrl)
+	client := newTestClient(t, model)
+
+	model.EXPECT().
+		GetTrashedTodos(gomock.Any()).
+		Times(1).
+		Return([]db.Todo{todo}, nil)
+	todos, err := client.ListTrash(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []db.Todo{todo}, todos)
+
+	model.EXPECT().
+		RestoreOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+		Times(1).
+		Return(todo, nil)
+	restored, err := client.RestoreTodo(ctx, todo.Id)
+	require.NoError(t, err)
+	require.Equal(t, todo, restored)
+
+	model.EXPECT().
+		PurgeOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+		Times(1).
+		Return(fmt.Errorf("not found"))
+	err = client.PurgeTodo(ctx, todo.Id)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	model.EXPECT().
+		PurgeTrash(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, before time.Time, _ string) (int64, error) {
+			require.WithinDuration(t, time.Now().Add(-time.Hour), before, time.Minute)
+			return 3, nil
+		})
+	purged, err := client.PurgeTrash(ctx, time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), purged)
+}
+
+func TestStatusAndHistory(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	model := mock.NewMockDB(ctrl)
+	client := newTestClient(t, model)
+
+	model.EXPECT().
+		WithTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, fn func(db.DB) error) error {
+			return fn(model)
+		})
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		Times(1).
+		Return(todo, nil)
+	model.EXPECT().
+		UpdateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, todo db.Todo, actor string) (db.Todo, error) {
+			return todo, nil
+		})
+	updated, err := client.UpdateStatus(ctx, api.UpdateTodoStatusRequest{Id: todo.Id, Status: "in_progress"})
+	require.NoError(t, err)
+	require.Equal(t, "in_progress", updated.Status)
+
+	transitions := []db.Transition{{Id: 1, TodoId: todo.Id, From: "", To: "backlog", Actor: "alice"}}
+	model.EXPECT().
+		GetTodoTransitions(gomock.Any(), gomock.Eq(todo.Id)).
+		Times(1).
+		Return(transitions, nil)
+	recievedTransitions, err := client.GetTodoTransitions(ctx, todo.Id)
+	require.NoError(t, err)
+	require.Equal(t, transitions, recievedTransitions)
+
+	entries := []db.History{{Id: 1, TodoId: todo.Id, Actor: "alice", Operation: db.OpCreate}}
+	model.EXPECT().
+		GetTodoHistory(gomock.Any(), gomock.Eq(todo.Id), gomock.Eq(10), gomock.Eq(10)).
+		Times(1).
+		Return(entries, int64(11), nil)
+	history, err := client.GetTodoHistory(ctx, todo.Id, 2, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, history.Page)
+	require.Equal(t, int64(11), history.Total)
+	require.Len(t, history.Entries, 1)
+}
+
+func TestArchiveAndBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	model := mock.NewMockDB(ctrl)
+	client := newTestClient(t, model)
+
+	model.EXPECT().
+		WithTx(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ context.Context, fn func(db.DB) error) error {
+			return fn(model)
+		})
+
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		Times(2).
+		Return(todo, nil)
+	model.EXPECT().
+		UpdateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, todo db.Todo, actor string) (db.Todo, error) {
+			return todo, nil
+		})
+	archived, err := client.ArchiveTodo(ctx, todo.Id)
+	require.NoError(t, err)
+	require.NotNil(t, archived.ArchivedAt)
+
+	_, err = client.UnarchiveTodo(ctx, todo.Id)
+	require.ErrorIs(t, err, ErrBadRequest)
+
+	model.EXPECT().
+		ArchiveDoneTodos(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		Return(int64(2), nil)
+	count, err := client.ArchiveDoneTodos(ctx, api.ArchiveDoneTodosRequest{DoneBefore: "2022-12-23"})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), count)
+
+	model.EXPECT().
+		DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+		Times(1).
+		Return(nil)
+	model.EXPECT().
+		DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id+1), gomock.Any()).
+		Times(1).
+		Return(fmt.Errorf("not found"))
+	results, err := client.Batch(ctx, api.BatchRequest{
+		Mode: api.BatchAtomic,
+		Operations: []api.BatchOperation{
+			{Op: "delete", Id: todo.Id},
+			{Op: "delete", Id: todo.Id + 1},
+		},
+	})
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Len(t, results, 2)
+	require.Equal(t, http.StatusFailedDependency, results[0].Status)
+	require.Equal(t, http.StatusNotFound, results[1].Status)
+}
+
+func TestGraphQL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	model := mock.NewMockDB(ctrl)
+	client := newTestClient(t, model)
+
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		Times(1).
+		Return(todo, nil)
+	var data struct {
+		Todo struct {
+			Title  string `json:"title"`
+			Status string `json:"status"`
+		} `json:"todo"`
+	}
+	err := client.GraphQL(ctx, `query($id: ID!) { todo(id: $id) { title status } }`, map[string]any{"id": "123"}, &data)
+	require.NoError(t, err)
+	require.Equal(t, todo.Title, data.Todo.Title)
+	require.Equal(t, "backlog", data.Todo.Status)
+
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		Times(1).
+		Return(db.Todo{}, fmt.Errorf("not found"))
+	err = client.GraphQL(ctx, `{ todo(id: "123") { title } }`, nil, &data)
+	var gqlErrs GraphQLErrors
+	require.ErrorAs(t, err, &gqlErrs)
+	require.Equal(t, "not found", gqlErrs[0].Message)
+	require.Equal(t, []any{"todo"}, gqlErrs[0].Path)
+
+	err = client.GraphQL(ctx, `{ todo { unknown } }`, nil, &data)
+	require.ErrorIs(t, err, ErrBadRequest)
+	require.Contains(t, err.Error(), "unknown")
+}
+
+func TestEveryRoute(t *testing.T) {
+	var requests []string
+	client := serve(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Write([]byte("{}"))
+	}))
+	ctx := context.Background()
+
+	client.CreateTodo(ctx, api.CreateTodoRequest{})
+	client.GetTodo(ctx, 1)
+	client.ListTodos(ctx, ListOptions{})
+	client.UpdateTodo(ctx, api.UpdateTodoInfoRequest{})
+	client.UpdateCompletion(ctx, api.UpdateTodoCompletionRequest{})
+	client.SetDone(ctx, 1, true)
+	client.DeleteTodo(ctx, 1)
+	client.GetTodoHistory(ctx, 1, 0, 0)
+	client.UpdateStatus(ctx, api.UpdateTodoStatusRequest{})
+	client.GetTodoTransitions(ctx, 1)
+	client.ListTrash(ctx)
+	client.RestoreTodo(ctx, 1)
+	client.PurgeTodo(ctx, 1)
+	client.PurgeTrash(ctx, 0)
+	client.ArchiveTodo(ctx, 1)
+	client.UnarchiveTodo(ctx, 1)
+	client.ArchiveDoneTodos(ctx, api.ArchiveDoneTodosRequest{})
+	client.Batch(ctx, api.BatchRequest{})
+	client.GraphQL(ctx, "{ todos { id } }", nil, nil)
+
+	for _, route := range api.NewServer(nil).Router.Routes() {
+		// Documentation, health probes and metrics are not part of the API
+		switch route.Path {
+		case "/openapi.json", "/docs", "/docs/swagger-ui/*filepath", "/healthz", "/readyz", "/metrics":
+			continue
+		}
+		// Client sends GraphQL queries with POST
+		if route.Method == http.MethodGet && route.Path == "/graphql" {
+			continue
+		}
+		pattern := regexp.MustCompile(`:[^/]+`).ReplaceAllString(route.Path, `[^/]+`)
+		matcher := regexp.MustCompile("^" + route.Method + " " + pattern + "$")
+
+		covered := false
+		for _, request := range requests {
+			covered = covered || matcher.MatchString(request)
+		}
+		require.True(t, covered, "no client method for %v %v", route.Method, route.Path)
+	}
+}
+
+func TestRetry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	model := mock.NewMockDB(ctrl)
+	server := api.NewServer(nil)
+	server.Queries = model
+
+	failures := 0
+	var keys []string
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keys = append(keys, r.Header.Get("Idempotency-Key"))
+		if failures > 0 {
+			failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		server.Router.ServeHTTP(w, r)
+	})
+	policy := RetryPolicy{MaxAttempts: 3, MinBackoff: time.Millisecond, MaxBackoff: 10 * time.Millisecond}
+	client := serve(t, handler, WithRetry(policy))
+
+	// Mutating requests are retried with the same idempotency key
+	failures = 2
+	model.EXPECT().
+		DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+		Times(1).
+		Return(nil)
+	require.NoError(t, client.DeleteTodo(ctx, todo.Id))
+	require.Len(t, keys, 3)
+	require.NotEmpty(t, keys[0])
+	require.Equal(t, keys[0], keys[1])
+	require.Equal(t, keys[0], keys[2])
+
+	// Requests fail after the last attempt
+	failures, keys = 3, nil
+	_, err := client.GetTodo(ctx, todo.Id)
+	require.ErrorIs(t, err, ErrServer)
+	require.Len(t, keys, 3)
+	require.Empty(t, keys[0])
+
+	// Client errors are not retried
+	failures, keys = 0, nil
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		Times(1).
+		Return(db.Todo{}, fmt.Errorf("not found"))
+	_, err = client.GetTodo(ctx, todo.Id)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.Len(t, keys, 1)
+
+	// Retries can be disabled
+	client = serve(t, handler, WithRetry(RetryPolicy{MaxAttempts: 1}))
+	failures, keys = 1, nil
+	err = client.DeleteTodo(ctx, todo.Id)
+	require.ErrorIs(t, err, ErrServer)
+	require.Equal(t, []string{""}, keys)
+}
+
+func TestRetryAfter(t *testing.T) {
+	attempts := 0
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+		w.Write([]byte(`{"error":"too many requests"}`))
+	})
+	client := serve(t, handler)
+
+	// Server asks to wait longer than maximum backoff
+	_, err := client.ListTodos(context.Background(), ListOptions{})
+	require.ErrorIs(t, err, ErrRateLimited)
+	require.Equal(t, 1, attempts)
+
+	var apiErr *Error
+	require.True(t, errors.As(err, &apiErr))
+	require.Equal(t, time.Minute, apiErr.RetryAfter)
+	require.Equal(t, "too many requests", apiErr.Message)
+}
@@ -0,0 +1,40 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/vilderxyz/todos/api"
+)
+
+// Error of GraphQL query resolved by the server.
+//
+// Path leads to the field that failed to resolve.
+type GraphQLError struct {
+	Message string `json:"message"`
+	Path    []any  `json:"path,omitempty"`
+}
+
+// Errors returned next to data of partially resolved GraphQL query.
+type GraphQLErrors []GraphQLError
+
+func (e GraphQLErrors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Message
+	}
+	return "todos graphql: " + strings.Join(messages, "; ")
+}
+
+// Runs GraphQL query or mutation with given variables and decodes its data into data.
+//
+// Variables can be nil. Throws GraphQLErrors when some fields failed to resolve,
+// data holds the resolved ones then. Invalid queries and queries exceeding
+// server's limits throw *Error with status 400.
+func (c *Client) GraphQL(ctx context.Context, query string, variables map[string]any, data any) error {
+	return c.do(ctx, http.MethodPost, "/graphql", nil, api.GraphQLRequest{
+		Query:     query,
+		Variables: variables,
+	}, data)
+}
@@ -0,0 +1,28 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/vilderxyz/todos/api"
+)
+
+// Returns page of history entries of Todo with given Id, from the oldest one.
+//
+// Zero page and perPage select server's defaults.
+func (c *Client) GetTodoHistory(ctx context.Context, id int64, page, perPage int) (api.HistoryPage, error) {
+	query := url.Values{}
+	if page > 0 {
+		query.Set("page", strconv.Itoa(page))
+	}
+	if perPage > 0 {
+		query.Set("per_page", strconv.Itoa(perPage))
+	}
+
+	var history api.HistoryPage
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/todos/%d/history", id), query, nil, &history)
+	return history, err
+}
@@ -0,0 +1,24 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/vilderxyz/todos/api"
+	"github.com/vilderxyz/todos/db"
+)
+
+// Moves Todo to given status of server's workflow.
+func (c *Client) UpdateStatus(ctx context.Context, req api.UpdateTodoStatusRequest) (db.Todo, error) {
+	var todo db.Todo
+	err := c.do(ctx, http.MethodPatch, "/todos/status", nil, req, &todo)
+	return todo, err
+}
+
+// Returns all status transitions of Todo with given Id from the oldest one.
+func (c *Client) GetTodoTransitions(ctx context.Context, id int64) ([]db.Transition, error) {
+	var transitions []db.Transition
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/todos/%d/transitions", id), nil, nil, &transitions)
+	return transitions, err
+}
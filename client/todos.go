@@ -0,0 +1,76 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/vilderxyz/todos/api"
+	"github.com/vilderxyz/todos/db"
+)
+
+// Options of ListTodos.
+//
+// Period is one of [ "today" , "tomorrow" , "week" , "" ].
+// Archived lists only archived Todos and cannot be combined with Period.
+type ListOptions struct {
+	Period   string
+	Archived bool
+}
+
+// Creates new Todo.
+func (c *Client) CreateTodo(ctx context.Context, req api.CreateTodoRequest) (db.Todo, error) {
+	var todo db.Todo
+	err := c.do(ctx, http.MethodPost, "/todos", nil, req, &todo)
+	return todo, err
+}
+
+// Returns Todo with given Id.
+func (c *Client) GetTodo(ctx context.Context, id int64) (db.Todo, error) {
+	var todo db.Todo
+	err := c.do(ctx, http.MethodGet, fmt.Sprintf("/todos/%d", id), nil, nil, &todo)
+	return todo, err
+}
+
+// Returns Todos matching given options.
+func (c *Client) ListTodos(ctx context.Context, opts ListOptions) ([]db.Todo, error) {
+	query := url.Values{}
+	if opts.Period != "" {
+		query.Set("period", opts.Period)
+	}
+	if opts.Archived {
+		query.Set("archived", "true")
+	}
+
+	var todos []db.Todo
+	err := c.do(ctx, http.MethodGet, "/todos", query, nil, &todos)
+	return todos, err
+}
+
+// Replaces Todo's title, description and expiry.
+func (c *Client) UpdateTodo(ctx context.Context, req api.UpdateTodoInfoRequest) (db.Todo, error) {
+	var todo db.Todo
+	err := c.do(ctx, http.MethodPatch, "/todos", nil, req, &todo)
+	return todo, err
+}
+
+// Sets Todo's completion progress.
+func (c *Client) UpdateCompletion(ctx context.Context, req api.UpdateTodoCompletionRequest) (db.Todo, error) {
+	var todo db.Todo
+	err := c.do(ctx, http.MethodPatch, "/todos/completion", nil, req, &todo)
+	return todo, err
+}
+
+// Finishes Todo with given Id or reopens it.
+func (c *Client) SetDone(ctx context.Context, id int64, done bool) (db.Todo, error) {
+	var todo db.Todo
+	req := api.UpdateTodoDoneRequest{Id: id, IsDone: &done}
+	err := c.do(ctx, http.MethodPatch, "/todos/done", nil, req, &todo)
+	return todo, err
+}
+
+// Moves Todo with given Id to trash.
+func (c *Client) DeleteTodo(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/todos/%d", id), nil, nil, nil)
+}
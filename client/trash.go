@@ -0,0 +1,42 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/vilderxyz/todos/db"
+)
+
+// Returns all Todos from trash, most recently deleted first.
+func (c *Client) ListTrash(ctx context.Context) ([]db.Todo, error) {
+	var todos []db.Todo
+	err := c.do(ctx, http.MethodGet, "/trash", nil, nil, &todos)
+	return todos, err
+}
+
+// Moves Todo with given Id out of trash.
+func (c *Client) RestoreTodo(ctx context.Context, id int64) (db.Todo, error) {
+	var todo db.Todo
+	err := c.do(ctx, http.MethodPost, fmt.Sprintf("/todos/%d/restore", id), nil, nil, &todo)
+	return todo, err
+}
+
+// Permanently removes Todo with given Id from trash.
+func (c *Client) PurgeTodo(ctx context.Context, id int64) error {
+	return c.do(ctx, http.MethodDelete, fmt.Sprintf("/trash/%d", id), nil, nil, nil)
+}
+
+// Permanently removes Todos moved to trash longer than given time ago
+// and returns how many were removed. Zero olderThan empties the trash.
+func (c *Client) PurgeTrash(ctx context.Context, olderThan time.Duration) (int64, error) {
+	var res struct {
+		Purged int64 `json:"purged"`
+	}
+	query := url.Values{}
+	query.Set("older_than", olderThan.String())
+	err := c.do(ctx, http.MethodDelete, "/trash", query, nil, &res)
+	return res.Purged, err
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/vilderxyz/todos/api"
+	"github.com/vilderxyz/todos/client"
+)
+
+// Layout of dates accepted by the API.
+const dateLayout = "2006-01-02"
+
+// Returns names of all commands in alphabetical order.
+func commandNames() []string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		if commands[name].usage != "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Parses flags of command that may be given before, between or after positional arguments
+// and returns the positional ones.
+func parseArgs(flags *flag.FlagSet, args []string) ([]string, error) {
+	flags.SetOutput(io.Discard)
+	var positional []string
+	for {
+		if err := flags.Parse(args); err != nil {
+			if err == flag.ErrHelp {
+				flags.SetOutput(os.Stderr)
+				flags.PrintDefaults()
+			}
+			return nil, err
+		}
+		if flags.NArg() == 0 {
+			return positional, nil
+		}
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+// Parses Id of todo given as the only positional argument.
+func parseId(args []string) (int64, error) {
+	if len(args) != 1 {
+		return 0, fmt.Errorf("todo id is required")
+	}
+	id, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid todo id %q", args[0])
+	}
+	return id, nil
+}
+
+func runAdd(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("add", flag.ContinueOnError)
+	description := flags.String("d", "", "description, same as title by default")
+	expiry := flags.String("e", time.Now().AddDate(0, 0, 1).Format(dateLayout), "expiry date")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return fmt.Errorf("usage: todo %v", commands["add"].usage)
+	}
+	if *description == "" {
+		*description = args[0]
+	}
+
+	todo, err := a.client.CreateTodo(ctx, api.CreateTodoRequest{
+		Title:       args[0],
+		Description: *description,
+		Expiry:      *expiry,
+	})
+	if err != nil {
+		return err
+	}
+	return a.out.todo(todo)
+}
+
+func runList(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("ls", flag.ContinueOnError)
+	period := flags.String("period", "", "today, tomorrow or week")
+	archived := flags.Bool("archived", false, "list only archived todos")
+	if _, err := parseArgs(flags, args); err != nil {
+		return err
+	}
+
+	todos, err := a.client.ListTodos(ctx, client.ListOptions{
+		Period:   *period,
+		Archived: *archived,
+	})
+	if err != nil {
+		return err
+	}
+	return a.out.todos(todos)
+}
+
+func runShow(ctx context.Context, a *app, args []string) error {
+	id, err := parseId(args)
+	if err != nil {
+		return err
+	}
+
+	todo, err := a.client.GetTodo(ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.out.todo(todo)
+}
+
+func runEdit(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("edit", flag.ContinueOnError)
+	title := flags.String("t", "", "new title")
+	description := flags.String("d", "", "new description")
+	expiry := flags.String("e", "", "new expiry date")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	id, err := parseId(args)
+	if err != nil {
+		return err
+	}
+	if *title == "" && *description == "" && *expiry == "" {
+		return fmt.Errorf("nothing to change, use -t, -d or -e")
+	}
+
+	// API replaces all three fields at once
+	todo, err := a.client.GetTodo(ctx, id)
+	if err != nil {
+		return err
+	}
+	req := api.UpdateTodoInfoRequest{
+		Id:          id,
+		Title:       todo.Title,
+		Description: todo.Description,
+		Expiry:      todo.Expiry.Format(dateLayout),
+	}
+	if *title != "" {
+		req.Title = *title
+	}
+	if *description != "" {
+		req.Description = *description
+	}
+	if *expiry != "" {
+		req.Expiry = *expiry
+	}
+
+	todo, err = a.client.UpdateTodo(ctx, req)
+	if err != nil {
+		return err
+	}
+	return a.out.todo(todo)
+}
+
+func runProgress(ctx context.Context, a *app, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("usage: todo %v", commands["progress"].usage)
+	}
+	id, err := parseId(args[:1])
+	if err != nil {
+		return err
+	}
+	completion, err := strconv.ParseFloat(args[1], 32)
+	if err != nil {
+		return fmt.Errorf("invalid percent %q", args[1])
+	}
+
+	todo, err := a.client.UpdateCompletion(ctx, api.UpdateTodoCompletionRequest{
+		Id:         id,
+		Completion: float32(completion),
+	})
+	if err != nil {
+		return err
+	}
+	return a.out.todo(todo)
+}
+
+func runDone(ctx context.Context, a *app, args []string) error {
+	flags := flag.NewFlagSet("done", flag.ContinueOnError)
+	undo := flags.Bool("undo", false, "reopen finished todo")
+	args, err := parseArgs(flags, args)
+	if err != nil {
+		return err
+	}
+	id, err := parseId(args)
+	if err != nil {
+		return err
+	}
+
+	todo, err := a.client.SetDone(ctx, id, !*undo)
+	if err != nil {
+		return err
+	}
+	return a.out.todo(todo)
+}
+
+func runRemove(ctx context.Context, a *app, args []string) error {
+	id, err := parseId(args)
+	if err != nil {
+		return err
+	}
+
+	if err := a.client.DeleteTodo(ctx, id); err != nil {
+		return err
+	}
+	return a.out.message(fmt.Sprintf("Moved todo %d to trash", id))
+}
+
+func runConfig(ctx context.Context, a *app, args []string) error {
+	switch {
+	case len(args) == 0:
+		cfg := a.cfg
+		if cfg.APIKey != "" {
+			cfg.APIKey = "********"
+		}
+		return a.out.config(a.configPath, cfg)
+
+	case len(args) == 3 && args[0] == "set":
+		// Environment variables are not written to the file
+		cfg, err := readConfig(a.configPath)
+		if err != nil {
+			return err
+		}
+		if err := cfg.set(args[1], args[2]); err != nil {
+			return err
+		}
+		if err := saveConfig(a.configPath, cfg); err != nil {
+			return err
+		}
+		return a.out.message(fmt.Sprintf("Saved %v in %v", args[1], a.configPath))
+	}
+	return fmt.Errorf("usage: todo %v", commands["config"].usage)
+}
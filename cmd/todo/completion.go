@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/vilderxyz/todos/client"
+)
+
+// Bash completion script, %v is replaced with command names.
+const bashCompletion = `# bash completion for todo, load with: source <(todo completion bash)
+_todo() {
+    local cur prev cmd
+    cur="${COMP_WORDS[COMP_CWORD]}"
+    prev="${COMP_WORDS[COMP_CWORD-1]}"
+    cmd="${COMP_WORDS[1]}"
+
+    if [[ ${COMP_CWORD} -eq 1 ]]; then
+        COMPREPLY=($(compgen -W "%v" -- "${cur}"))
+        return
+    fi
+
+    case "${prev}" in
+        --period) COMPREPLY=($(compgen -W "today tomorrow week" -- "${cur}")); return ;;
+        -o) COMPREPLY=($(compgen -W "table json" -- "${cur}")); return ;;
+    esac
+
+    case "${cmd}" in
+        ls) COMPREPLY=($(compgen -W "--period --archived" -- "${cur}")) ;;
+        add) COMPREPLY=($(compgen -W "-d -e" -- "${cur}")) ;;
+        edit|show|progress|done|rm)
+            if [[ ${COMP_CWORD} -eq 2 ]]; then
+                COMPREPLY=($(compgen -W "$(todo __ids 2>/dev/null)" -- "${cur}"))
+            elif [[ "${cmd}" == "edit" ]]; then
+                COMPREPLY=($(compgen -W "-t -d -e" -- "${cur}"))
+            elif [[ "${cmd}" == "done" ]]; then
+                COMPREPLY=($(compgen -W "--undo" -- "${cur}"))
+            fi ;;
+        config) COMPREPLY=($(compgen -W "set server api_key output" -- "${cur}")) ;;
+        completion) COMPREPLY=($(compgen -W "bash zsh" -- "${cur}")) ;;
+    esac
+}
+complete -F _todo todo
+`
+
+// Zsh completion script, %v is replaced with command names.
+const zshCompletion = `#compdef todo
+# zsh completion for todo, load with: source <(todo completion zsh)
+_todo() {
+    local -a commands
+    commands=(%v)
+
+    if (( CURRENT == 2 )); then
+        _describe 'command' commands
+        return
+    fi
+
+    case "${words[2]}" in
+        ls) _arguments '--period[expiry period]:period:(today tomorrow week)' '--archived[only archived todos]' ;;
+        add) _arguments '-d[description]:description:' '-e[expiry date]:date:' ;;
+        edit|show|progress|done|rm)
+            if (( CURRENT == 3 )); then
+                compadd -- $(todo __ids 2>/dev/null)
+            fi ;;
+        config) compadd set server api_key output ;;
+        completion) compadd bash zsh ;;
+    esac
+}
+compdef _todo todo
+`
+
+func runCompletion(ctx context.Context, a *app, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: todo %v", commands["completion"].usage)
+	}
+
+	switch args[0] {
+	case "bash":
+		fmt.Fprintf(a.out.w, bashCompletion, strings.Join(commandNames(), " "))
+	case "zsh":
+		var described []string
+		for _, name := range commandNames() {
+			described = append(described, fmt.Sprintf("'%v:%v'", name, commands[name].help))
+		}
+		fmt.Fprintf(a.out.w, zshCompletion, strings.Join(described, " "))
+	default:
+		return fmt.Errorf("unsupported shell %q, use bash or zsh", args[0])
+	}
+	return nil
+}
+
+// Prints Ids of all todos for shell completion.
+func runIds(ctx context.Context, a *app, args []string) error {
+	todos, err := a.client.ListTodos(ctx, client.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, todo := range todos {
+		fmt.Fprintln(a.out.w, todo.Id)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Settings of the client read from config file and environment.
+//
+// TODO_SERVER and TODO_API_KEY environment variables
+// take precedence over values from the file.
+type config struct {
+	Server string `json:"server"`
+	APIKey string `json:"api_key,omitempty"`
+	Output string `json:"output,omitempty"`
+}
+
+// Server used when none is configured.
+const defaultServer = "http://localhost:8090"
+
+// Returns default path of config file, "~/.config/todo/config.json" on Linux.
+func defaultConfigPath() string {
+	if path := os.Getenv("TODO_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "todo.json"
+	}
+	return filepath.Join(dir, "todo", "config.json")
+}
+
+// Reads config from file at path. Missing file is not an error.
+func readConfig(path string) (config, error) {
+	cfg := config{Server: defaultServer, Output: "table"}
+
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return cfg, err
+	default:
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return cfg, fmt.Errorf("cannot parse config %v: %w", path, err)
+		}
+	}
+	return cfg, nil
+}
+
+// Reads config from file at path and applies environment variables.
+func loadConfig(path string) (config, error) {
+	cfg, err := readConfig(path)
+	if err != nil {
+		return cfg, err
+	}
+
+	if server := os.Getenv("TODO_SERVER"); server != "" {
+		cfg.Server = server
+	}
+	if key := os.Getenv("TODO_API_KEY"); key != "" {
+		cfg.APIKey = key
+	}
+	return cfg, nil
+}
+
+// Writes config to file at path, readable only by its owner as it holds API key.
+func saveConfig(path string, cfg config) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(data, '\n'), 0o600)
+}
+
+// Sets single config value by its name.
+func (c *config) set(name, value string) error {
+	switch name {
+	case "server":
+		c.Server = value
+	case "api_key":
+		c.APIKey = value
+	case "output":
+		if value != "table" && value != "json" {
+			return fmt.Errorf("output must be table or json")
+		}
+		c.Output = value
+	default:
+		return fmt.Errorf("unknown config key %q, use server, api_key or output", name)
+	}
+	return nil
+}
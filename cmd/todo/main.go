@@ -0,0 +1,116 @@
+// Command todo is a command-line client of Todos API.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"text/tabwriter"
+
+	"github.com/vilderxyz/todos/client"
+)
+
+// State shared by all commands.
+type app struct {
+	cfg        config
+	configPath string
+	client     *client.Client
+	out        *printer
+}
+
+// Single command of the client.
+type command struct {
+	usage string
+	help  string
+	run   func(ctx context.Context, a *app, args []string) error
+}
+
+// Commands of the client by their names.
+var commands map[string]command
+
+func init() {
+	commands = map[string]command{
+		"add":        {"add <title> [-d description] [-e yyyy-mm-dd]", "creates todo, expiring tomorrow by default", runAdd},
+		"ls":         {"ls [--period today|tomorrow|week] [--archived]", "lists todos", runList},
+		"show":       {"show <id>", "shows single todo", runShow},
+		"edit":       {"edit <id> [-t title] [-d description] [-e yyyy-mm-dd]", "changes title, description or expiry of todo", runEdit},
+		"progress":   {"progress <id> <percent>", "sets completion progress of todo", runProgress},
+		"done":       {"done <id> [--undo]", "finishes todo or reopens it", runDone},
+		"rm":         {"rm <id>", "moves todo to trash", runRemove},
+		"config":     {"config [set <server|api_key|output> <value>]", "shows or changes configuration", runConfig},
+		"completion": {"completion bash|zsh", "prints shell completion script", runCompletion},
+
+		// Used by completion scripts
+		"__ids": {"", "", runIds},
+	}
+}
+
+func main() {
+	flags := flag.NewFlagSet("todo", flag.ExitOnError)
+	configPath := flags.String("config", defaultConfigPath(), "path of config file")
+	server := flags.String("server", "", "url of Todos API, overrides config")
+	output := flags.String("o", "", "output format, table or json, overrides config")
+	flags.Usage = func() { printUsage(flags) }
+	flags.Parse(os.Args[1:])
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	cmd, ok := commands[flags.Arg(0)]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "todo: unknown command %q\n\n", flags.Arg(0))
+		flags.Usage()
+		os.Exit(2)
+	}
+
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fail(err)
+	}
+	if *server != "" {
+		cfg.Server = *server
+	}
+	if *output != "" {
+		cfg.Output = *output
+	}
+	if cfg.Output != "table" && cfg.Output != "json" {
+		fail(fmt.Errorf("output must be table or json"))
+	}
+
+	a := &app{
+		cfg:        cfg,
+		configPath: *configPath,
+		client:     client.New(cfg.Server, client.WithAPIKey(cfg.APIKey)),
+		out:        &printer{w: os.Stdout, json: cfg.Output == "json"},
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := cmd.run(ctx, a, flags.Args()[1:]); err != nil {
+		fail(err)
+	}
+}
+
+// Prints error and exits with failure status.
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "todo:", err)
+	os.Exit(1)
+}
+
+// Prints usage of global flags and all commands.
+func printUsage(flags *flag.FlagSet) {
+	w := flags.Output()
+	fmt.Fprintln(w, "Usage: todo [flags] <command> [arguments]")
+	fmt.Fprintln(w, "\nCommands:")
+	tw := tabwriter.NewWriter(w, 0, 0, 3, ' ', 0)
+	for _, name := range commandNames() {
+		fmt.Fprintf(tw, "  %v\t%v\n", commands[name].usage, commands[name].help)
+	}
+	tw.Flush()
+	fmt.Fprintln(w, "\nFlags:")
+	flags.PrintDefaults()
+}
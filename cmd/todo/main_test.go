@@ -0,0 +1,56 @@
+package main
+
+import (
+	"flag"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseArgs(t *testing.T) {
+	flags := flag.NewFlagSet("edit", flag.ContinueOnError)
+	title := flags.String("t", "", "")
+	description := flags.String("d", "", "")
+
+	args, err := parseArgs(flags, []string{"-t", "Wash car", "12", "-d", "Outside"})
+	require.NoError(t, err)
+	require.Equal(t, []string{"12"}, args)
+	require.Equal(t, "Wash car", *title)
+	require.Equal(t, "Outside", *description)
+
+	_, err = parseArgs(flags, []string{"12", "-x"})
+	require.Error(t, err)
+}
+
+func TestConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todo", "config.json")
+	t.Setenv("TODO_SERVER", "")
+	t.Setenv("TODO_API_KEY", "")
+
+	cfg, err := loadConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, defaultServer, cfg.Server)
+	require.Equal(t, "table", cfg.Output)
+
+	require.NoError(t, cfg.set("server", "http://todos.local"))
+	require.NoError(t, cfg.set("api_key", "secret"))
+	require.Error(t, cfg.set("output", "xml"))
+	require.Error(t, cfg.set("unknown", "value"))
+	require.NoError(t, saveConfig(path, cfg))
+
+	cfg, err = loadConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, "http://todos.local", cfg.Server)
+	require.Equal(t, "secret", cfg.APIKey)
+
+	// Environment takes precedence over the file
+	t.Setenv("TODO_API_KEY", "other")
+	cfg, err = loadConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, "other", cfg.APIKey)
+
+	cfg, err = readConfig(path)
+	require.NoError(t, err)
+	require.Equal(t, "secret", cfg.APIKey)
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/vilderxyz/todos/db"
+)
+
+// Writes results of commands as table or JSON.
+type printer struct {
+	w    io.Writer
+	json bool
+}
+
+// Writes value as indented JSON.
+func (p *printer) encode(value any) error {
+	encoder := json.NewEncoder(p.w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+// Writes list of todos, one per row.
+func (p *printer) todos(todos []db.Todo) error {
+	if p.json {
+		if todos == nil {
+			todos = []db.Todo{}
+		}
+		return p.encode(todos)
+	}
+
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTITLE\tSTATUS\tPROGRESS\tEXPIRY")
+	for _, todo := range todos {
+		fmt.Fprintf(w, "%d\t%v\t%v\t%.0f%%\t%v\n",
+			todo.Id, todo.Title, status(todo), todo.Completion, todo.Expiry.Format(dateLayout))
+	}
+	return w.Flush()
+}
+
+// Writes all fields of single todo.
+func (p *printer) todo(todo db.Todo) error {
+	if p.json {
+		return p.encode(todo)
+	}
+
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Id:\t%d\n", todo.Id)
+	fmt.Fprintf(w, "Title:\t%v\n", todo.Title)
+	fmt.Fprintf(w, "Description:\t%v\n", todo.Description)
+	fmt.Fprintf(w, "Status:\t%v\n", status(todo))
+	fmt.Fprintf(w, "Progress:\t%.0f%%\n", todo.Completion)
+	fmt.Fprintf(w, "Expiry:\t%v\n", todo.Expiry.Format(dateLayout))
+	if todo.DoneAt != nil {
+		fmt.Fprintf(w, "Done at:\t%v\n", todo.DoneAt.Format("2006-01-02 15:04"))
+	}
+	if todo.ArchivedAt != nil {
+		fmt.Fprintf(w, "Archived at:\t%v\n", todo.ArchivedAt.Format("2006-01-02 15:04"))
+	}
+	return w.Flush()
+}
+
+// Writes configuration and path of its file.
+func (p *printer) config(path string, cfg config) error {
+	if p.json {
+		return p.encode(struct {
+			Path string `json:"path"`
+			config
+		}{path, cfg})
+	}
+
+	w := tabwriter.NewWriter(p.w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "file:\t%v\n", path)
+	fmt.Fprintf(w, "server:\t%v\n", cfg.Server)
+	fmt.Fprintf(w, "api_key:\t%v\n", cfg.APIKey)
+	fmt.Fprintf(w, "output:\t%v\n", cfg.Output)
+	return w.Flush()
+}
+
+// Writes confirmation of command without other result.
+func (p *printer) message(message string) error {
+	if p.json {
+		return p.encode(map[string]string{"message": message})
+	}
+	_, err := fmt.Fprintln(p.w, message)
+	return err
+}
+
+// Returns todo's status, derived from IsDone for todos created before statuses existed.
+func status(todo db.Todo) string {
+	if todo.Status != "" {
+		return todo.Status
+	}
+	if todo.IsDone {
+		return "done"
+	}
+	return "-"
+}
@@ -0,0 +1,578 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/vilderxyz/todos/certs"
+	"github.com/vilderxyz/todos/logging"
+	"github.com/vilderxyz/todos/ratelimit"
+	"github.com/vilderxyz/todos/tracing"
+	"github.com/vilderxyz/todos/workflow"
+	"gopkg.in/yaml.v3"
+)
+
+// Configuration of the application.
+//
+// Every setting has a key in configuration file given by its yaml tag,
+// environment variable given by its env tag and flag named after
+// the variable, e.g. DB_HOST is set with -db-host flag.
+type Config struct {
+	Environment string `yaml:"environment" env:"APP_ENV" usage:"development or production, selects defaults of CORS origins and HSTS"`
+
+	Server    Server    `yaml:"server"`
+	TLS       TLS       `yaml:"tls"`
+	GRPC      GRPC      `yaml:"grpc"`
+	DB        DB        `yaml:"db"`
+	Cache     Cache     `yaml:"cache"`
+	CORS      CORS      `yaml:"cors"`
+	Security  Security  `yaml:"security"`
+	RateLimit RateLimit `yaml:"rate_limit"`
+	GraphQL   GraphQL   `yaml:"graphql"`
+	Workers   Workers   `yaml:"workers"`
+	Features  Features  `yaml:"features"`
+	Log       Log       `yaml:"log"`
+	Tracing   Tracing   `yaml:"tracing"`
+
+	Workflow string `yaml:"workflow" env:"STATUS_WORKFLOW" usage:"workflow of todo statuses in format \"from>to,to;from>to\", empty for the default one"`
+}
+
+// Settings of http server.
+type Server struct {
+	Addr           string        `yaml:"addr" env:"SERVER_ADDR" usage:"address http server listens on"`
+	PublicURL      string        `yaml:"public_url" env:"LISTEN_ADDR" usage:"URL the API is reachable at, only logged"`
+	QueryTimeout   time.Duration `yaml:"query_timeout" env:"QUERY_TIMEOUT" usage:"time limit of database queries of a single request, 0 disables it"`
+	IdempotencyTTL time.Duration `yaml:"idempotency_ttl" env:"IDEMPOTENCY_TTL" usage:"time after which idempotency keys can be reused"`
+
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"SERVER_READ_TIMEOUT" usage:"time limit of reading whole request, 0 disables it"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"SERVER_READ_HEADER_TIMEOUT" usage:"time limit of reading request headers, 0 disables it"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"SERVER_WRITE_TIMEOUT" usage:"time limit from reading request headers to writing the response, 0 disables it"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"SERVER_IDLE_TIMEOUT" usage:"time keep-alive connection waits for the next request, 0 disables it"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" usage:"time requests in progress have to finish after SIGINT or SIGTERM"`
+	ShutdownDelay     time.Duration `yaml:"shutdown_delay" env:"SHUTDOWN_DELAY" usage:"time /readyz fails before server stops accepting connections on shutdown"`
+
+	HealthCheckTimeout time.Duration `yaml:"health_check_timeout" env:"HEALTH_CHECK_TIMEOUT" usage:"time limit of each /readyz check"`
+}
+
+// Settings of TLS of http and gRPC servers.
+type TLS struct {
+	CertFile       string        `yaml:"cert_file" env:"TLS_CERT_FILE" usage:"PEM file of server's certificate chain, serves HTTPS together with TLS_KEY_FILE"`
+	KeyFile        string        `yaml:"key_file" env:"TLS_KEY_FILE" usage:"PEM file of server's private key"`
+	ReloadInterval time.Duration `yaml:"reload_interval" env:"TLS_RELOAD_INTERVAL" usage:"how often certificate files are checked for changes, 0 disables reloading"`
+	MinVersion     string        `yaml:"min_version" env:"TLS_MIN_VERSION" usage:"minimum TLS version: 1.2 or 1.3"`
+	CipherSuites   []string      `yaml:"cipher_suites" env:"TLS_CIPHER_SUITES" usage:"comma separated TLS 1.2 cipher suites, empty uses Go's defaults"`
+
+	ClientCAFile string   `yaml:"client_ca_file" env:"TLS_CLIENT_CA_FILE" usage:"PEM file of CAs verifying client certificates, enables mutual TLS"`
+	ClientAuth   string   `yaml:"client_auth" env:"TLS_CLIENT_AUTH" usage:"whether clients must send certificate: optional or require"`
+	ClientUsers  []string `yaml:"client_users" env:"TLS_CLIENT_USERS" usage:"comma separated common_name=user pairs authenticating clients by certificates"`
+}
+
+// Reports whether servers use TLS.
+func (t TLS) Enabled() bool {
+	return t.CertFile != ""
+}
+
+// Returns options of TLS.
+func (t TLS) Options() certs.Options {
+	return certs.Options{
+		CertFile:     t.CertFile,
+		KeyFile:      t.KeyFile,
+		ClientCAFile: t.ClientCAFile,
+		ClientAuth:   t.ClientAuth,
+		MinVersion:   t.MinVersion,
+		CipherSuites: t.CipherSuites,
+	}
+}
+
+// Returns users of clients by common names of their certificates.
+func (t TLS) Users() (certs.Users, error) {
+	return certs.ParseUsers(t.ClientUsers)
+}
+
+// Settings of gRPC server.
+type GRPC struct {
+	Addr string `yaml:"addr" env:"GRPC_ADDR" usage:"address gRPC server listens on, empty disables it"`
+}
+
+// Settings of database connection.
+type DB struct {
+	Host     string `yaml:"host" env:"DB_HOST" usage:"database host"`
+	Port     int    `yaml:"port" env:"DB_PORT" usage:"database port"`
+	User     string `yaml:"user" env:"DB_USER" usage:"database user"`
+	Password string `yaml:"password" env:"DB_PASSWORD" usage:"database password" secret:"true"`
+	Name     string `yaml:"name" env:"DB_NAME" usage:"database name"`
+	SSLMode  string `yaml:"ssl_mode" env:"DB_SSL_MODE" usage:"sslmode of connection, one of disable, require, verify-ca, verify-full"`
+
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" usage:"maximum number of open connections, 0 means unlimited"`
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" usage:"maximum number of idle connections"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" usage:"maximum time a connection is reused, 0 means forever"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" usage:"maximum time a connection stays idle, 0 means forever"`
+
+	MigrateOnStart bool `yaml:"migrate_on_start" env:"MIGRATE_ON_START" usage:"apply pending migrations before serving"`
+}
+
+// Returns connection string of database.
+func (d DB) DSN() string {
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(d.User, d.Password),
+		Host:     fmt.Sprintf("%v:%v", d.Host, d.Port),
+		Path:     d.Name,
+		RawQuery: url.Values{"sslmode": {d.SSLMode}}.Encode(),
+	}
+	return dsn.String()
+}
+
+// Settings of in-process cache of Todos read from database.
+type Cache struct {
+	Size int           `yaml:"size" env:"CACHE_SIZE" usage:"maximum number of cached query results, 0 disables the cache"`
+	TTL  time.Duration `yaml:"ttl" env:"CACHE_TTL" usage:"time query results are cached for"`
+}
+
+// Environments of the application.
+const (
+	EnvDevelopment = "development"
+	EnvProduction  = "production"
+)
+
+// Settings of Cross-Origin Resource Sharing.
+type CORS struct {
+	AllowOrigins     []string      `yaml:"allow_origins" env:"CORS_ALLOW_ORIGINS" usage:"comma separated origins allowed to call the API, * allows all; all in development and none in production when empty"`
+	AllowMethods     []string      `yaml:"allow_methods" env:"CORS_ALLOW_METHODS" usage:"comma separated methods allowed in cross-origin requests"`
+	AllowHeaders     []string      `yaml:"allow_headers" env:"CORS_ALLOW_HEADERS" usage:"comma separated request headers allowed in cross-origin requests besides those of the API"`
+	AllowCredentials bool          `yaml:"allow_credentials" env:"CORS_ALLOW_CREDENTIALS" usage:"let browsers send credentials with cross-origin requests, needs explicit origins"`
+	MaxAge           time.Duration `yaml:"max_age" env:"CORS_MAX_AGE" usage:"time browsers cache results of preflight requests"`
+}
+
+// Security headers of http responses.
+type Security struct {
+	HSTS         string `yaml:"hsts" env:"SECURITY_HSTS" usage:"value of Strict-Transport-Security header, omitted in development and max-age=31536000; includeSubDomains in production when empty"`
+	FrameOptions string `yaml:"frame_options" env:"SECURITY_FRAME_OPTIONS" usage:"value of X-Frame-Options header: DENY or SAMEORIGIN"`
+}
+
+// Settings depending on environment, applied to those left empty.
+var environments = map[string]struct {
+	allowOrigins []string
+	hsts         string
+}{
+	EnvDevelopment: {allowOrigins: []string{"*"}},
+	EnvProduction:  {hsts: "max-age=31536000; includeSubDomains"},
+}
+
+// Sets settings left empty to defaults of configured environment.
+func (c *Config) applyEnvironment() {
+	env := environments[c.Environment]
+	if len(c.CORS.AllowOrigins) == 0 {
+		c.CORS.AllowOrigins = env.allowOrigins
+	}
+	if c.Security.HSTS == "" {
+		c.Security.HSTS = env.hsts
+	}
+}
+
+// Limits of requests per client in format "n/period[:burst]".
+type RateLimit struct {
+	Read  string `yaml:"read" env:"RATE_LIMIT_READ" usage:"limit of GET routes, 0/s disables it"`
+	Write string `yaml:"write" env:"RATE_LIMIT_WRITE" usage:"limit of POST, PATCH and DELETE routes, 0/s disables it"`
+	Auth  string `yaml:"auth" env:"RATE_LIMIT_AUTH" usage:"limit of API key lookups per IP address, 0/s disables it"`
+}
+
+// Returns parsed limits of reads, writes and API key lookups.
+func (r RateLimit) Limits() (read, write, auth ratelimit.Limit, err error) {
+	if read, err = ratelimit.ParseLimit(r.Read); err != nil {
+		return read, write, auth, err
+	}
+	if write, err = ratelimit.ParseLimit(r.Write); err != nil {
+		return read, write, auth, err
+	}
+	auth, err = ratelimit.ParseLimit(r.Auth)
+	return read, write, auth, err
+}
+
+// Limits of GraphQL queries.
+type GraphQL struct {
+	MaxDepth      int `yaml:"max_depth" env:"GRAPHQL_MAX_DEPTH" usage:"maximum nesting of fields in GraphQL query, 0 disables it"`
+	MaxComplexity int `yaml:"max_complexity" env:"GRAPHQL_MAX_COMPLEXITY" usage:"maximum complexity of GraphQL query, 0 disables it"`
+}
+
+// Settings of background workers.
+type Workers struct {
+	TrashRetention time.Duration `yaml:"trash_retention" env:"TRASH_RETENTION" usage:"time after which todos are purged from trash, 0 keeps them forever"`
+	ArchiveAfter   time.Duration `yaml:"archive_after" env:"ARCHIVE_AFTER" usage:"time after which done todos are archived, 0 disables it"`
+}
+
+// Settings of logging.
+type Log struct {
+	Level              string        `yaml:"level" env:"LOG_LEVEL" usage:"minimum level of logged records: debug, info, warn or error"`
+	Format             string        `yaml:"format" env:"LOG_FORMAT" usage:"format of log records: json or text"`
+	RedactDescriptions bool          `yaml:"redact_descriptions" env:"LOG_REDACT_DESCRIPTIONS" usage:"replace descriptions of todos and string values in logged SQL with [REDACTED]"`
+	SlowQuery          time.Duration `yaml:"slow_query" env:"LOG_SLOW_QUERY" usage:"duration after which database queries are logged as slow, 0 disables it"`
+}
+
+// Returns options of loggers.
+func (l Log) Options() logging.Options {
+	return logging.Options{
+		Level:              l.Level,
+		Format:             l.Format,
+		RedactDescriptions: l.RedactDescriptions,
+		SlowQuery:          l.SlowQuery,
+	}
+}
+
+// Settings of tracing.
+type Tracing struct {
+	Exporter    string  `yaml:"exporter" env:"TRACING_EXPORTER" usage:"exporter of spans: none, stdout or otlp"`
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_OTLP_ENDPOINT" usage:"address of OTLP gRPC collector"`
+	Insecure    bool    `yaml:"insecure" env:"TRACING_OTLP_INSECURE" usage:"connect to OTLP collector without TLS"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" usage:"fraction of new traces that are sampled, from 0 to 1"`
+}
+
+// Returns options of tracing.
+func (t Tracing) Options() tracing.Options {
+	return tracing.Options{
+		ServiceName: "todos",
+		Exporter:    t.Exporter,
+		Endpoint:    t.Endpoint,
+		Insecure:    t.Insecure,
+		SampleRatio: t.SampleRatio,
+	}
+}
+
+// Optional parts of the application.
+type Features struct {
+	GraphQL bool `yaml:"graphql" env:"FEATURE_GRAPHQL" usage:"serve /graphql"`
+	Docs    bool `yaml:"docs" env:"FEATURE_DOCS" usage:"serve /openapi.json and /docs"`
+	Metrics bool `yaml:"metrics" env:"FEATURE_METRICS" usage:"serve Prometheus metrics at /metrics"`
+}
+
+// Returns configuration used when no other source sets a value.
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Addr:           ":8080",
+			QueryTimeout:   5 * time.Second,
+			IdempotencyTTL: 24 * time.Hour,
+
+			ReadTimeout:       10 * time.Second,
+			ReadHeaderTimeout: 5 * time.Second,
+			WriteTimeout:      30 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+			ShutdownTimeout:   15 * time.Second,
+
+			HealthCheckTimeout: 2 * time.Second,
+		},
+		TLS: TLS{
+			ReloadInterval: time.Minute,
+			MinVersion:     "1.2",
+			ClientAuth:     certs.ClientAuthOptional,
+		},
+		DB: DB{
+			Host:            "localhost",
+			Port:            5432,
+			SSLMode:         "disable",
+			MaxOpenConns:    10,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: time.Hour,
+			MigrateOnStart:  true,
+		},
+		Environment: EnvProduction,
+		Cache: Cache{
+			TTL: 30 * time.Second,
+		},
+		CORS: CORS{
+			AllowMethods: []string{"GET", "POST", "PUT", "PATCH", "DELETE"},
+			AllowHeaders: []string{"Authorization", "If-Match", "If-None-Match", "If-Modified-Since"},
+			MaxAge:       12 * time.Hour,
+		},
+		Security: Security{
+			FrameOptions: "DENY",
+		},
+		RateLimit: RateLimit{
+			Read:  "20/s:40",
+			Write: "5/s:10",
+			Auth:  "25/s:50",
+		},
+		GraphQL: GraphQL{
+			MaxDepth:      6,
+			MaxComplexity: 5000,
+		},
+		Workers: Workers{
+			TrashRetention: 30 * 24 * time.Hour,
+		},
+		Features: Features{
+			GraphQL: true,
+			Docs:    true,
+			Metrics: true,
+		},
+		Log: Log{
+			Level:              "info",
+			Format:             logging.FormatJSON,
+			RedactDescriptions: true,
+			SlowQuery:          200 * time.Millisecond,
+		},
+		Tracing: Tracing{
+			Exporter:    tracing.ExporterNone,
+			Endpoint:    "localhost:4317",
+			Insecure:    true,
+			SampleRatio: 1,
+		},
+	}
+}
+
+// Error listing all problems of invalid configuration.
+type ValidationError []string
+
+func (e ValidationError) Error() string {
+	return "invalid configuration:\n  " + strings.Join(e, "\n  ")
+}
+
+// Throws ValidationError when any setting is missing or invalid.
+func (c *Config) Validate() error {
+	var problems ValidationError
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			problems = append(problems, fmt.Sprintf(format, args...))
+		}
+	}
+
+	check(c.Server.Addr != "", "server.addr (SERVER_ADDR) is required")
+	check(c.Server.QueryTimeout >= 0, "server.query_timeout (QUERY_TIMEOUT) cannot be negative")
+	check(c.Server.IdempotencyTTL > 0, "server.idempotency_ttl (IDEMPOTENCY_TTL) must be positive")
+	check(c.Server.ReadTimeout >= 0, "server.read_timeout (SERVER_READ_TIMEOUT) cannot be negative")
+	check(c.Server.ReadHeaderTimeout >= 0, "server.read_header_timeout (SERVER_READ_HEADER_TIMEOUT) cannot be negative")
+	check(c.Server.WriteTimeout >= 0, "server.write_timeout (SERVER_WRITE_TIMEOUT) cannot be negative")
+	check(c.Server.WriteTimeout == 0 || c.Server.QueryTimeout == 0 || c.Server.WriteTimeout > c.Server.QueryTimeout,
+		"server.write_timeout (SERVER_WRITE_TIMEOUT) must be longer than server.query_timeout (QUERY_TIMEOUT), so errors of timed out queries can be sent")
+	check(c.Server.IdleTimeout >= 0, "server.idle_timeout (SERVER_IDLE_TIMEOUT) cannot be negative")
+	check(c.Server.ShutdownTimeout > 0, "server.shutdown_timeout (SHUTDOWN_TIMEOUT) must be positive")
+	check(c.Server.ShutdownDelay >= 0 && c.Server.ShutdownDelay < c.Server.ShutdownTimeout,
+		"server.shutdown_delay (SHUTDOWN_DELAY) cannot be negative and must be shorter than server.shutdown_timeout (SHUTDOWN_TIMEOUT)")
+	check(c.Server.HealthCheckTimeout > 0, "server.health_check_timeout (HEALTH_CHECK_TIMEOUT) must be positive")
+
+	check((c.TLS.CertFile == "") == (c.TLS.KeyFile == ""),
+		"tls.cert_file (TLS_CERT_FILE) and tls.key_file (TLS_KEY_FILE) must be set together")
+	check(c.TLS.ReloadInterval >= 0, "tls.reload_interval (TLS_RELOAD_INTERVAL) cannot be negative")
+	_, err := certs.ParseVersion(c.TLS.MinVersion)
+	check(err == nil, "tls.min_version (TLS_MIN_VERSION): %v", err)
+	_, err = certs.ParseCipherSuites(c.TLS.CipherSuites)
+	check(err == nil, "tls.cipher_suites (TLS_CIPHER_SUITES): %v", err)
+	check(c.TLS.ClientCAFile == "" || c.TLS.Enabled(), "tls.client_ca_file (TLS_CLIENT_CA_FILE) requires tls.cert_file (TLS_CERT_FILE)")
+	switch c.TLS.ClientAuth {
+	case certs.ClientAuthOptional, certs.ClientAuthRequire:
+	default:
+		check(false, "tls.client_auth (TLS_CLIENT_AUTH) must be one of optional, require, got %q", c.TLS.ClientAuth)
+	}
+	_, err = c.TLS.Users()
+	check(err == nil, "tls.client_users (TLS_CLIENT_USERS): %v", err)
+	check(len(c.TLS.ClientUsers) == 0 || c.TLS.ClientCAFile != "", "tls.client_users (TLS_CLIENT_USERS) requires tls.client_ca_file (TLS_CLIENT_CA_FILE)")
+
+	check(c.DB.Host != "", "db.host (DB_HOST) is required")
+	check(c.DB.Port > 0 && c.DB.Port < 65536, "db.port (DB_PORT) must be between 1 and 65535, got %v", c.DB.Port)
+	check(c.DB.User != "", "db.user (DB_USER) is required")
+	check(c.DB.Name != "", "db.name (DB_NAME) is required")
+	switch c.DB.SSLMode {
+	case "disable", "require", "verify-ca", "verify-full":
+	default:
+		check(false, "db.ssl_mode (DB_SSL_MODE) must be one of disable, require, verify-ca, verify-full, got %q", c.DB.SSLMode)
+	}
+	check(c.DB.MaxOpenConns >= 0, "db.max_open_conns (DB_MAX_OPEN_CONNS) cannot be negative")
+	check(c.DB.MaxIdleConns >= 0, "db.max_idle_conns (DB_MAX_IDLE_CONNS) cannot be negative")
+	check(c.DB.MaxOpenConns == 0 || c.DB.MaxIdleConns <= c.DB.MaxOpenConns,
+		"db.max_idle_conns (DB_MAX_IDLE_CONNS) cannot be greater than db.max_open_conns (DB_MAX_OPEN_CONNS)")
+	check(c.DB.ConnMaxLifetime >= 0, "db.conn_max_lifetime (DB_CONN_MAX_LIFETIME) cannot be negative")
+	check(c.DB.ConnMaxIdleTime >= 0, "db.conn_max_idle_time (DB_CONN_MAX_IDLE_TIME) cannot be negative")
+
+	check(c.Cache.Size >= 0, "cache.size (CACHE_SIZE) cannot be negative")
+	check(c.Cache.Size == 0 || c.Cache.TTL > 0, "cache.ttl (CACHE_TTL) must be positive when cache is enabled")
+
+	_, ok := environments[c.Environment]
+	check(ok, "environment (APP_ENV) must be one of development, production, got %q", c.Environment)
+
+	for _, origin := range c.CORS.AllowOrigins {
+		check(validOrigin(origin), "cors.allow_origins (CORS_ALLOW_ORIGINS) contains invalid origin %q", origin)
+		check(origin != "*" || !c.CORS.AllowCredentials,
+			"cors.allow_credentials (CORS_ALLOW_CREDENTIALS) cannot be used when cors.allow_origins (CORS_ALLOW_ORIGINS) allows all origins")
+	}
+	check(len(c.CORS.AllowMethods) > 0, "cors.allow_methods (CORS_ALLOW_METHODS) is required")
+	for _, method := range c.CORS.AllowMethods {
+		check(method == strings.ToUpper(method) && !strings.ContainsAny(method, " /"),
+			"cors.allow_methods (CORS_ALLOW_METHODS) contains invalid method %q", method)
+	}
+	check(c.CORS.MaxAge >= 0, "cors.max_age (CORS_MAX_AGE) cannot be negative")
+
+	check(c.Security.HSTS == "" || strings.HasPrefix(c.Security.HSTS, "max-age="),
+		"security.hsts (SECURITY_HSTS) must start with max-age=, got %q", c.Security.HSTS)
+	switch c.Security.FrameOptions {
+	case "DENY", "SAMEORIGIN":
+	default:
+		check(false, "security.frame_options (SECURITY_FRAME_OPTIONS) must be one of DENY, SAMEORIGIN, got %q", c.Security.FrameOptions)
+	}
+
+	_, err = ratelimit.ParseLimit(c.RateLimit.Read)
+	check(err == nil, "rate_limit.read (RATE_LIMIT_READ): %v", err)
+	_, err = ratelimit.ParseLimit(c.RateLimit.Write)
+	check(err == nil, "rate_limit.write (RATE_LIMIT_WRITE): %v", err)
+	_, err = ratelimit.ParseLimit(c.RateLimit.Auth)
+	check(err == nil, "rate_limit.auth (RATE_LIMIT_AUTH): %v", err)
+
+	check(c.GraphQL.MaxDepth >= 0, "graphql.max_depth (GRAPHQL_MAX_DEPTH) cannot be negative")
+	check(c.GraphQL.MaxComplexity >= 0, "graphql.max_complexity (GRAPHQL_MAX_COMPLEXITY) cannot be negative")
+
+	check(c.Workers.TrashRetention >= 0, "workers.trash_retention (TRASH_RETENTION) cannot be negative")
+	check(c.Workers.ArchiveAfter >= 0, "workers.archive_after (ARCHIVE_AFTER) cannot be negative")
+
+	_, err = logging.New(io.Discard, c.Log.Options())
+	check(err == nil, "log (LOG_LEVEL, LOG_FORMAT): %v", err)
+	check(c.Log.SlowQuery >= 0, "log.slow_query (LOG_SLOW_QUERY) cannot be negative")
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterStdout, tracing.ExporterOTLP:
+	default:
+		check(false, "tracing.exporter (TRACING_EXPORTER) must be one of none, stdout, otlp, got %q", c.Tracing.Exporter)
+	}
+	check(c.Tracing.Exporter != tracing.ExporterOTLP || c.Tracing.Endpoint != "",
+		"tracing.endpoint (TRACING_OTLP_ENDPOINT) is required by otlp exporter")
+	check(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1,
+		"tracing.sample_ratio (TRACING_SAMPLE_RATIO) must be between 0 and 1, got %v", c.Tracing.SampleRatio)
+
+	_, err = c.StatusWorkflow()
+	check(err == nil, "workflow (STATUS_WORKFLOW): %v", err)
+
+	if len(problems) > 0 {
+		return problems
+	}
+	return nil
+}
+
+// Reports whether origin is "*" or URL with http or https scheme and host only.
+func validOrigin(origin string) bool {
+	if origin == "*" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "" &&
+		(u.Path == "" || u.Path == "/") && u.RawQuery == ""
+}
+
+// Returns configured workflow of statuses or the default one.
+func (c *Config) StatusWorkflow() (*workflow.Machine, error) {
+	if c.Workflow == "" {
+		return workflow.Default, nil
+	}
+	return workflow.Parse(c.Workflow)
+}
+
+// Value replacing secrets in redacted configuration
+const redacted = "******"
+
+// Returns copy of configuration with secrets replaced, safe to print or log.
+func (c *Config) Redacted() *Config {
+	res := *c
+	res.CORS.AllowOrigins = append([]string(nil), c.CORS.AllowOrigins...)
+	res.CORS.AllowMethods = append([]string(nil), c.CORS.AllowMethods...)
+	res.CORS.AllowHeaders = append([]string(nil), c.CORS.AllowHeaders...)
+	res.TLS.CipherSuites = append([]string(nil), c.TLS.CipherSuites...)
+	res.TLS.ClientUsers = append([]string(nil), c.TLS.ClientUsers...)
+	for _, f := range fields(reflect.ValueOf(&res).Elem(), "") {
+		if f.secret && f.value.String() != "" {
+			f.value.SetString(redacted)
+		}
+	}
+	return &res
+}
+
+// Returns redacted configuration in format of configuration file.
+func (c *Config) String() string {
+	var out strings.Builder
+	encoder := yaml.NewEncoder(&out)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(c.Redacted()); err != nil {
+		return err.Error()
+	}
+	return out.String()
+}
+
+// Setting of configuration.
+type field struct {
+	path   string
+	env    string
+	usage  string
+	secret bool
+	value  reflect.Value
+}
+
+// Returns name of flag setting the field.
+func (f field) flag() string {
+	return strings.ToLower(strings.ReplaceAll(f.env, "_", "-"))
+}
+
+// Returns current value of the field in format parsed by parse.
+func (f field) String() string {
+	if list, ok := f.value.Interface().([]string); ok {
+		return strings.Join(list, ",")
+	}
+	return fmt.Sprint(f.value.Interface())
+}
+
+// Returns all settings of struct v, its nested structs included.
+func fields(v reflect.Value, prefix string) []field {
+	var res []field
+	for i := 0; i < v.NumField(); i++ {
+		tag := v.Type().Field(i)
+		path := prefix + tag.Tag.Get("yaml")
+
+		if tag.Type.Kind() == reflect.Struct && tag.Type != reflect.TypeOf(time.Duration(0)) {
+			res = append(res, fields(v.Field(i), path+".")...)
+			continue
+		}
+		if env := tag.Tag.Get("env"); env != "" {
+			res = append(res, field{
+				path:   path,
+				env:    env,
+				usage:  tag.Tag.Get("usage"),
+				secret: tag.Tag.Get("secret") == "true",
+				value:  v.Field(i),
+			})
+		}
+	}
+	return res
+}
+
+// Parses s into value of given type.
+//
+// Lists are comma separated.
+func parse(typ reflect.Type, s string) (reflect.Value, error) {
+	var value any
+	var err error
+	switch typ {
+	case reflect.TypeOf(""):
+		value = s
+	case reflect.TypeOf(0):
+		value, err = strconv.Atoi(s)
+	case reflect.TypeOf(0.0):
+		value, err = strconv.ParseFloat(s, 64)
+	case reflect.TypeOf(false):
+		value, err = strconv.ParseBool(s)
+	case reflect.TypeOf(time.Duration(0)):
+		value, err = time.ParseDuration(s)
+	case reflect.TypeOf([]string{}):
+		list := []string{}
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		value = list
+	default:
+		return reflect.Value{}, fmt.Errorf("unsupported type %v", typ)
+	}
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("invalid value %q", s)
+	}
+	return reflect.ValueOf(value), nil
+}
@@ -0,0 +1,136 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variable with path of configuration file
+const fileEnv = "CONFIG_FILE"
+
+// Returns configuration loaded from sources in order of precedence:
+// defaults, YAML file, environment variables and flags parsed from args.
+//
+// File is given with -config flag or CONFIG_FILE environment variable and can be omitted.
+// Empty environment variables are ignored. CORS origins and HSTS left empty
+// are set to defaults of configured environment.
+//
+// Throws an error when any source cannot be parsed and ValidationError
+// when resulting configuration is invalid.
+func Load(args []string) (*Config, error) {
+	return load(args, os.LookupEnv, os.Stderr)
+}
+
+// Loads configuration with given lookup of environment variables.
+// Usage of flags is written to output when they cannot be parsed.
+func load(args []string, lookupEnv func(string) (string, bool), output io.Writer) (*Config, error) {
+	cfg := Default()
+	settings := fields(reflect.ValueOf(cfg).Elem(), "")
+
+	// Flags are applied after other sources, so they are only collected while parsing
+	flags := flag.NewFlagSet("", flag.ContinueOnError)
+	flags.SetOutput(output)
+	file := flags.String("config", "", "path of YAML configuration file, overrides "+fileEnv)
+	var setFlags []func()
+	for _, f := range settings {
+		f := f
+		flags.Var(&flagValue{
+			isBool: f.value.Kind() == reflect.Bool,
+			value:  f.String(),
+			set: func(s string) error {
+				value, err := parse(f.value.Type(), s)
+				if err != nil {
+					return err
+				}
+				setFlags = append(setFlags, func() { f.value.Set(value) })
+				return nil
+			},
+		}, f.flag(), fmt.Sprintf("%v (%v)", f.usage, f.env))
+	}
+	if err := flags.Parse(args); err != nil {
+		return nil, err
+	}
+	if flags.NArg() > 0 {
+		return nil, fmt.Errorf("unexpected arguments %v", flags.Args())
+	}
+
+	if *file == "" {
+		*file, _ = lookupEnv(fileEnv)
+	}
+	if *file != "" {
+		if err := readFile(cfg, *file); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, f := range settings {
+		if s, ok := lookupEnv(f.env); ok && s != "" {
+			value, err := parse(f.value.Type(), s)
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse %v: %w", f.env, err)
+			}
+			f.value.Set(value)
+		}
+	}
+
+	for _, set := range setFlags {
+		set()
+	}
+	cfg.applyEnvironment()
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Overrides configuration with settings of YAML file at path.
+//
+// Throws an error for unknown keys, so typos do not go unnoticed.
+func readFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("cannot read configuration file: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("cannot parse configuration file %v: %w", path, err)
+	}
+	return nil
+}
+
+// Flag setting a field of configuration.
+type flagValue struct {
+	isBool bool
+	set    func(string) error
+	value  string
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.value
+}
+
+func (v *flagValue) Set(s string) error {
+	if err := v.set(s); err != nil {
+		return err
+	}
+	v.value = s
+	return nil
+}
+
+// Lets boolean flags be given without value, e.g. -feature-graphql.
+func (v *flagValue) IsBoolFlag() bool {
+	return v.isBool
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Returns all archived Todos, most recently archived first.
+func (q *Queries) GetArchivedTodos(ctx context.Context) ([]Todo, error) {
+	var todos []Todo
+	result := q.db.WithContext(ctx).Where("archived_at IS NOT NULL").
+		Order("archived_at DESC").
+		Find(&todos)
+	return todos, result.Error
+}
+
+// Archives all finished Todos that were done before given time
+// and returns how many were archived.
+//
+// Todos finished before their completion time was recorded lack DoneAt
+// and are archived regardless of given time.
+//
+// Records archivization in history of every archived Todo on behalf of actor.
+func (q *Queries) ArchiveDoneTodos(ctx context.Context, before time.Time, actor string) (int64, error) {
+	var todos []Todo
+	err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("is_done AND (done_at < ? OR done_at IS NULL) AND archived_at IS NULL", before).
+			Find(&todos)
+		if result.Error != nil || len(todos) == 0 {
+			return result.Error
+		}
+
+		now := time.Now()
+		ids := make([]int64, len(todos))
+		entries := make([]History, len(todos))
+		for i := range todos {
+			archived := todos[i]
+			archived.ArchivedAt = &now
+
+			ids[i] = todos[i].Id
+			entries[i] = History{
+				TodoId:    todos[i].Id,
+				Actor:     actor,
+				Operation: OpUpdate,
+				Changes:   diffTodos(&todos[i], &archived),
+			}
+		}
+
+		err := tx.Model(&Todo{}).Where("id IN ?", ids).Update("archived_at", now).Error
+		if err != nil {
+			return err
+		}
+		return tx.Create(&entries).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(todos)), nil
+}
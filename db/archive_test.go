@@ -0,0 +1,63 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchiveDoneTodos(t *testing.T) {
+	done := createTodo(t)
+	done.IsDone = true
+	done.Status = "done"
+	done, err := testQueries.UpdateOneTodo(testCtx, done, testActor)
+	require.NoError(t, err)
+
+	unfinished := createTodo(t)
+
+	archived, err := testQueries.ArchiveDoneTodos(testCtx, done.DoneAt.Add(-time.Minute), testActor)
+	require.NoError(t, err)
+	require.Zero(t, archived)
+
+	archived, err = testQueries.ArchiveDoneTodos(testCtx, time.Now().Add(time.Minute), testActor)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, archived, int64(1))
+
+	recievedTodo, err := testQueries.GetOneTodoById(testCtx, done.Id)
+	require.NoError(t, err)
+	require.NotNil(t, recievedTodo.ArchivedAt)
+
+	todos, err := testQueries.GetAllTodos(testCtx)
+	require.NoError(t, err)
+	for _, todo := range todos {
+		require.NotEqual(t, done.Id, todo.Id)
+	}
+
+	todos, err = testQueries.GetArchivedTodos(testCtx)
+	require.NoError(t, err)
+	require.Equal(t, done.Id, todos[0].Id)
+
+	recievedTodo, err = testQueries.GetOneTodoById(testCtx, unfinished.Id)
+	require.NoError(t, err)
+	require.Nil(t, recievedTodo.ArchivedAt)
+
+	entries, _, err := testQueries.GetTodoHistory(testCtx, done.Id, 10, 0)
+	require.NoError(t, err)
+	require.Contains(t, entries[len(entries)-1].Changes, "archived_at")
+}
+
+func TestArchiveLegacyDoneTodos(t *testing.T) {
+	// Todos finished before done_at column was added lack completion time
+	legacy := createTodo(t)
+	err := testQueries.(*Queries).db.Exec("UPDATE todos SET is_done = true, status = 'done', done_at = NULL WHERE id = ?", legacy.Id).Error
+	require.NoError(t, err)
+
+	archived, err := testQueries.ArchiveDoneTodos(testCtx, time.Now().Add(-time.Hour), testActor)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, archived, int64(1))
+
+	recievedTodo, err := testQueries.GetOneTodoById(testCtx, legacy.Id)
+	require.NoError(t, err)
+	require.NotNil(t, recievedTodo.ArchivedAt)
+}
@@ -1,14 +1,37 @@
 package db
 
-import "time"
+import (
+	"context"
+	"time"
 
+	"gorm.io/gorm"
+)
+
+// Database access.
+//
+// Every query is cancelled when given context is done.
 type DB interface {
-	GetAllTodos() ([]Todo, error)
-	GetManyTodos(time.Time, time.Time) ([]Todo, error)
-	GetOneTodoById(int64) (Todo, error)
-	UpdateOneTodo(Todo) (Todo, error)
-	DeleteOneTodo(int64) error
-	CreateOneTodo(CreateTodoParams) (Todo, error)
+	GetAllTodos(context.Context) ([]Todo, error)
+	GetManyTodos(context.Context, time.Time, time.Time) ([]Todo, error)
+	GetOneTodoById(context.Context, int64) (Todo, error)
+	UpdateOneTodo(context.Context, Todo, string) (Todo, error)
+	DeleteOneTodo(context.Context, int64, string) error
+	CreateOneTodo(context.Context, CreateTodoParams, string) (Todo, error)
+	GetTodoHistory(context.Context, int64, int, int) ([]History, int64, error)
+	GetHistoryOfTodos(context.Context, []int64) ([]History, error)
+	GetTrashedTodos(context.Context) ([]Todo, error)
+	RestoreOneTodo(context.Context, int64, string) (Todo, error)
+	PurgeOneTodo(context.Context, int64, string) error
+	PurgeTrash(context.Context, time.Time, string) (int64, error)
+	GetTodoTransitions(context.Context, int64) ([]Transition, error)
+	GetTransitionsOfTodos(context.Context, []int64) ([]Transition, error)
+	GetArchivedTodos(context.Context) ([]Todo, error)
+	ArchiveDoneTodos(context.Context, time.Time, string) (int64, error)
+	CountTodos(context.Context, time.Time) (TodoCounts, error)
+	CreateUser(context.Context, string, string) (User, error)
+	GetUserByKeyHash(context.Context, string) (User, error)
+	DisableUser(context.Context, string) (User, error)
+	WithTx(context.Context, func(DB) error) error
 }
 
 // Todo ORM model structure
@@ -19,4 +42,17 @@ type Todo struct {
 	Completion  float32   `json:"completion" gorm:"not null"`
 	Expiry      time.Time `json:"expiry" gorm:"not null"`
 	IsDone      bool      `json:"is_done"`
+
+	// Current status in workflow and time when Todo was finished
+	Status string     `json:"status" gorm:"not null;default:backlog"`
+	DoneAt *time.Time `json:"done_at"`
+
+	// Set when Todo is archived, archived Todos are excluded from listings
+	ArchivedAt *time.Time `json:"archived_at" gorm:"index"`
+
+	// Set when Todo is moved to trash
+	DeletedAt gorm.DeletedAt `json:"deleted_at" gorm:"index"`
+
+	// Time of the last change, set by GORM on every save and update
+	UpdatedAt time.Time `json:"updated_at" gorm:"not null"`
 }
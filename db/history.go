@@ -0,0 +1,108 @@
+package db
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Operations recorded in History.
+const (
+	OpCreate  = "create"
+	OpUpdate  = "update"
+	OpDelete  = "delete"
+	OpRestore = "restore"
+	OpPurge   = "purge"
+)
+
+// History ORM model structure.
+//
+// Single append-only entry describing who changed a Todo, when and how.
+type History struct {
+	Id        int64     `json:"id" gorm:"primaryKey"`
+	TodoId    int64     `json:"todo_id" gorm:"not null;index"`
+	Actor     string    `json:"actor" gorm:"not null"`
+	Operation string    `json:"operation" gorm:"not null"`
+	Changes   Changes   `json:"changes" gorm:"type:jsonb;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+}
+
+// Value of a single Todo field before and after the change.
+//
+// Before is nil for created Todos and After is nil for deleted ones.
+type Change struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// Field-level diff keyed by json names of Todo fields.
+type Changes map[string]Change
+
+// Implements driver.Valuer, so Changes are stored as json.
+func (c Changes) Value() (driver.Value, error) {
+	if c == nil {
+		return "{}", nil
+	}
+	data, err := json.Marshal(c)
+	return string(data), err
+}
+
+// Implements sql.Scanner, so Changes are read from json.
+func (c *Changes) Scan(value any) error {
+	switch v := value.(type) {
+	case []byte:
+		return json.Unmarshal(v, c)
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	}
+	return errors.New("cannot scan changes")
+}
+
+// Returns diff between two states of a Todo.
+//
+// Nil before or after means that Todo did not exist on that side.
+// Id is never included as it cannot change, nor is UpdatedAt
+// that changes with every save.
+func diffTodos(before, after *Todo) Changes {
+	changes := Changes{}
+
+	t := reflect.TypeOf(Todo{})
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" || name == "id" || name == "updated_at" {
+			continue
+		}
+
+		var change Change
+		if before != nil {
+			change.Before = reflect.ValueOf(*before).Field(i).Interface()
+		}
+		if after != nil {
+			change.After = reflect.ValueOf(*after).Field(i).Interface()
+		}
+		if before != nil && after != nil && equal(change.Before, change.After) {
+			continue
+		}
+		changes[name] = change
+	}
+	return changes
+}
+
+// Compares field values. Times are equal when they represent the same instant.
+func equal(a, b any) bool {
+	if ta, ok := a.(*time.Time); ok {
+		if tb, ok := b.(*time.Time); ok {
+			return ta == tb || (ta != nil && tb != nil && ta.Equal(*tb))
+		}
+	}
+	if ta, ok := a.(time.Time); ok {
+		if tb, ok := b.(time.Time); ok {
+			return ta.Equal(tb)
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoHistory(t *testing.T) {
+	todo := createTodo(t)
+
+	todo.Title = "New title"
+	todo.Completion = 42
+	_, err := testQueries.UpdateOneTodo(testCtx, todo, testActor)
+	require.NoError(t, err)
+
+	// Saving unchanged Todo records nothing
+	_, err = testQueries.UpdateOneTodo(testCtx, todo, testActor)
+	require.NoError(t, err)
+
+	err = testQueries.DeleteOneTodo(testCtx, todo.Id, testActor)
+	require.NoError(t, err)
+
+	entries, total, err := testQueries.GetTodoHistory(testCtx, todo.Id, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+	require.Len(t, entries, 3)
+
+	require.Equal(t, OpCreate, entries[0].Operation)
+	require.Nil(t, entries[0].Changes["title"].Before)
+	require.Equal(t, "test_title", entries[0].Changes["title"].After)
+
+	require.Equal(t, OpUpdate, entries[1].Operation)
+	require.Len(t, entries[1].Changes, 2)
+	require.Equal(t, "test_title", entries[1].Changes["title"].Before)
+	require.Equal(t, "New title", entries[1].Changes["title"].After)
+	require.EqualValues(t, 0, entries[1].Changes["completion"].Before)
+	require.EqualValues(t, 42, entries[1].Changes["completion"].After)
+
+	require.Equal(t, OpDelete, entries[2].Operation)
+	require.Nil(t, entries[2].Changes["title"].After)
+
+	for _, entry := range entries {
+		require.Equal(t, todo.Id, entry.TodoId)
+		require.Equal(t, testActor, entry.Actor)
+		require.WithinDuration(t, time.Now(), entry.CreatedAt, time.Minute)
+	}
+
+	entries, total, err = testQueries.GetTodoHistory(testCtx, todo.Id, 1, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), total)
+	require.Len(t, entries, 1)
+	require.Equal(t, OpUpdate, entries[0].Operation)
+}
+
+func TestHistoryOfTodos(t *testing.T) {
+	first := createTodo(t)
+	second := createTodo(t)
+
+	first.Title = "New title"
+	_, err := testQueries.UpdateOneTodo(testCtx, first, testActor)
+	require.NoError(t, err)
+
+	entries, err := testQueries.GetHistoryOfTodos(testCtx, []int64{first.Id, second.Id})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, first.Id, entries[0].TodoId)
+	require.Equal(t, second.Id, entries[1].TodoId)
+	require.Equal(t, first.Id, entries[2].TodoId)
+	require.Equal(t, OpUpdate, entries[2].Operation)
+
+	entries, err = testQueries.GetHistoryOfTodos(testCtx, []int64{second.Id})
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestDiffTodos(t *testing.T) {
+	before := Todo{Id: 1, Title: "a", Expiry: time.Now()}
+	after := before
+	after.Title = "b"
+	after.Expiry = before.Expiry.UTC()
+
+	changes := diffTodos(&before, &after)
+	require.Equal(t, Changes{"title": {Before: "a", After: "b"}}, changes)
+
+	doneAt := time.Now()
+	before.DoneAt = &doneAt
+	after.DoneAt = &doneAt
+	after.Title = before.Title
+	require.Empty(t, diffTodos(&before, &after))
+
+	changes = diffTodos(nil, &after)
+	require.NotContains(t, changes, "id")
+	require.Equal(t, Change{Before: nil, After: "a"}, changes["title"])
+}
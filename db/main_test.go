@@ -1,6 +1,7 @@
 package db
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -12,6 +13,9 @@ import (
 
 var testQueries DB
 
+// Context of queries executed by tests
+var testCtx = context.Background()
+
 func TestMain(m *testing.M) {
 	dsn := fmt.Sprintf("postgres://%v:%v@%v:%v/%v?sslmode=disable",
 		"mock",
@@ -25,6 +29,14 @@ func TestMain(m *testing.M) {
 		log.Fatal("Cannot connect to db:", err)
 	}
 
+	migrator, err := NewMigrator(conn)
+	if err != nil {
+		log.Fatal("Cannot load migrations:", err)
+	}
+	if _, err := migrator.Up(testCtx); err != nil {
+		log.Fatal("Cannot migrate db:", err)
+	}
+
 	testQueries = New(conn)
 	os.Exit(m.Run())
 }
@@ -0,0 +1,307 @@
+package db
+
+import (
+	"context"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SQL files of all migrations named "<version>_<name>.<up|down>.sql".
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// Key of Postgres advisory lock held while migrating,
+// so many instances of the application do not migrate at once.
+const migrationLockKey = 7_262_531_034
+
+// Single versioned change of database schema.
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Migration together with time it was applied at.
+//
+// AppliedAt is nil for pending migrations.
+type MigrationStatus struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Applies and rolls back versioned migrations.
+//
+// Applied versions are stored in schema_migrations table.
+// Every migration runs in its own transaction.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// Returns Migrator with all migrations embedded in the application.
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+	}, nil
+}
+
+// Applies all pending migrations in order of versions and returns them.
+//
+// Stops at the first failed migration, leaving previous ones applied.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	var migrated []Migration
+	err := m.locked(ctx, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			if _, ok := applied[migration.Version]; ok {
+				continue
+			}
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Up).Error; err != nil {
+					return err
+				}
+				return tx.Exec("INSERT INTO schema_migrations (version, name) VALUES (?, ?)",
+					migration.Version, migration.Name).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			migrated = append(migrated, migration)
+		}
+		return nil
+	})
+	return migrated, err
+}
+
+// Rolls back given number of the most recently applied migrations
+// and returns them in order they were rolled back.
+//
+// Throws an error when applied migration is unknown to the application.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var migrated []Migration
+	err := m.locked(ctx, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		versions := make([]int64, 0, len(applied))
+		for version := range applied {
+			versions = append(versions, version)
+		}
+		sort.Slice(versions, func(i, j int) bool { return versions[i] > versions[j] })
+
+		for i := 0; i < steps && i < len(versions); i++ {
+			migration, ok := m.find(versions[i])
+			if !ok {
+				return fmt.Errorf("unknown migration version %d", versions[i])
+			}
+
+			err := conn.Transaction(func(tx *gorm.DB) error {
+				if err := tx.Exec(migration.Down).Error; err != nil {
+					return err
+				}
+				return tx.Exec("DELETE FROM schema_migrations WHERE version = ?", migration.Version).Error
+			})
+			if err != nil {
+				return fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+			}
+			migrated = append(migrated, migration)
+		}
+		return nil
+	})
+	return migrated, err
+}
+
+// Returns all migrations in order of versions together with time they were applied at.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	var statuses []MigrationStatus
+	err := m.locked(ctx, func(conn *gorm.DB) error {
+		applied, err := appliedMigrations(conn)
+		if err != nil {
+			return err
+		}
+
+		for _, migration := range m.migrations {
+			status := MigrationStatus{Migration: migration}
+			if appliedAt, ok := applied[migration.Version]; ok {
+				status.AppliedAt = &appliedAt
+			}
+			statuses = append(statuses, status)
+		}
+		return nil
+	})
+	return statuses, err
+}
+
+// Returns migrations not applied yet in order of versions.
+//
+// Unlike Status it does not wait for migration lock, so it can be called
+// while other instance migrates. All migrations are pending in empty database.
+func (m *Migrator) Pending(ctx context.Context) ([]Migration, error) {
+	conn := m.db.WithContext(ctx)
+
+	var exists bool
+	if err := conn.Raw("SELECT to_regclass('schema_migrations') IS NOT NULL").Scan(&exists).Error; err != nil {
+		return nil, err
+	}
+	if !exists {
+		return m.migrations, nil
+	}
+
+	applied, err := appliedMigrations(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var pending []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, migration)
+		}
+	}
+	return pending, nil
+}
+
+// Runs fn on a single connection holding migration lock
+// and makes sure schema_migrations table exists.
+//
+// Lock is released even when ctx is cancelled, otherwise the connection
+// would keep it after returning to the pool.
+func (m *Migrator) locked(ctx context.Context, fn func(*gorm.DB) error) error {
+	return m.db.WithContext(ctx).Connection(func(conn *gorm.DB) (err error) {
+		if err := conn.Exec("SELECT pg_advisory_lock(?)", migrationLockKey).Error; err != nil {
+			return err
+		}
+		defer func() {
+			if unlockErr := unlock(conn.WithContext(context.Background())); err == nil {
+				err = unlockErr
+			}
+		}()
+
+		err = conn.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+			version bigint PRIMARY KEY,
+			name text NOT NULL,
+			applied_at timestamptz NOT NULL DEFAULT now()
+		)`).Error
+		if err != nil {
+			return err
+		}
+		return fn(conn)
+	})
+}
+
+// Releases migration lock held by connection.
+func unlock(conn *gorm.DB) error {
+	var unlocked bool
+	if err := conn.Raw("SELECT pg_advisory_unlock(?)", migrationLockKey).Scan(&unlocked).Error; err != nil {
+		return fmt.Errorf("cannot release migration lock: %w", err)
+	}
+	if !unlocked {
+		return fmt.Errorf("cannot release migration lock: it was not held")
+	}
+	return nil
+}
+
+// Returns migration with given version.
+func (m *Migrator) find(version int64) (Migration, bool) {
+	for _, migration := range m.migrations {
+		if migration.Version == version {
+			return migration, true
+		}
+	}
+	return Migration{}, false
+}
+
+// Returns times of applied migrations by their versions.
+func appliedMigrations(conn *gorm.DB) (map[int64]time.Time, error) {
+	var rows []struct {
+		Version   int64
+		AppliedAt time.Time
+	}
+	err := conn.Raw("SELECT version, applied_at FROM schema_migrations").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]time.Time, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row.AppliedAt
+	}
+	return applied, nil
+}
+
+// Reads migrations from "<version>_<name>.<up|down>.sql" files in dir
+// and returns them in order of versions.
+//
+// Throws an error when file name is malformed, version is used
+// by different migrations or migration lacks one of its steps.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		file := entry.Name()
+		base := strings.TrimSuffix(file, ".sql")
+		step := path.Ext(base)
+		base = strings.TrimSuffix(base, step)
+
+		number, name, ok := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(number, 10, 64)
+		if !ok || err != nil || name == "" || (step != ".up" && step != ".down") {
+			return nil, fmt.Errorf("malformed migration file name %q", file)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("migration version %d is used by %q and %q", version, migration.Name, name)
+		}
+
+		content, err := fs.ReadFile(fsys, path.Join(dir, file))
+		if err != nil {
+			return nil, err
+		}
+		if step == ".up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s lacks up or down step", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
@@ -0,0 +1,96 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	migrations, err := loadMigrations(migrationFiles, "migrations")
+	require.NoError(t, err)
+	require.NotEmpty(t, migrations)
+
+	for i, migration := range migrations {
+		require.Equal(t, int64(i+1), migration.Version)
+		require.NotEmpty(t, migration.Name)
+		require.NotEmpty(t, migration.Up)
+		require.NotEmpty(t, migration.Down)
+	}
+
+	malformed := []fstest.MapFS{
+		{"migrations/create_todos.up.sql": {}},
+		{"migrations/0001_create_todos.sql": {}},
+		{"migrations/0001_create_todos.up.sql": {Data: []byte("up")}},
+		{
+			"migrations/0001_create_todos.up.sql":   {Data: []byte("up")},
+			"migrations/0001_create_todos.down.sql": {Data: []byte("down")},
+			"migrations/0001_other.up.sql":          {Data: []byte("up")},
+		},
+	}
+	for _, fsys := range malformed {
+		_, err := loadMigrations(fsys, "migrations")
+		require.Error(t, err)
+	}
+}
+
+func TestMigrator(t *testing.T) {
+	migrator, err := NewMigrator(testQueries.(*Queries).db)
+	require.NoError(t, err)
+
+	statuses, err := migrator.Status(testCtx)
+	require.NoError(t, err)
+	for _, status := range statuses {
+		require.NotNil(t, status.AppliedAt)
+	}
+	last := statuses[len(statuses)-1]
+
+	migrated, err := migrator.Up(testCtx)
+	require.NoError(t, err)
+	require.Empty(t, migrated)
+
+	migrated, err = migrator.Down(testCtx, 1)
+	require.NoError(t, err)
+	require.Len(t, migrated, 1)
+	require.Equal(t, last.Version, migrated[0].Version)
+
+	statuses, err = migrator.Status(testCtx)
+	require.NoError(t, err)
+	require.Nil(t, statuses[len(statuses)-1].AppliedAt)
+
+	pending, err := migrator.Pending(testCtx)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, last.Version, pending[0].Version)
+
+	migrated, err = migrator.Up(testCtx)
+	require.NoError(t, err)
+	require.Len(t, migrated, 1)
+	require.Equal(t, last.Version, migrated[0].Version)
+
+	pending, err = migrator.Pending(testCtx)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+}
+
+func TestMigratorLockCancelled(t *testing.T) {
+	conn := testQueries.(*Queries).db
+	migrator, err := NewMigrator(conn)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(testCtx)
+	err = migrator.locked(ctx, func(*gorm.DB) error {
+		cancel()
+		return nil
+	})
+	require.NoError(t, err)
+
+	// No connection of the pool keeps the lock
+	var locks int64
+	err = conn.Raw("SELECT count(*) FROM pg_locks WHERE locktype = 'advisory'").Scan(&locks).Error
+	require.NoError(t, err)
+	require.Zero(t, locks)
+}
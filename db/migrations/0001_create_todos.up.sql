@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS todos (
+    id bigserial PRIMARY KEY,
+    title text NOT NULL,
+    description text NOT NULL,
+    completion decimal NOT NULL,
+    expiry timestamptz NOT NULL,
+    is_done boolean
+);
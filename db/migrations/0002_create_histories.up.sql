@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS histories (
+    id bigserial PRIMARY KEY,
+    todo_id bigint NOT NULL,
+    actor text NOT NULL,
+    operation text NOT NULL,
+    changes jsonb NOT NULL,
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_histories_todo_id ON histories (todo_id);
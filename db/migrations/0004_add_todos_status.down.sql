@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS transitions;
+
+ALTER TABLE todos
+    DROP COLUMN IF EXISTS done_at,
+    DROP COLUMN IF EXISTS status;
@@ -0,0 +1,17 @@
+ALTER TABLE todos
+    ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'backlog',
+    ADD COLUMN IF NOT EXISTS done_at timestamptz;
+
+-- Todos finished before statuses were introduced
+UPDATE todos SET status = 'done' WHERE is_done AND status <> 'done';
+
+CREATE TABLE IF NOT EXISTS transitions (
+    id bigserial PRIMARY KEY,
+    todo_id bigint NOT NULL,
+    "from" text NOT NULL,
+    "to" text NOT NULL,
+    actor text NOT NULL,
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_transitions_todo_id ON transitions (todo_id);
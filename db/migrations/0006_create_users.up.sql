@@ -0,0 +1,11 @@
+CREATE TABLE users (
+    id bigserial PRIMARY KEY,
+    name text NOT NULL,
+    key_hash text NOT NULL,
+    disabled_at timestamptz,
+    created_at timestamptz NOT NULL
+);
+
+CREATE UNIQUE INDEX idx_users_name ON users (name);
+
+CREATE UNIQUE INDEX idx_users_key_hash ON users (key_hash);
@@ -0,0 +1,2 @@
+-- Existing Todos get time of the migration as their last change
+ALTER TABLE todos ADD COLUMN IF NOT EXISTS updated_at timestamptz NOT NULL DEFAULT now();
@@ -1,22 +1,38 @@
 package db
 
 import (
+	"context"
+
 	"gorm.io/gorm"
 )
 
 // Implements DB interface
 type Queries struct {
 	db *gorm.DB
+
+	// Set for Queries running within transaction started by WithTx
+	tx bool
 }
 
 // Returns object that implements DB interface
 //
-// Meanwhile migrates all ORM models
+// Schema must be already migrated with Migrator.
 func New(db *gorm.DB) DB {
-	if db != nil {
-		db.AutoMigrate(&Todo{})
-	}
 	return &Queries{
 		db: db,
 	}
 }
+
+// Runs fn within a single database transaction bound to ctx.
+//
+// DB passed to fn executes all its queries in that transaction
+// and GetOneTodoById locks returned Todo till the end of it,
+// so read-modify-write operations are not interleaved with other ones.
+//
+// Transaction is committed when fn returns nil and rolled back otherwise
+// or when ctx is done. Nested calls use savepoints.
+func (q *Queries) WithTx(ctx context.Context, fn func(DB) error) error {
+	return q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return fn(&Queries{db: tx, tx: true})
+	})
+}
@@ -0,0 +1,53 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithTx(t *testing.T) {
+	todo := createTodo(t)
+	errFailed := errors.New("failed")
+
+	err := testQueries.WithTx(testCtx, func(tx DB) error {
+		todo.Title = "Rolled back title"
+		_, err := tx.UpdateOneTodo(testCtx, todo, testActor)
+		require.NoError(t, err)
+
+		lockedTodo, err := tx.GetOneTodoById(testCtx, todo.Id)
+		require.NoError(t, err)
+		require.Equal(t, todo.Title, lockedTodo.Title)
+
+		err = tx.DeleteOneTodo(testCtx, todo.Id, testActor)
+		require.NoError(t, err)
+		return errFailed
+	})
+	require.ErrorIs(t, err, errFailed)
+
+	recievedTodo, err := testQueries.GetOneTodoById(testCtx, todo.Id)
+	require.NoError(t, err)
+	require.Equal(t, "test_title", recievedTodo.Title)
+
+	_, total, err := testQueries.GetTodoHistory(testCtx, todo.Id, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), total)
+
+	err = testQueries.WithTx(testCtx, func(tx DB) error {
+		return tx.DeleteOneTodo(testCtx, todo.Id, testActor)
+	})
+	require.NoError(t, err)
+
+	_, err = testQueries.GetOneTodoById(testCtx, todo.Id)
+	require.Error(t, err)
+}
+
+func TestCancelledContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(testCtx)
+	cancel()
+
+	_, err := testQueries.GetAllTodos(ctx)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,27 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+// Numbers of Todos excluding archived ones and those in trash.
+type TodoCounts struct {
+	Open    int64
+	Overdue int64
+	Done    int64
+}
+
+// Returns numbers of open, overdue and done Todos, excluding archived ones and those in trash.
+//
+// Open Todos are not done and overdue ones are open Todos expired before now.
+func (q *Queries) CountTodos(ctx context.Context, now time.Time) (TodoCounts, error) {
+	var counts TodoCounts
+	result := q.db.WithContext(ctx).Model(&Todo{}).
+		Select(`COUNT(*) FILTER (WHERE is_done IS NOT TRUE) AS open,
+			COUNT(*) FILTER (WHERE is_done IS NOT TRUE AND expiry < ?) AS overdue,
+			COUNT(*) FILTER (WHERE is_done) AS done`, now).
+		Where("archived_at IS NULL").
+		Scan(&counts)
+	return counts, result.Error
+}
@@ -0,0 +1,36 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountTodos(t *testing.T) {
+	// New Todos expire now, so they are not overdue yet an hour ago
+	past := time.Now().Add(-time.Hour)
+	before, err := testQueries.CountTodos(testCtx, past)
+	require.NoError(t, err)
+
+	open := createTodo(t)
+
+	done := createTodo(t)
+	done.IsDone = true
+	done.Status = "done"
+	_, err = testQueries.UpdateOneTodo(testCtx, done, testActor)
+	require.NoError(t, err)
+
+	trashed := createTodo(t)
+	require.NoError(t, testQueries.DeleteOneTodo(testCtx, trashed.Id, testActor))
+
+	counts, err := testQueries.CountTodos(testCtx, past)
+	require.NoError(t, err)
+	require.Equal(t, before.Open+1, counts.Open)
+	require.Equal(t, before.Overdue, counts.Overdue)
+	require.Equal(t, before.Done+1, counts.Done)
+
+	counts, err = testQueries.CountTodos(testCtx, open.Expiry.Add(time.Minute))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, counts.Overdue, before.Overdue+1)
+}
@@ -1,8 +1,12 @@
 package db
 
 import (
+	"context"
 	"errors"
 	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // Data struct for CreateOneTodo.
@@ -10,60 +14,176 @@ type CreateTodoParams struct {
 	Title       string    `json:"title"`
 	Description string    `json:"description"`
 	Expiry      time.Time `json:"expiry"`
+	Status      string    `json:"status"`
 }
 
-// Returns all Todos from database
-func (q *Queries) GetAllTodos() ([]Todo, error) {
+// Returns all Todos from database except archived ones
+func (q *Queries) GetAllTodos(ctx context.Context) ([]Todo, error) {
 	var todos []Todo
-	result := q.db.Find(&todos)
+	result := q.db.WithContext(ctx).Where("archived_at IS NULL").Find(&todos)
 	return todos, result.Error
 }
 
 // Inserts single Todo to database.
 //
 // Sets completion at 0.0 and marks Todo as unfinished.
-func (q *Queries) CreateOneTodo(params CreateTodoParams) (Todo, error) {
+//
+// Records creation and initial status in Todo's history on behalf of actor.
+func (q *Queries) CreateOneTodo(ctx context.Context, params CreateTodoParams, actor string) (Todo, error) {
 	todo := Todo{
 		Title:       params.Title,
 		Description: params.Description,
 		Expiry:      params.Expiry,
 		IsDone:      false,
 		Completion:  0,
+		Status:      params.Status,
 	}
-	result := q.db.Create(&todo)
-	return todo, result.Error
+	err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&todo).Error; err != nil {
+			return err
+		}
+		if err := recordTransition(tx, todo.Id, actor, "", todo.Status); err != nil {
+			return err
+		}
+		return record(tx, todo.Id, actor, OpCreate, diffTodos(nil, &todo))
+	})
+	return todo, err
 }
 
-// Returns slice of unfinished Todos from database between two terms of time.
-func (q *Queries) GetManyTodos(startDate, endDate time.Time) ([]Todo, error) {
+// Returns slice of unfinished and not archived Todos from database between two terms of time.
+func (q *Queries) GetManyTodos(ctx context.Context, startDate, endDate time.Time) ([]Todo, error) {
 	var todos []Todo
-	result := q.db.Where("(expiry BETWEEN ? AND ?) AND NOT is_done", startDate, endDate).Find(&todos)
+	result := q.db.WithContext(ctx).Where("(expiry BETWEEN ? AND ?) AND NOT is_done AND archived_at IS NULL", startDate, endDate).Find(&todos)
 	return todos, result.Error
 }
 
 // Returns single Todo for given Id.
 //
 // Throws en error when not found in database.
-func (q *Queries) GetOneTodoById(id int64) (Todo, error) {
-	todo := Todo{Id: id}
-	result := q.db.First(&todo)
-	if result.RowsAffected == 0 {
-		return todo, errors.New("not found")
+//
+// Within WithTx Todo stays locked for update till the end of transaction.
+func (q *Queries) GetOneTodoById(ctx context.Context, id int64) (Todo, error) {
+	if q.tx {
+		return lockTodo(q.db.WithContext(ctx), id)
 	}
-	return todo, result.Error
+
+	todo := Todo{Id: id}
+	result := q.db.WithContext(ctx).First(&todo)
+	return todo, findError(result)
 }
 
 // Updates existing Todo
-func (q *Queries) UpdateOneTodo(todo Todo) (Todo, error) {
-	result := q.db.Save(&todo)
-	return todo, result.Error
+//
+// Sets DoneAt when Todo gets finished, unless it is given, and clears it when Todo gets reopened.
+//
+// Records changed fields and status transition in Todo's history on behalf of actor.
+// Nothing is recorded when Todo did not change.
+func (q *Queries) UpdateOneTodo(ctx context.Context, todo Todo, actor string) (Todo, error) {
+	err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		before, err := lockTodo(tx, todo.Id)
+		if err != nil {
+			return err
+		}
+
+		if todo.IsDone && !before.IsDone && todo.DoneAt == nil {
+			now := time.Now()
+			todo.DoneAt = &now
+		} else if !todo.IsDone {
+			todo.DoneAt = nil
+		}
+
+		if err := tx.Save(&todo).Error; err != nil {
+			return err
+		}
+
+		if before.Status != todo.Status {
+			err := recordTransition(tx, todo.Id, actor, before.Status, todo.Status)
+			if err != nil {
+				return err
+			}
+		}
+
+		changes := diffTodos(&before, &todo)
+		if len(changes) == 0 {
+			return nil
+		}
+		return record(tx, todo.Id, actor, OpUpdate, changes)
+	})
+	return todo, err
+}
+
+// Moves Todo with given Id to trash.
+//
+// Records deletion in Todo's history on behalf of actor.
+func (q *Queries) DeleteOneTodo(ctx context.Context, id int64, actor string) error {
+	return q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		todo, err := lockTodo(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&todo).Error; err != nil {
+			return err
+		}
+		return record(tx, id, actor, OpDelete, diffTodos(&todo, nil))
+	})
+}
+
+// Returns page of Todo's history entries from the oldest one
+// and total number of entries.
+func (q *Queries) GetTodoHistory(ctx context.Context, todoId int64, limit, offset int) ([]History, int64, error) {
+	var total int64
+	result := q.db.WithContext(ctx).Model(&History{}).Where("todo_id = ?", todoId).Count(&total)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	var entries []History
+	result = q.db.WithContext(ctx).Where("todo_id = ?", todoId).
+		Order("id").
+		Limit(limit).
+		Offset(offset).
+		Find(&entries)
+	return entries, total, result.Error
+}
+
+// Returns history entries of all given Todos ordered from the oldest one.
+func (q *Queries) GetHistoryOfTodos(ctx context.Context, todoIds []int64) ([]History, error) {
+	var entries []History
+	result := q.db.WithContext(ctx).Where("todo_id IN ?", todoIds).Order("id").Find(&entries)
+	return entries, result.Error
+}
+
+// Returns Todo with given Id locked for update till the end of transaction.
+//
+// Throws an error when not found in database.
+func lockTodo(tx *gorm.DB, id int64) (Todo, error) {
+	todo := Todo{Id: id}
+	result := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&todo)
+	return todo, findError(result)
 }
 
-// Deletes Todo with given Id
-func (q *Queries) DeleteOneTodo(id int64) error {
-	result := q.db.Delete(&Todo{}, id)
+// Returns error of query finding single Todo, "not found" when there is none.
+// Other errors, e.g. of cancelled queries, are returned unchanged.
+func findError(result *gorm.DB) error {
+	if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+		return errors.New("not found")
+	}
+	if result.Error != nil {
+		return result.Error
+	}
 	if result.RowsAffected == 0 {
 		return errors.New("not found")
 	}
-	return result.Error
+	return nil
+}
+
+// Appends single entry to Todo's history within given transaction.
+func record(tx *gorm.DB, todoId int64, actor, operation string, changes Changes) error {
+	return tx.Create(&History{
+		TodoId:    todoId,
+		Actor:     actor,
+		Operation: operation,
+		Changes:   changes,
+	}).Error
 }
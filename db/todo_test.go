@@ -1,18 +1,22 @@
 package db
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/require"
 )
 
+const testActor = "test_actor"
+
 func createTodo(t *testing.T) Todo {
-	todo, err := testQueries.CreateOneTodo(CreateTodoParams{
+	todo, err := testQueries.CreateOneTodo(testCtx, CreateTodoParams{
 		Title:       "test_title",
 		Description: "test_desc",
 		Expiry:      time.Now(),
-	})
+		Status:      "backlog",
+	}, testActor)
 	require.NoError(t, err)
 	require.NotEmpty(t, todo)
 	return todo
@@ -27,7 +31,7 @@ func TestGetMany(t *testing.T) {
 		_ = createTodo(t)
 	}
 
-	todos, err := testQueries.GetAllTodos()
+	todos, err := testQueries.GetAllTodos(testCtx)
 	require.NoError(t, err)
 	require.NotEmpty(t, todos)
 	require.GreaterOrEqual(t, len(todos), 10)
@@ -46,7 +50,7 @@ func TestUpdateTodo(t *testing.T) {
 	todo.Completion = 21.37
 	todo.IsDone = true
 
-	updatedTodo, err := testQueries.UpdateOneTodo(todo)
+	updatedTodo, err := testQueries.UpdateOneTodo(testCtx, todo, testActor)
 	require.NoError(t, err)
 	require.NotEmpty(t, updatedTodo)
 
@@ -56,22 +60,38 @@ func TestUpdateTodo(t *testing.T) {
 	require.Equal(t, todo.IsDone, updatedTodo.IsDone)
 	require.Equal(t, todo.Completion, updatedTodo.Completion)
 	require.WithinDuration(t, todo.Expiry, updatedTodo.Expiry, time.Second)
+	require.NotNil(t, updatedTodo.DoneAt)
+	require.WithinDuration(t, time.Now(), *updatedTodo.DoneAt, time.Second)
+}
+
+func TestUpdateTodoDoneAt(t *testing.T) {
+	todo := createTodo(t)
+
+	// Given completion time is kept, e.g. of imported Todos
+	doneAt := time.Now().AddDate(0, -3, 0)
+	todo.IsDone = true
+	todo.DoneAt = &doneAt
+
+	updatedTodo, err := testQueries.UpdateOneTodo(testCtx, todo, testActor)
+	require.NoError(t, err)
+	require.NotNil(t, updatedTodo.DoneAt)
+	require.WithinDuration(t, doneAt, *updatedTodo.DoneAt, time.Second)
 }
 
 func TestDeleteTodo(t *testing.T) {
 	todo := createTodo(t)
 
-	err := testQueries.DeleteOneTodo(todo.Id)
+	err := testQueries.DeleteOneTodo(testCtx, todo.Id, testActor)
 	require.NoError(t, err)
 
-	err = testQueries.DeleteOneTodo(todo.Id)
+	err = testQueries.DeleteOneTodo(testCtx, todo.Id, testActor)
 	require.Error(t, err)
 }
 
 func TestGetOneById(t *testing.T) {
 	todo := createTodo(t)
 
-	recievedTodo, err := testQueries.GetOneTodoById(todo.Id)
+	recievedTodo, err := testQueries.GetOneTodoById(testCtx, todo.Id)
 	require.NoError(t, err)
 	require.NotEmpty(t, recievedTodo)
 
@@ -82,12 +102,17 @@ func TestGetOneById(t *testing.T) {
 	require.Equal(t, todo.IsDone, recievedTodo.IsDone)
 	require.WithinDuration(t, todo.Expiry, recievedTodo.Expiry, time.Second)
 
-	err = testQueries.DeleteOneTodo(todo.Id)
+	err = testQueries.DeleteOneTodo(testCtx, todo.Id, testActor)
 	require.NoError(t, err)
 
-	recievedTodo, err = testQueries.GetOneTodoById(todo.Id)
-	require.Error(t, err)
+	recievedTodo, err = testQueries.GetOneTodoById(testCtx, todo.Id)
+	require.EqualError(t, err, "not found")
 
+	// Cancelled queries are not reported as missing Todos
+	ctx, cancel := context.WithCancel(testCtx)
+	cancel()
+	_, err = testQueries.GetOneTodoById(ctx, todo.Id)
+	require.ErrorIs(t, err, context.Canceled)
 }
 
 func TestGetManyTodos(t *testing.T) {
@@ -97,15 +122,15 @@ func TestGetManyTodos(t *testing.T) {
 	todo1.Expiry = time.Now().AddDate(0, 0, 1)
 	todo2.Expiry = time.Now().AddDate(0, 0, 2)
 
-	_, err := testQueries.UpdateOneTodo(todo1)
+	_, err := testQueries.UpdateOneTodo(testCtx, todo1, testActor)
 	require.NoError(t, err)
 
-	_, err = testQueries.UpdateOneTodo(todo2)
+	_, err = testQueries.UpdateOneTodo(testCtx, todo2, testActor)
 	require.NoError(t, err)
 
 	startDate := time.Now()
 	endDate := time.Now().AddDate(0, 0, 5)
-	todos, err := testQueries.GetManyTodos(startDate, endDate)
+	todos, err := testQueries.GetManyTodos(testCtx, startDate, endDate)
 	require.NoError(t, err)
 	require.NotEmpty(t, todos)
 	require.Greater(t, len(todos), 0)
@@ -0,0 +1,45 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Transition ORM model structure.
+//
+// Records single change of Todo's status. Creation of Todo is recorded
+// as transition from empty status to the initial one.
+type Transition struct {
+	Id        int64     `json:"id" gorm:"primaryKey"`
+	TodoId    int64     `json:"todo_id" gorm:"not null;index"`
+	From      string    `json:"from" gorm:"not null"`
+	To        string    `json:"to" gorm:"not null"`
+	Actor     string    `json:"actor" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"not null"`
+}
+
+// Returns all status transitions of Todo with given Id from the oldest one.
+func (q *Queries) GetTodoTransitions(ctx context.Context, todoId int64) ([]Transition, error) {
+	var transitions []Transition
+	result := q.db.WithContext(ctx).Where("todo_id = ?", todoId).Order("id").Find(&transitions)
+	return transitions, result.Error
+}
+
+// Appends single status transition within given transaction.
+func recordTransition(tx *gorm.DB, todoId int64, actor, from, to string) error {
+	return tx.Create(&Transition{
+		TodoId: todoId,
+		From:   from,
+		To:     to,
+		Actor:  actor,
+	}).Error
+}
+
+// Returns status transitions of all given Todos ordered from the oldest one.
+func (q *Queries) GetTransitionsOfTodos(ctx context.Context, todoIds []int64) ([]Transition, error) {
+	var transitions []Transition
+	result := q.db.WithContext(ctx).Where("todo_id IN ?", todoIds).Order("id").Find(&transitions)
+	return transitions, result.Error
+}
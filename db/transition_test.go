@@ -0,0 +1,62 @@
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTodoTransitions(t *testing.T) {
+	todo := createTodo(t)
+
+	todo.Status = "done"
+	todo.IsDone = true
+	updatedTodo, err := testQueries.UpdateOneTodo(testCtx, todo, testActor)
+	require.NoError(t, err)
+	require.NotNil(t, updatedTodo.DoneAt)
+
+	// Changes of other fields are not transitions
+	updatedTodo.Title = "New title"
+	_, err = testQueries.UpdateOneTodo(testCtx, updatedTodo, testActor)
+	require.NoError(t, err)
+
+	updatedTodo.Status = "reopened"
+	updatedTodo.IsDone = false
+	updatedTodo, err = testQueries.UpdateOneTodo(testCtx, updatedTodo, testActor)
+	require.NoError(t, err)
+	require.Nil(t, updatedTodo.DoneAt)
+
+	transitions, err := testQueries.GetTodoTransitions(testCtx, todo.Id)
+	require.NoError(t, err)
+	require.Len(t, transitions, 3)
+
+	require.Equal(t, "", transitions[0].From)
+	require.Equal(t, "backlog", transitions[0].To)
+	require.Equal(t, "backlog", transitions[1].From)
+	require.Equal(t, "done", transitions[1].To)
+	require.Equal(t, "done", transitions[2].From)
+	require.Equal(t, "reopened", transitions[2].To)
+
+	for i := range transitions {
+		require.Equal(t, testActor, transitions[i].Actor)
+		require.False(t, transitions[i].CreatedAt.IsZero())
+	}
+}
+
+func TestTransitionsOfTodos(t *testing.T) {
+	first := createTodo(t)
+	second := createTodo(t)
+
+	second.Status = "done"
+	second.IsDone = true
+	_, err := testQueries.UpdateOneTodo(testCtx, second, testActor)
+	require.NoError(t, err)
+
+	transitions, err := testQueries.GetTransitionsOfTodos(testCtx, []int64{first.Id, second.Id})
+	require.NoError(t, err)
+	require.Len(t, transitions, 3)
+	require.Equal(t, first.Id, transitions[0].TodoId)
+	require.Equal(t, second.Id, transitions[1].TodoId)
+	require.Equal(t, second.Id, transitions[2].TodoId)
+	require.Equal(t, "done", transitions[2].To)
+}
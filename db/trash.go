@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Returns all Todos from trash, most recently deleted first.
+func (q *Queries) GetTrashedTodos(ctx context.Context) ([]Todo, error) {
+	var todos []Todo
+	result := q.db.WithContext(ctx).Unscoped().
+		Where("deleted_at IS NOT NULL").
+		Order("deleted_at DESC").
+		Find(&todos)
+	return todos, result.Error
+}
+
+// Moves Todo with given Id out of trash.
+//
+// Throws an error when Todo is not in trash.
+//
+// Records restoration in Todo's history on behalf of actor.
+func (q *Queries) RestoreOneTodo(ctx context.Context, id int64, actor string) (Todo, error) {
+	var todo Todo
+	err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		before, err := lockTrashedTodo(tx, id)
+		if err != nil {
+			return err
+		}
+
+		todo = before
+		todo.DeletedAt = gorm.DeletedAt{}
+		if err := tx.Unscoped().Save(&todo).Error; err != nil {
+			return err
+		}
+		return record(tx, id, actor, OpRestore, diffTodos(&before, &todo))
+	})
+	return todo, err
+}
+
+// Permanently removes Todo with given Id from trash.
+//
+// Throws an error when Todo is not in trash.
+//
+// Records purge in Todo's history on behalf of actor.
+func (q *Queries) PurgeOneTodo(ctx context.Context, id int64, actor string) error {
+	return q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		todo, err := lockTrashedTodo(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Delete(&todo).Error; err != nil {
+			return err
+		}
+		return record(tx, id, actor, OpPurge, diffTodos(&todo, nil))
+	})
+}
+
+// Permanently removes all Todos moved to trash before given time
+// and returns how many were removed.
+//
+// Records purge in history of every removed Todo on behalf of actor.
+func (q *Queries) PurgeTrash(ctx context.Context, before time.Time, actor string) (int64, error) {
+	var todos []Todo
+	err := q.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Unscoped().
+			Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at < ?", before).
+			Find(&todos)
+		if result.Error != nil || len(todos) == 0 {
+			return result.Error
+		}
+
+		ids := make([]int64, len(todos))
+		entries := make([]History, len(todos))
+		for i := range todos {
+			ids[i] = todos[i].Id
+			entries[i] = History{
+				TodoId:    todos[i].Id,
+				Actor:     actor,
+				Operation: OpPurge,
+				Changes:   diffTodos(&todos[i], nil),
+			}
+		}
+
+		if err := tx.Unscoped().Delete(&Todo{}, ids).Error; err != nil {
+			return err
+		}
+		return tx.Create(&entries).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(todos)), nil
+}
+
+// Returns trashed Todo with given Id locked for update till the end of transaction.
+//
+// Throws an error when not found in trash.
+func lockTrashedTodo(tx *gorm.DB, id int64) (Todo, error) {
+	todo := Todo{Id: id}
+	result := tx.Unscoped().
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("deleted_at IS NOT NULL").
+		First(&todo)
+	return todo, findError(result)
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRestoreTodo(t *testing.T) {
+	todo := createTodo(t)
+
+	_, err := testQueries.RestoreOneTodo(testCtx, todo.Id, testActor)
+	require.Error(t, err)
+
+	err = testQueries.DeleteOneTodo(testCtx, todo.Id, testActor)
+	require.NoError(t, err)
+
+	trash, err := testQueries.GetTrashedTodos(testCtx)
+	require.NoError(t, err)
+	require.Equal(t, todo.Id, trash[0].Id)
+	require.True(t, trash[0].DeletedAt.Valid)
+
+	restoredTodo, err := testQueries.RestoreOneTodo(testCtx, todo.Id, testActor)
+	require.NoError(t, err)
+	require.Equal(t, todo.Id, restoredTodo.Id)
+	require.False(t, restoredTodo.DeletedAt.Valid)
+
+	recievedTodo, err := testQueries.GetOneTodoById(testCtx, todo.Id)
+	require.NoError(t, err)
+	require.Equal(t, todo.Title, recievedTodo.Title)
+
+	entries, _, err := testQueries.GetTodoHistory(testCtx, todo.Id, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, OpRestore, entries[len(entries)-1].Operation)
+}
+
+func TestPurgeTodo(t *testing.T) {
+	todo := createTodo(t)
+
+	// Todos must be moved to trash first
+	err := testQueries.PurgeOneTodo(testCtx, todo.Id, testActor)
+	require.Error(t, err)
+
+	err = testQueries.DeleteOneTodo(testCtx, todo.Id, testActor)
+	require.NoError(t, err)
+
+	err = testQueries.PurgeOneTodo(testCtx, todo.Id, testActor)
+	require.NoError(t, err)
+
+	_, err = testQueries.RestoreOneTodo(testCtx, todo.Id, testActor)
+	require.Error(t, err)
+
+	entries, _, err := testQueries.GetTodoHistory(testCtx, todo.Id, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, OpPurge, entries[len(entries)-1].Operation)
+}
+
+func TestPurgeTrash(t *testing.T) {
+	todo1 := createTodo(t)
+	todo2 := createTodo(t)
+
+	err := testQueries.DeleteOneTodo(testCtx, todo1.Id, testActor)
+	require.NoError(t, err)
+
+	purged, err := testQueries.PurgeTrash(testCtx, time.Now().Add(-time.Hour), testActor)
+	require.NoError(t, err)
+	require.Zero(t, purged)
+
+	purged, err = testQueries.PurgeTrash(testCtx, time.Now().Add(time.Minute), testActor)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, purged, int64(1))
+
+	_, err = testQueries.RestoreOneTodo(testCtx, todo1.Id, testActor)
+	require.Error(t, err)
+
+	// Todos outside of trash are untouched
+	_, err = testQueries.GetOneTodoById(testCtx, todo2.Id)
+	require.NoError(t, err)
+}
@@ -0,0 +1,67 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// User ORM model structure.
+//
+// Users authenticate with API key, of which only the hash is stored.
+type User struct {
+	Id         int64      `json:"id" gorm:"primaryKey"`
+	Name       string     `json:"name" gorm:"not null;uniqueIndex"`
+	KeyHash    string     `json:"-" gorm:"not null;uniqueIndex"`
+	DisabledAt *time.Time `json:"disabled_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"not null"`
+}
+
+// Inserts single User with given name and hash of API key to database.
+//
+// Throws an error when name or key is already taken.
+func (q *Queries) CreateUser(ctx context.Context, name, keyHash string) (User, error) {
+	user := User{
+		Name:    name,
+		KeyHash: keyHash,
+	}
+	result := q.db.WithContext(ctx).Create(&user)
+	return user, result.Error
+}
+
+// Returns single User for given hash of API key, including disabled one.
+//
+// Throws an error when not found in database.
+func (q *Queries) GetUserByKeyHash(ctx context.Context, keyHash string) (User, error) {
+	var user User
+	result := q.db.WithContext(ctx).Where("key_hash = ?", keyHash).Limit(1).Find(&user)
+	if result.Error != nil {
+		return user, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return user, errors.New("not found")
+	}
+	return user, nil
+}
+
+// Disables User with given name, so its API key is no longer accepted.
+//
+// Throws an error when not found in database.
+func (q *Queries) DisableUser(ctx context.Context, name string) (User, error) {
+	var user User
+	result := q.db.WithContext(ctx).Where("name = ?", name).Limit(1).Find(&user)
+	if result.Error != nil {
+		return user, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return user, errors.New("not found")
+	}
+	if user.DisabledAt != nil {
+		return user, nil
+	}
+
+	now := time.Now()
+	user.DisabledAt = &now
+	result = q.db.WithContext(ctx).Model(&user).Update("disabled_at", now)
+	return user, result.Error
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUser(t *testing.T) {
+	name := fmt.Sprintf("user_%d", time.Now().UnixNano())
+	keyHash := name + "_hash"
+
+	user, err := testQueries.CreateUser(testCtx, name, keyHash)
+	require.NoError(t, err)
+	require.NotZero(t, user.Id)
+	require.Nil(t, user.DisabledAt)
+
+	_, err = testQueries.CreateUser(testCtx, name, "other_hash")
+	require.Error(t, err)
+
+	recievedUser, err := testQueries.GetUserByKeyHash(testCtx, keyHash)
+	require.NoError(t, err)
+	require.Equal(t, user.Id, recievedUser.Id)
+
+	_, err = testQueries.GetUserByKeyHash(testCtx, "unknown_hash")
+	require.EqualError(t, err, "not found")
+
+	disabledUser, err := testQueries.DisableUser(testCtx, name)
+	require.NoError(t, err)
+	require.NotNil(t, disabledUser.DisabledAt)
+
+	recievedUser, err = testQueries.GetUserByKeyHash(testCtx, keyHash)
+	require.NoError(t, err)
+	require.NotNil(t, recievedUser.DisabledAt)
+
+	_, err = testQueries.DisableUser(testCtx, "unknown_user")
+	require.EqualError(t, err, "not found")
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/workflow"
+)
+
+// Writes all Todos except trashed ones as JSON array to stdout or given file.
+//
+//	export [-o file]
+func exportTodos(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("o", "", "file to write todos to instead of stdout")
+	flags.Parse(args)
+
+	conn, err := openDB()
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		file, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		w = file
+	}
+
+	count, err := writeTodos(context.Background(), db.New(conn), w)
+	if err != nil {
+		return err
+	}
+
+	if *output != "" {
+		fmt.Printf("Exported %d todos\n", count)
+	}
+	return nil
+}
+
+// Writes all Todos except trashed ones as JSON array to w and returns their number.
+func writeTodos(ctx context.Context, queries db.DB, w io.Writer) (int, error) {
+	todos, err := queries.GetAllTodos(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("cannot get todos: %w", err)
+	}
+	archived, err := queries.GetArchivedTodos(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("cannot get archived todos: %w", err)
+	}
+	todos = append(todos, archived...)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(todos); err != nil {
+		return 0, fmt.Errorf("cannot write todos: %w", err)
+	}
+	return len(todos), nil
+}
+
+// Creates Todos from JSON array written by export command, read from stdin or given file.
+//
+// Todos get new Ids. Their statuses, completion progress and time, and archivization
+// are kept. Either all Todos are imported or none of them.
+//
+//	import [file]
+func importTodos(args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	flags.Parse(args)
+
+	var r io.Reader = os.Stdin
+	if file := flags.Arg(0); file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	machine, err := loadWorkflow()
+	if err != nil {
+		return err
+	}
+	todos, err := readTodos(r, machine)
+	if err != nil {
+		return err
+	}
+
+	conn, err := openDB()
+	if err != nil {
+		return err
+	}
+	if err := createTodos(context.Background(), db.New(conn), machine, todos); err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported %d todos\n", len(todos))
+	return nil
+}
+
+// Returns Todos of JSON array written by export command,
+// checking they have titles, descriptions and known statuses.
+func readTodos(r io.Reader, machine *workflow.Machine) ([]db.Todo, error) {
+	var todos []db.Todo
+	if err := json.NewDecoder(r).Decode(&todos); err != nil {
+		return nil, fmt.Errorf("cannot read todos: %w", err)
+	}
+
+	for i, todo := range todos {
+		if todo.Title == "" || todo.Description == "" {
+			return nil, fmt.Errorf("todo %d: title and description are required", i)
+		}
+		if todo.Status != "" && !machine.Valid(todo.Status) {
+			return nil, fmt.Errorf("todo %d: unknown status %q", i, todo.Status)
+		}
+	}
+	return todos, nil
+}
+
+// Creates copies of Todos in a single transaction.
+func createTodos(ctx context.Context, queries db.DB, machine *workflow.Machine, todos []db.Todo) error {
+	err := queries.WithTx(ctx, func(tx db.DB) error {
+		for _, todo := range todos {
+			// Todos exported before statuses were introduced have none
+			status := todo.Status
+			if status == "" && todo.IsDone {
+				status = workflow.Done
+			} else if status == "" {
+				status = machine.Initial()
+			}
+
+			created, err := tx.CreateOneTodo(ctx, db.CreateTodoParams{
+				Title:       todo.Title,
+				Description: todo.Description,
+				Expiry:      todo.Expiry,
+				Status:      status,
+			}, cliActor)
+			if err != nil {
+				return err
+			}
+
+			if todo.Completion == 0 && !todo.IsDone && todo.ArchivedAt == nil {
+				continue
+			}
+			created.Completion = todo.Completion
+			created.IsDone = todo.IsDone
+			created.DoneAt = todo.DoneAt
+			created.ArchivedAt = todo.ArchivedAt
+			if _, err := tx.UpdateOneTodo(ctx, created, cliActor); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot import todos: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/mock"
+	"github.com/vilderxyz/todos/workflow"
+)
+
+func TestExportImport(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	expiry := time.Date(2022, 6, 1, 0, 0, 0, 0, time.UTC)
+	doneAt := time.Date(2022, 3, 1, 12, 30, 0, 0, time.UTC)
+	archivedAt := time.Date(2022, 5, 1, 8, 0, 0, 0, time.UTC)
+	open := db.Todo{Id: 1, Title: "Clean house", Description: "Kitchen", Expiry: expiry, Status: workflow.Backlog}
+	progress := db.Todo{Id: 2, Title: "Wash car", Description: "Outside", Expiry: expiry, Status: workflow.InProgress, Completion: 40}
+	done := db.Todo{Id: 3, Title: "Pay bills", Description: "Phone", Expiry: expiry, Status: workflow.Done, Completion: 100, IsDone: true, DoneAt: &doneAt}
+	archived := done
+	archived.Id = 4
+	archived.ArchivedAt = &archivedAt
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().GetAllTodos(gomock.Any()).Return([]db.Todo{open, progress, done}, nil)
+	model.EXPECT().GetArchivedTodos(gomock.Any()).Return([]db.Todo{archived}, nil)
+
+	var buf bytes.Buffer
+	count, err := writeTodos(context.Background(), model, &buf)
+	require.NoError(t, err)
+	require.Equal(t, 4, count)
+
+	todos, err := readTodos(&buf, workflow.Default)
+	require.NoError(t, err)
+	require.Len(t, todos, 4)
+
+	model.EXPECT().
+		WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(db.DB) error) error {
+			return fn(model)
+		})
+	var nextId int64 = 10
+	model.EXPECT().
+		CreateOneTodo(gomock.Any(), gomock.Any(), cliActor).
+		Times(4).
+		DoAndReturn(func(_ context.Context, params db.CreateTodoParams, _ string) (db.Todo, error) {
+			nextId++
+			return db.Todo{
+				Id:          nextId,
+				Title:       params.Title,
+				Description: params.Description,
+				Expiry:      params.Expiry,
+				Status:      params.Status,
+			}, nil
+		})
+	var updated []db.Todo
+	model.EXPECT().
+		UpdateOneTodo(gomock.Any(), gomock.Any(), cliActor).
+		Times(3).
+		DoAndReturn(func(_ context.Context, todo db.Todo, _ string) (db.Todo, error) {
+			updated = append(updated, todo)
+			return todo, nil
+		})
+
+	require.NoError(t, createTodos(context.Background(), model, workflow.Default, todos))
+
+	// Untouched Todos are only created, others keep their progress
+	require.Len(t, updated, 3)
+	require.Equal(t, int64(12), updated[0].Id)
+	require.Equal(t, float32(40), updated[0].Completion)
+	require.Nil(t, updated[0].DoneAt)
+
+	for _, todo := range updated[1:] {
+		require.Equal(t, workflow.Done, todo.Status)
+		require.True(t, todo.IsDone)
+		require.NotNil(t, todo.DoneAt)
+		require.True(t, doneAt.Equal(*todo.DoneAt))
+	}
+	require.Nil(t, updated[1].ArchivedAt)
+	require.NotNil(t, updated[2].ArchivedAt)
+	require.True(t, archivedAt.Equal(*updated[2].ArchivedAt))
+}
+
+func TestReadTodos(t *testing.T) {
+	_, err := readTodos(strings.NewReader(`[{"title": "Clean house"}]`), workflow.Default)
+	require.EqualError(t, err, "todo 0: title and description are required")
+
+	_, err = readTodos(strings.NewReader(`[{"title": "Clean house", "description": "Kitchen", "status": "lost"}]`), workflow.Default)
+	require.EqualError(t, err, `todo 0: unknown status "lost"`)
+
+	_, err = readTodos(strings.NewReader(`{}`), workflow.Default)
+	require.Error(t, err)
+}
+
+func TestImportWithoutStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	todos, err := readTodos(strings.NewReader(`[
+		{"title": "Clean house", "description": "Kitchen"},
+		{"title": "Pay bills", "description": "Phone", "is_done": true, "completion": 100}
+	]`), workflow.Default)
+	require.NoError(t, err)
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(db.DB) error) error {
+			return fn(model)
+		})
+	var statuses []string
+	model.EXPECT().
+		CreateOneTodo(gomock.Any(), gomock.Any(), cliActor).
+		Times(2).
+		DoAndReturn(func(_ context.Context, params db.CreateTodoParams, _ string) (db.Todo, error) {
+			statuses = append(statuses, params.Status)
+			return db.Todo{Title: params.Title, Status: params.Status}, nil
+		})
+	model.EXPECT().
+		UpdateOneTodo(gomock.Any(), gomock.Any(), cliActor).
+		Times(1).
+		DoAndReturn(func(_ context.Context, todo db.Todo, _ string) (db.Todo, error) {
+			require.True(t, todo.IsDone)
+			require.Equal(t, workflow.Done, todo.Status)
+			return todo, nil
+		})
+
+	require.NoError(t, createTodos(context.Background(), model, workflow.Default, todos))
+
+	// Finished Todos get done status, others the initial one
+	require.Equal(t, []string{workflow.Backlog, workflow.Done}, statuses)
+}
@@ -0,0 +1,113 @@
+package idempotency
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Stored request identified by idempotency key.
+//
+// Fingerprint identifies request's content.
+// Status, Header and Body hold the response once Done is true.
+type Record struct {
+	Fingerprint string
+	Done        bool
+	Status      int
+	Header      http.Header
+	Body        []byte
+	Expires     time.Time
+}
+
+// Storage of idempotency keys.
+//
+// Reserve must atomically store in-progress record under key unless
+// a record that has not expired already exists, so implementations shared
+// between many instances of the application can be plugged in later.
+type Store interface {
+	// Stores in-progress record with given fingerprint under key.
+	// Returns existing record and false when key is already in use.
+	Reserve(key, fingerprint string, ttl time.Duration) (Record, bool, error)
+
+	// Stores response of the request reserved under key.
+	Complete(key string, record Record) error
+
+	// Removes record stored under key, so the request can be retried.
+	Release(key string) error
+}
+
+// How many calls to Reserve can pass before expired records are removed.
+const sweepEvery = 1024
+
+// In-memory implementation of Store interface.
+//
+// Records are kept per process, so retries must reach the same instance
+// of the application to be recognized.
+type MemoryStore struct {
+	mu      sync.Mutex
+	records map[string]Record
+	calls   int
+	now     func() time.Time
+}
+
+// Returns empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]Record),
+		now:     time.Now,
+	}
+}
+
+// Stores in-progress record under key unless it holds one that has not expired.
+func (m *MemoryStore) Reserve(key, fingerprint string, ttl time.Duration) (Record, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.now()
+	m.calls++
+	if m.calls%sweepEvery == 0 {
+		m.sweep(now)
+	}
+
+	if record, ok := m.records[key]; ok && now.Before(record.Expires) {
+		return record, false, nil
+	}
+
+	record := Record{
+		Fingerprint: fingerprint,
+		Expires:     now.Add(ttl),
+	}
+	m.records[key] = record
+	return record, true, nil
+}
+
+// Stores response under key keeping expiry time of the reservation.
+func (m *MemoryStore) Complete(key string, record Record) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if reserved, ok := m.records[key]; ok {
+		record.Expires = reserved.Expires
+	}
+	record.Done = true
+	m.records[key] = record
+	return nil
+}
+
+// Removes record stored under key.
+func (m *MemoryStore) Release(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.records, key)
+	return nil
+}
+
+// Removes expired records.
+func (m *MemoryStore) sweep(now time.Time) {
+	for key, record := range m.records {
+		if !now.Before(record.Expires) {
+			delete(m.records, key)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package idempotency
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+
+	record, reserved, err := store.Reserve("key", "fingerprint", time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+	require.False(t, record.Done)
+
+	record, reserved, err = store.Reserve("key", "other", time.Hour)
+	require.NoError(t, err)
+	require.False(t, reserved)
+	require.False(t, record.Done)
+	require.Equal(t, "fingerprint", record.Fingerprint)
+
+	err = store.Complete("key", Record{
+		Fingerprint: "fingerprint",
+		Status:      http.StatusOK,
+		Body:        []byte("body"),
+	})
+	require.NoError(t, err)
+
+	record, reserved, err = store.Reserve("key", "fingerprint", time.Hour)
+	require.NoError(t, err)
+	require.False(t, reserved)
+	require.True(t, record.Done)
+	require.Equal(t, http.StatusOK, record.Status)
+	require.Equal(t, []byte("body"), record.Body)
+	require.Equal(t, now.Add(time.Hour), record.Expires)
+
+	// Expired keys can be reused
+	now = now.Add(time.Hour)
+	_, reserved, err = store.Reserve("key", "other", time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+
+	// Released keys can be reused
+	require.NoError(t, store.Release("key"))
+	_, reserved, err = store.Reserve("key", "fingerprint", time.Hour)
+	require.NoError(t, err)
+	require.True(t, reserved)
+}
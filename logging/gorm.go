@@ -0,0 +1,87 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+
+	"golang.org/x/exp/slog"
+	"gorm.io/gorm/logger"
+)
+
+// Matches string literals of SQL, including escaped quotes.
+var sqlString = regexp.MustCompile(`'(?:[^']|'')*'`)
+
+// Logger of GORM writing queries as records of slog logger,
+// with request ID of the context they were run with.
+//
+// Failed queries are logged at error level, queries slower than SlowQuery
+// at warn level and all other ones at debug level. Missing records are not errors.
+// Zero SlowQuery disables logging of slow queries.
+type GormLogger struct {
+	Logger    *slog.Logger
+	SlowQuery time.Duration
+
+	// GORM inlines values into logged SQL, so all string literals are redacted
+	// as descriptions cannot be told apart from other values
+	RedactDescriptions bool
+}
+
+// Returns GORM logger writing to l.
+func NewGormLogger(l *slog.Logger, opts Options) *GormLogger {
+	return &GormLogger{
+		Logger:             l,
+		SlowQuery:          opts.SlowQuery,
+		RedactDescriptions: opts.RedactDescriptions,
+	}
+}
+
+// Returns the same logger, levels are controlled by slog logger.
+func (l *GormLogger) LogMode(logger.LogLevel) logger.Interface {
+	return l
+}
+
+func (l *GormLogger) Info(ctx context.Context, msg string, data ...interface{}) {
+	l.Logger.InfoCtx(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (l *GormLogger) Warn(ctx context.Context, msg string, data ...interface{}) {
+	l.Logger.WarnCtx(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (l *GormLogger) Error(ctx context.Context, msg string, data ...interface{}) {
+	l.Logger.ErrorCtx(ctx, fmt.Sprintf(msg, data...))
+}
+
+func (l *GormLogger) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	elapsed := time.Since(begin)
+
+	level := slog.LevelDebug
+	msg := "query"
+	switch {
+	case err != nil && !errors.Is(err, logger.ErrRecordNotFound):
+		level, msg = slog.LevelError, "query failed"
+	case l.SlowQuery > 0 && elapsed > l.SlowQuery:
+		level, msg = slog.LevelWarn, "slow query"
+	}
+	if !l.Logger.Enabled(ctx, level) {
+		return
+	}
+
+	sql, rows := fc()
+	if l.RedactDescriptions {
+		sql = sqlString.ReplaceAllString(sql, "'"+Redacted+"'")
+	}
+
+	attrs := []slog.Attr{
+		slog.String("sql", sql),
+		slog.Int64("rows", rows),
+		slog.Duration("latency", elapsed),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+	}
+	l.Logger.LogAttrs(ctx, level, msg, attrs...)
+}
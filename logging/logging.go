@@ -0,0 +1,121 @@
+// Package logging configures structured logging of the application
+// and carries request IDs through contexts into log records.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/exp/slog"
+)
+
+// Formats of log records.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Key of attributes holding descriptions of Todos.
+const DescriptionKey = "description"
+
+// Value replacing redacted attributes.
+const Redacted = "[REDACTED]"
+
+// Settings of Logger.
+type Options struct {
+	// Minimum level of logged records: "debug", "info", "warn" or "error"
+	Level string
+
+	// Format of records, FormatJSON or FormatText
+	Format string
+
+	// Replace descriptions of Todos with Redacted,
+	// in attributes with DescriptionKey and in SQL of database logs
+	RedactDescriptions bool
+
+	// Duration after which database queries are logged at warn level
+	SlowQuery time.Duration
+}
+
+// Returns logger writing records to w.
+//
+// Records logged with context carrying request ID get "request_id" attribute,
+// and those logged with context of sampled span "trace_id" and "span_id" attributes.
+//
+// Throws an error when level or format is unknown.
+func New(w io.Writer, opts Options) (*slog.Logger, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(opts.Level)); err != nil {
+		return nil, fmt.Errorf("unknown log level %q", opts.Level)
+	}
+
+	handlerOpts := slog.HandlerOptions{Level: level}
+	if opts.RedactDescriptions {
+		handlerOpts.ReplaceAttr = redactDescription
+	}
+
+	var handler slog.Handler
+	switch strings.ToLower(opts.Format) {
+	case FormatJSON:
+		handler = handlerOpts.NewJSONHandler(w)
+	case FormatText:
+		handler = handlerOpts.NewTextHandler(w)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", opts.Format)
+	}
+	return slog.New(&contextHandler{Handler: handler}), nil
+}
+
+// Replaces value of description attributes with Redacted.
+func redactDescription(groups []string, attr slog.Attr) slog.Attr {
+	if attr.Key == DescriptionKey {
+		return slog.String(DescriptionKey, Redacted)
+	}
+	return attr
+}
+
+// Context key under which request ID is stored
+type requestIDContextKey struct{}
+
+// Returns context carrying request ID, added to records logged with it.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// Returns request ID carried by context, empty when there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// Handler adding request ID and span from context to every record.
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h *contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			record.AddAttrs(slog.String("request_id", id))
+		}
+		if span := trace.SpanContextFromContext(ctx); span.IsSampled() {
+			record.AddAttrs(
+				slog.String("trace_id", span.TraceID().String()),
+				slog.String("span_id", span.SpanID().String()),
+			)
+		}
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h *contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *contextHandler) WithGroup(name string) slog.Handler {
+	return &contextHandler{Handler: h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,95 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm/logger"
+)
+
+// Returns records written to buf as JSON objects.
+func records(t *testing.T, buf *bytes.Buffer) []map[string]any {
+	var res []map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var record map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		res = append(res, record)
+	}
+	return res
+}
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, Options{Level: "info", Format: FormatJSON, RedactDescriptions: true})
+	require.NoError(t, err)
+
+	ctx := WithRequestID(context.Background(), "abc")
+	require.Equal(t, "abc", RequestID(ctx))
+	require.Empty(t, RequestID(context.Background()))
+
+	l.InfoCtx(ctx, "created", DescriptionKey, "secret plans", "title", "plans")
+	l.With("component", "test").InfoCtx(ctx, "with attrs")
+	l.Info("no request")
+	l.DebugCtx(ctx, "below level")
+
+	logged := records(t, &buf)
+	require.Len(t, logged, 3)
+	require.Equal(t, "abc", logged[0]["request_id"])
+	require.Equal(t, Redacted, logged[0][DescriptionKey])
+	require.Equal(t, "plans", logged[0]["title"])
+	require.Equal(t, "abc", logged[1]["request_id"])
+	require.Equal(t, "test", logged[1]["component"])
+	require.NotContains(t, logged[2], "request_id")
+
+	// Descriptions are kept unless redaction is enabled
+	buf.Reset()
+	l, err = New(&buf, Options{Level: "debug", Format: FormatJSON})
+	require.NoError(t, err)
+	l.Debug("created", DescriptionKey, "secret plans")
+	require.Equal(t, "secret plans", records(t, &buf)[0][DescriptionKey])
+
+	_, err = New(&buf, Options{Level: "loud", Format: FormatJSON})
+	require.Error(t, err)
+	_, err = New(&buf, Options{Level: "info", Format: "xml"})
+	require.Error(t, err)
+}
+
+func TestGormLogger(t *testing.T) {
+	var buf bytes.Buffer
+	l, err := New(&buf, Options{Level: "info", Format: FormatJSON})
+	require.NoError(t, err)
+
+	gormLogger := NewGormLogger(l, Options{RedactDescriptions: true, SlowQuery: time.Second})
+	ctx := WithRequestID(context.Background(), "abc")
+	query := func() (string, int64) {
+		return `INSERT INTO "todos" ("title","description") VALUES ('plans','it''s secret')`, 1
+	}
+
+	// Fast queries are logged at debug level, missing records are not errors
+	gormLogger.Trace(ctx, time.Now(), query, nil)
+	gormLogger.Trace(ctx, time.Now(), query, logger.ErrRecordNotFound)
+	require.Empty(t, buf.String())
+
+	gormLogger.Trace(ctx, time.Now().Add(-2*time.Second), query, nil)
+	gormLogger.Trace(ctx, time.Now(), query, errors.New("connection refused"))
+
+	logged := records(t, &buf)
+	require.Len(t, logged, 2)
+	require.Equal(t, "WARN", logged[0]["level"])
+	require.Equal(t, "slow query", logged[0]["msg"])
+	require.Equal(t, "ERROR", logged[1]["level"])
+	require.Equal(t, "connection refused", logged[1]["error"])
+	for _, record := range logged {
+		require.Equal(t, "abc", record["request_id"])
+		require.Equal(t, `INSERT INTO "todos" ("title","description") VALUES ('[REDACTED]','[REDACTED]')`, record["sql"])
+	}
+}
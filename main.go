@@ -1,35 +1,129 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"log"
 	"os"
 
 	_ "github.com/lib/pq"
-	"github.com/vilderxyz/todos/api"
+	"github.com/vilderxyz/todos/config"
+	"github.com/vilderxyz/todos/workflow"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 )
 
+// Actor recorded in history of Todos changed by administrative commands.
+const cliActor = "system:cli"
+
+// Commands of the application binary by their names.
+var commands = map[string]func(args []string) error{
+	"serve":       serve,
+	"migrate":     migrate,
+	"seed":        seed,
+	"export":      exportTodos,
+	"import":      importTodos,
+	"purge-trash": purgeTrash,
+	"user":        user,
+	"config":      printConfig,
+}
+
+const usage = `Usage: todoApp [command] [arguments]
+
+Commands:
+  serve [flags]              runs http server, default when no command is given
+  config [flags]             prints configuration with secrets redacted
+  migrate up                 applies all pending migrations
+  migrate down [steps]       rolls back given number of the latest migrations, 1 by default
+  migrate status             lists all migrations with time they were applied at
+  seed [-n count]            creates sample todos
+  export [-o file]           writes all todos except trashed ones as JSON
+  import [file]              creates todos from JSON written by export
+  purge-trash [-older-than]  permanently removes todos from trash
+  user create <name>         creates user and prints its API key
+  user disable <name>        disables user, so its API key is no longer accepted
+
+Configuration is read from YAML file given with CONFIG_FILE environment variable,
+overridden by environment variables like DB_HOST and by flags of serve like -db-host.
+Run "todoApp serve -h" to list all settings.
+`
+
 func main() {
-	dsn := fmt.Sprintf("postgres://%v:%v@%v:%v/%v?sslmode=disable",
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_NAME"),
-	)
-	conn, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	name, args := "serve", os.Args[1:]
+	if len(args) > 0 {
+		name, args = args[0], args[1:]
+	}
+
+	command, ok := commands[name]
+	if !ok {
+		fmt.Fprint(os.Stderr, usage)
+		if name != "help" && name != "-h" && name != "--help" {
+			os.Exit(2)
+		}
+		return
+	}
+
+	if err := command(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return
+		}
+		log.Fatal(err)
+	}
+}
+
+// Returns configuration of administrative commands, loaded from
+// configuration file and environment variables. They take no configuration flags.
+func loadConfig() (*config.Config, error) {
+	return config.Load(nil)
+}
+
+// Opens connection to database configured with file and environment variables.
+func openDB() (*gorm.DB, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return connect(cfg.DB, logger.Default)
+}
+
+// Opens pool of connections to database with given settings.
+func connect(cfg config.DB, queryLogger logger.Interface) (*gorm.DB, error) {
+	conn, err := gorm.Open(postgres.Open(cfg.DSN()), &gorm.Config{Logger: queryLogger})
 	if err != nil {
-		log.Fatal("Cannot connect to db:", err)
+		return nil, fmt.Errorf("cannot connect to db: %w", err)
 	}
 
-	server := api.NewServer(conn)
+	pool, err := conn.DB()
+	if err != nil {
+		return nil, fmt.Errorf("cannot connect to db: %w", err)
+	}
+	pool.SetMaxOpenConns(cfg.MaxOpenConns)
+	pool.SetMaxIdleConns(cfg.MaxIdleConns)
+	pool.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	pool.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return conn, nil
+}
 
-	addr := os.Getenv("SERVER_ADDR")
+// Returns workflow configured with file and environment variables
+// or the default one.
+func loadWorkflow() (*workflow.Machine, error) {
+	cfg, err := loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return cfg.StatusWorkflow()
+}
 
-	err = server.Start(addr)
+// Prints configuration loaded from all sources with secrets redacted.
+//
+//	config [flags of serve]
+func printConfig(args []string) error {
+	cfg, err := config.Load(args)
 	if err != nil {
-		log.Fatal("Cannot start server:", err)
+		return err
 	}
+	fmt.Print(cfg)
+	return nil
 }
@@ -0,0 +1,177 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/vilderxyz/todos/db"
+)
+
+// Decorator of db.DB that records calls and latency of every query.
+//
+// Queries are labelled with names of their methods. Transactions are recorded
+// as "WithTx" query, including time spent by fn, and queries run within them
+// are recorded as well.
+type instrumented struct {
+	queries db.DB
+	metrics *Metrics
+}
+
+// Returns queries recording their calls in m.
+func DB(queries db.DB, m *Metrics) db.DB {
+	return &instrumented{queries: queries, metrics: m}
+}
+
+// Records call of query started at start.
+func (i *instrumented) observe(query string, start time.Time, err error) {
+	i.metrics.ObserveQuery(query, err, time.Since(start))
+}
+
+func (i *instrumented) GetAllTodos(ctx context.Context) ([]db.Todo, error) {
+	start := time.Now()
+	res, err := i.queries.GetAllTodos(ctx)
+	i.observe("GetAllTodos", start, err)
+	return res, err
+}
+
+func (i *instrumented) GetManyTodos(ctx context.Context, from, to time.Time) ([]db.Todo, error) {
+	start := time.Now()
+	res, err := i.queries.GetManyTodos(ctx, from, to)
+	i.observe("GetManyTodos", start, err)
+	return res, err
+}
+
+func (i *instrumented) GetOneTodoById(ctx context.Context, id int64) (db.Todo, error) {
+	start := time.Now()
+	res, err := i.queries.GetOneTodoById(ctx, id)
+	i.observe("GetOneTodoById", start, err)
+	return res, err
+}
+
+func (i *instrumented) UpdateOneTodo(ctx context.Context, todo db.Todo, actor string) (db.Todo, error) {
+	start := time.Now()
+	res, err := i.queries.UpdateOneTodo(ctx, todo, actor)
+	i.observe("UpdateOneTodo", start, err)
+	return res, err
+}
+
+func (i *instrumented) DeleteOneTodo(ctx context.Context, id int64, actor string) error {
+	start := time.Now()
+	err := i.queries.DeleteOneTodo(ctx, id, actor)
+	i.observe("DeleteOneTodo", start, err)
+	return err
+}
+
+func (i *instrumented) CreateOneTodo(ctx context.Context, params db.CreateTodoParams, actor string) (db.Todo, error) {
+	start := time.Now()
+	res, err := i.queries.CreateOneTodo(ctx, params, actor)
+	i.observe("CreateOneTodo", start, err)
+	return res, err
+}
+
+func (i *instrumented) GetTodoHistory(ctx context.Context, id int64, limit, offset int) ([]db.History, int64, error) {
+	start := time.Now()
+	res, total, err := i.queries.GetTodoHistory(ctx, id, limit, offset)
+	i.observe("GetTodoHistory", start, err)
+	return res, total, err
+}
+
+func (i *instrumented) GetHistoryOfTodos(ctx context.Context, ids []int64) ([]db.History, error) {
+	start := time.Now()
+	res, err := i.queries.GetHistoryOfTodos(ctx, ids)
+	i.observe("GetHistoryOfTodos", start, err)
+	return res, err
+}
+
+func (i *instrumented) GetTrashedTodos(ctx context.Context) ([]db.Todo, error) {
+	start := time.Now()
+	res, err := i.queries.GetTrashedTodos(ctx)
+	i.observe("GetTrashedTodos", start, err)
+	return res, err
+}
+
+func (i *instrumented) RestoreOneTodo(ctx context.Context, id int64, actor string) (db.Todo, error) {
+	start := time.Now()
+	res, err := i.queries.RestoreOneTodo(ctx, id, actor)
+	i.observe("RestoreOneTodo", start, err)
+	return res, err
+}
+
+func (i *instrumented) PurgeOneTodo(ctx context.Context, id int64, actor string) error {
+	start := time.Now()
+	err := i.queries.PurgeOneTodo(ctx, id, actor)
+	i.observe("PurgeOneTodo", start, err)
+	return err
+}
+
+func (i *instrumented) PurgeTrash(ctx context.Context, before time.Time, actor string) (int64, error) {
+	start := time.Now()
+	res, err := i.queries.PurgeTrash(ctx, before, actor)
+	i.observe("PurgeTrash", start, err)
+	return res, err
+}
+
+func (i *instrumented) GetTodoTransitions(ctx context.Context, id int64) ([]db.Transition, error) {
+	start := time.Now()
+	res, err := i.queries.GetTodoTransitions(ctx, id)
+	i.observe("GetTodoTransitions", start, err)
+	return res, err
+}
+
+func (i *instrumented) GetTransitionsOfTodos(ctx context.Context, ids []int64) ([]db.Transition, error) {
+	start := time.Now()
+	res, err := i.queries.GetTransitionsOfTodos(ctx, ids)
+	i.observe("GetTransitionsOfTodos", start, err)
+	return res, err
+}
+
+func (i *instrumented) GetArchivedTodos(ctx context.Context) ([]db.Todo, error) {
+	start := time.Now()
+	res, err := i.queries.GetArchivedTodos(ctx)
+	i.observe("GetArchivedTodos", start, err)
+	return res, err
+}
+
+func (i *instrumented) ArchiveDoneTodos(ctx context.Context, before time.Time, actor string) (int64, error) {
+	start := time.Now()
+	res, err := i.queries.ArchiveDoneTodos(ctx, before, actor)
+	i.observe("ArchiveDoneTodos", start, err)
+	return res, err
+}
+
+func (i *instrumented) CountTodos(ctx context.Context, now time.Time) (db.TodoCounts, error) {
+	start := time.Now()
+	res, err := i.queries.CountTodos(ctx, now)
+	i.observe("CountTodos", start, err)
+	return res, err
+}
+
+func (i *instrumented) CreateUser(ctx context.Context, name, keyHash string) (db.User, error) {
+	start := time.Now()
+	res, err := i.queries.CreateUser(ctx, name, keyHash)
+	i.observe("CreateUser", start, err)
+	return res, err
+}
+
+func (i *instrumented) GetUserByKeyHash(ctx context.Context, keyHash string) (db.User, error) {
+	start := time.Now()
+	res, err := i.queries.GetUserByKeyHash(ctx, keyHash)
+	i.observe("GetUserByKeyHash", start, err)
+	return res, err
+}
+
+func (i *instrumented) DisableUser(ctx context.Context, name string) (db.User, error) {
+	start := time.Now()
+	res, err := i.queries.DisableUser(ctx, name)
+	i.observe("DisableUser", start, err)
+	return res, err
+}
+
+func (i *instrumented) WithTx(ctx context.Context, fn func(db.DB) error) error {
+	start := time.Now()
+	err := i.queries.WithTx(ctx, func(tx db.DB) error {
+		return fn(&instrumented{queries: tx, metrics: i.metrics})
+	})
+	i.observe("WithTx", start, err)
+	return err
+}
@@ -0,0 +1,135 @@
+// Package metrics exposes Prometheus metrics of http requests, database queries
+// and Todos.
+package metrics
+
+import (
+	"database/sql"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Prefix of names of all metrics of the application.
+const namespace = "todos"
+
+// Route label of requests not matching any route, so unknown paths
+// do not create new series.
+const UnmatchedRoute = "unmatched"
+
+// Results of database queries.
+const (
+	resultOK       = "ok"
+	resultNotFound = "not_found"
+	resultError    = "error"
+)
+
+// Results of cache lookups.
+const (
+	resultHit  = "hit"
+	resultMiss = "miss"
+)
+
+// Collectors of the application registered in their own registry,
+// together with collectors of Go runtime and the process.
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	queries         *prometheus.CounterVec
+	queryDuration   *prometheus.HistogramVec
+	cacheLookups    *prometheus.CounterVec
+}
+
+// Returns Metrics with all collectors registered.
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of handled http requests by route template and status.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of http requests by route template.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route"}),
+		queries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "db_queries_total",
+			Help:      "Number of calls of database queries by result.",
+		}, []string{"query", "result"}),
+		queryDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Latency of database queries.",
+			Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+		}, []string{"query"}),
+		cacheLookups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "cache_lookups_total",
+			Help:      "Number of lookups of cached query results by result, hit or miss.",
+		}, []string{"query", "result"}),
+	}
+
+	m.Registry.MustRegister(
+		m.requests,
+		m.requestDuration,
+		m.queries,
+		m.queryDuration,
+		m.cacheLookups,
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Returns http handler serving all metrics in Prometheus text format.
+//
+// Failing collectors are reported in logs and skipped, so they do not fail the whole scrape.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{
+		ErrorHandling: promhttp.ContinueOnError,
+		ErrorLog:      log.Default(),
+	})
+}
+
+// Records handled http request. Route is template of matched route, e.g. "/todos/:id".
+func (m *Metrics) ObserveRequest(method, route string, status int, duration time.Duration) {
+	m.requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	m.requestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// Records call of database query with its error.
+func (m *Metrics) ObserveQuery(query string, err error, duration time.Duration) {
+	result := resultOK
+	if err != nil {
+		result = resultError
+		if err.Error() == "not found" {
+			result = resultNotFound
+		}
+	}
+	m.queries.WithLabelValues(query, result).Inc()
+	m.queryDuration.WithLabelValues(query).Observe(duration.Seconds())
+}
+
+// Records lookup of cached result of query and whether it was found.
+func (m *Metrics) ObserveCache(query string, hit bool) {
+	result := resultMiss
+	if hit {
+		result = resultHit
+	}
+	m.cacheLookups.WithLabelValues(query, result).Inc()
+}
+
+// Registers gauges of connection pool, e.g. numbers of open and idle connections.
+func (m *Metrics) RegisterPool(pool *sql.DB) error {
+	return m.Registry.Register(collectors.NewDBStatsCollector(pool, namespace))
+}
@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/mock"
+)
+
+func TestDB(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().GetOneTodoById(gomock.Any(), int64(1)).Return(db.Todo{Id: 1}, nil)
+	model.EXPECT().GetOneTodoById(gomock.Any(), int64(2)).Return(db.Todo{}, errors.New("not found"))
+	model.EXPECT().DeleteOneTodo(gomock.Any(), int64(1), "actor").Return(errors.New("connection refused"))
+	model.EXPECT().
+		WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(db.DB) error) error {
+			return fn(model)
+		})
+	model.EXPECT().GetAllTodos(gomock.Any()).Return(nil, nil)
+
+	m := New()
+	queries := DB(model, m)
+	ctx := context.Background()
+
+	todo, err := queries.GetOneTodoById(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, int64(1), todo.Id)
+
+	_, err = queries.GetOneTodoById(ctx, 2)
+	require.EqualError(t, err, "not found")
+
+	require.Error(t, queries.DeleteOneTodo(ctx, 1, "actor"))
+
+	// Queries within transaction are recorded as well
+	err = queries.WithTx(ctx, func(tx db.DB) error {
+		_, err := tx.GetAllTodos(ctx)
+		return err
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, 1.0, testutil.ToFloat64(m.queries.WithLabelValues("GetOneTodoById", resultOK)))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.queries.WithLabelValues("GetOneTodoById", resultNotFound)))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.queries.WithLabelValues("DeleteOneTodo", resultError)))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.queries.WithLabelValues("WithTx", resultOK)))
+	require.Equal(t, 1.0, testutil.ToFloat64(m.queries.WithLabelValues("GetAllTodos", resultOK)))
+	require.Equal(t, 4, testutil.CollectAndCount(m.queryDuration))
+}
+
+func TestTodoCounts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	gomock.InOrder(
+		model.EXPECT().
+			CountTodos(gomock.Any(), gomock.Any()).
+			Return(db.TodoCounts{Open: 3, Overdue: 1, Done: 2}, nil),
+		model.EXPECT().
+			CountTodos(gomock.Any(), gomock.Any()).
+			Return(db.TodoCounts{}, errors.New("connection refused")),
+	)
+
+	m := New()
+	require.NoError(t, m.RegisterTodoCounts(model, time.Second))
+
+	expected := `
+# HELP todos_todos Number of Todos by state, excluding archived ones and those in trash.
+# TYPE todos_todos gauge
+todos_todos{state="done"} 2
+todos_todos{state="open"} 3
+todos_todos{state="overdue"} 1
+`
+	require.NoError(t, testutil.GatherAndCompare(m.Registry, strings.NewReader(expected), "todos_todos"))
+
+	// Failing count does not fail the whole scrape
+	m.ObserveRequest(http.MethodGet, "/todos/:id", http.StatusOK, time.Millisecond)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/metrics", nil)
+	require.NoError(t, err)
+	m.Handler().ServeHTTP(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), `todos_http_requests_total{method="GET",route="/todos/:id",status="200"} 1`)
+	require.NotContains(t, recorder.Body.String(), "todos_todos{")
+}
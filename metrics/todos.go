@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/vilderxyz/todos/db"
+)
+
+// Collects numbers of Todos by their state with a single query on every scrape.
+type todoCollector struct {
+	queries db.DB
+	timeout time.Duration
+	counts  *prometheus.Desc
+}
+
+// Registers gauge of open, overdue and done Todos, excluding archived ones and those in trash,
+// counted with queries on every scrape within timeout. Zero timeout disables the limit.
+func (m *Metrics) RegisterTodoCounts(queries db.DB, timeout time.Duration) error {
+	return m.Registry.Register(&todoCollector{
+		queries: queries,
+		timeout: timeout,
+		counts: prometheus.NewDesc(
+			prometheus.BuildFQName(namespace, "", "todos"),
+			"Number of Todos by state, excluding archived ones and those in trash.",
+			[]string{"state"}, nil,
+		),
+	})
+}
+
+func (c *todoCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.counts
+}
+
+func (c *todoCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx := context.Background()
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	counts, err := c.queries.CountTodos(ctx, time.Now())
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.counts, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.counts, prometheus.GaugeValue, float64(counts.Open), "open")
+	ch <- prometheus.MustNewConstMetric(c.counts, prometheus.GaugeValue, float64(counts.Overdue), "overdue")
+	ch <- prometheus.MustNewConstMetric(c.counts, prometheus.GaugeValue, float64(counts.Done), "done")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/vilderxyz/todos/db"
+)
+
+// Runs migrate command with given arguments.
+//
+//	migrate up             - applies all pending migrations
+//	migrate down [steps]   - rolls back given number of the latest migrations, 1 by default
+//	migrate status         - lists all migrations with time they were applied at
+func migrate(args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [steps]|status")
+	}
+	ctx := context.Background()
+
+	conn, err := openDB()
+	if err != nil {
+		return err
+	}
+	migrator, err := db.NewMigrator(conn)
+	if err != nil {
+		return fmt.Errorf("cannot load migrations: %w", err)
+	}
+
+	switch args[0] {
+	case "up":
+		migrated, err := migrator.Up(ctx)
+		printMigrations("Applied", migrated)
+		return err
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("steps must be a positive number")
+			}
+		}
+		migrated, err := migrator.Down(ctx, steps)
+		printMigrations("Rolled back", migrated)
+		return err
+
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, status := range statuses {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = status.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%04d\t%v\t%v\n", status.Version, status.Name, appliedAt)
+		}
+		return w.Flush()
+	}
+	return fmt.Errorf("unknown migrate command %q", args[0])
+}
+
+// Prints versions and names of given migrations.
+func printMigrations(action string, migrations []db.Migration) {
+	if len(migrations) == 0 {
+		fmt.Println("No migrations to run")
+		return
+	}
+	for _, migration := range migrations {
+		fmt.Printf("%v %04d_%v\n", action, migration.Version, migration.Name)
+	}
+}
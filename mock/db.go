@@ -5,120 +5,346 @@
 package mock
 
 import (
-	gomock "github.com/golang/mock/gomock"
-	db "github.com/vilderxyz/todos/db"
+	context "context"
 	reflect "reflect"
 	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	db "github.com/vilderxyz/todos/db"
 )
 
-// MockDB is a mock of DB interface
+// MockDB is a mock of DB interface.
 type MockDB struct {
 	ctrl     *gomock.Controller
 	recorder *MockDBMockRecorder
 }
 
-// MockDBMockRecorder is the mock recorder for MockDB
+// MockDBMockRecorder is the mock recorder for MockDB.
 type MockDBMockRecorder struct {
 	mock *MockDB
 }
 
-// NewMockDB creates a new mock instance
+// NewMockDB creates a new mock instance.
 func NewMockDB(ctrl *gomock.Controller) *MockDB {
 	mock := &MockDB{ctrl: ctrl}
 	mock.recorder = &MockDBMockRecorder{mock}
 	return mock
 }
 
-// EXPECT returns an object that allows the caller to indicate expected use
+// EXPECT returns an object that allows the caller to indicate expected use.
 func (m *MockDB) EXPECT() *MockDBMockRecorder {
 	return m.recorder
 }
 
-// CreateOneTodo mocks base method
-func (m *MockDB) CreateOneTodo(arg0 db.CreateTodoParams) (db.Todo, error) {
+// ArchiveDoneTodos mocks base method.
+func (m *MockDB) ArchiveDoneTodos(arg0 context.Context, arg1 time.Time, arg2 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ArchiveDoneTodos", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ArchiveDoneTodos indicates an expected call of ArchiveDoneTodos.
+func (mr *MockDBMockRecorder) ArchiveDoneTodos(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ArchiveDoneTodos", reflect.TypeOf((*MockDB)(nil).ArchiveDoneTodos), arg0, arg1, arg2)
+}
+
+// CountTodos mocks base method.
+func (m *MockDB) CountTodos(arg0 context.Context, arg1 time.Time) (db.TodoCounts, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountTodos", arg0, arg1)
+	ret0, _ := ret[0].(db.TodoCounts)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountTodos indicates an expected call of CountTodos.
+func (mr *MockDBMockRecorder) CountTodos(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountTodos", reflect.TypeOf((*MockDB)(nil).CountTodos), arg0, arg1)
+}
+
+// CreateOneTodo mocks base method.
+func (m *MockDB) CreateOneTodo(arg0 context.Context, arg1 db.CreateTodoParams, arg2 string) (db.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "CreateOneTodo", arg0)
+	ret := m.ctrl.Call(m, "CreateOneTodo", arg0, arg1, arg2)
 	ret0, _ := ret[0].(db.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// CreateOneTodo indicates an expected call of CreateOneTodo
-func (mr *MockDBMockRecorder) CreateOneTodo(arg0 interface{}) *gomock.Call {
+// CreateOneTodo indicates an expected call of CreateOneTodo.
+func (mr *MockDBMockRecorder) CreateOneTodo(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOneTodo", reflect.TypeOf((*MockDB)(nil).CreateOneTodo), arg0, arg1, arg2)
+}
+
+// CreateUser mocks base method.
+func (m *MockDB) CreateUser(arg0 context.Context, arg1, arg2 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateUser", arg0, arg1, arg2)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateUser indicates an expected call of CreateUser.
+func (mr *MockDBMockRecorder) CreateUser(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOneTodo", reflect.TypeOf((*MockDB)(nil).CreateOneTodo), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockDB)(nil).CreateUser), arg0, arg1, arg2)
 }
 
-// DeleteOneTodo mocks base method
-func (m *MockDB) DeleteOneTodo(arg0 int64) error {
+// DeleteOneTodo mocks base method.
+func (m *MockDB) DeleteOneTodo(arg0 context.Context, arg1 int64, arg2 string) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteOneTodo", arg0)
+	ret := m.ctrl.Call(m, "DeleteOneTodo", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// DeleteOneTodo indicates an expected call of DeleteOneTodo
-func (mr *MockDBMockRecorder) DeleteOneTodo(arg0 interface{}) *gomock.Call {
+// DeleteOneTodo indicates an expected call of DeleteOneTodo.
+func (mr *MockDBMockRecorder) DeleteOneTodo(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOneTodo", reflect.TypeOf((*MockDB)(nil).DeleteOneTodo), arg0, arg1, arg2)
+}
+
+// DisableUser mocks base method.
+func (m *MockDB) DisableUser(arg0 context.Context, arg1 string) (db.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DisableUser", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DisableUser indicates an expected call of DisableUser.
+func (mr *MockDBMockRecorder) DisableUser(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DisableUser", reflect.TypeOf((*MockDB)(nil).DisableUser), arg0, arg1)
+}
+
+// GetAllTodos mocks base method.
+func (m *MockDB) GetAllTodos(arg0 context.Context) ([]db.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAllTodos", arg0)
+	ret0, _ := ret[0].([]db.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAllTodos indicates an expected call of GetAllTodos.
+func (mr *MockDBMockRecorder) GetAllTodos(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTodos", reflect.TypeOf((*MockDB)(nil).GetAllTodos), arg0)
+}
+
+// GetArchivedTodos mocks base method.
+func (m *MockDB) GetArchivedTodos(arg0 context.Context) ([]db.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetArchivedTodos", arg0)
+	ret0, _ := ret[0].([]db.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetArchivedTodos indicates an expected call of GetArchivedTodos.
+func (mr *MockDBMockRecorder) GetArchivedTodos(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetArchivedTodos", reflect.TypeOf((*MockDB)(nil).GetArchivedTodos), arg0)
+}
+
+// GetHistoryOfTodos mocks base method.
+func (m *MockDB) GetHistoryOfTodos(arg0 context.Context, arg1 []int64) ([]db.History, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHistoryOfTodos", arg0, arg1)
+	ret0, _ := ret[0].([]db.History)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHistoryOfTodos indicates an expected call of GetHistoryOfTodos.
+func (mr *MockDBMockRecorder) GetHistoryOfTodos(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteOneTodo", reflect.TypeOf((*MockDB)(nil).DeleteOneTodo), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHistoryOfTodos", reflect.TypeOf((*MockDB)(nil).GetHistoryOfTodos), arg0, arg1)
 }
 
-// GetAllTodos mocks base method
-func (m *MockDB) GetAllTodos() ([]db.Todo, error) {
+// GetManyTodos mocks base method.
+func (m *MockDB) GetManyTodos(arg0 context.Context, arg1, arg2 time.Time) ([]db.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetAllTodos")
+	ret := m.ctrl.Call(m, "GetManyTodos", arg0, arg1, arg2)
 	ret0, _ := ret[0].([]db.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetAllTodos indicates an expected call of GetAllTodos
-func (mr *MockDBMockRecorder) GetAllTodos() *gomock.Call {
+// GetManyTodos indicates an expected call of GetManyTodos.
+func (mr *MockDBMockRecorder) GetManyTodos(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAllTodos", reflect.TypeOf((*MockDB)(nil).GetAllTodos))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManyTodos", reflect.TypeOf((*MockDB)(nil).GetManyTodos), arg0, arg1, arg2)
 }
 
-// GetManyTodos mocks base method
-func (m *MockDB) GetManyTodos(arg0, arg1 time.Time) ([]db.Todo, error) {
+// GetOneTodoById mocks base method.
+func (m *MockDB) GetOneTodoById(arg0 context.Context, arg1 int64) (db.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetManyTodos", arg0, arg1)
+	ret := m.ctrl.Call(m, "GetOneTodoById", arg0, arg1)
+	ret0, _ := ret[0].(db.Todo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOneTodoById indicates an expected call of GetOneTodoById.
+func (mr *MockDBMockRecorder) GetOneTodoById(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneTodoById", reflect.TypeOf((*MockDB)(nil).GetOneTodoById), arg0, arg1)
+}
+
+// GetTodoHistory mocks base method.
+func (m *MockDB) GetTodoHistory(arg0 context.Context, arg1 int64, arg2, arg3 int) ([]db.History, int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTodoHistory", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]db.History)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTodoHistory indicates an expected call of GetTodoHistory.
+func (mr *MockDBMockRecorder) GetTodoHistory(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTodoHistory", reflect.TypeOf((*MockDB)(nil).GetTodoHistory), arg0, arg1, arg2, arg3)
+}
+
+// GetTodoTransitions mocks base method.
+func (m *MockDB) GetTodoTransitions(arg0 context.Context, arg1 int64) ([]db.Transition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTodoTransitions", arg0, arg1)
+	ret0, _ := ret[0].([]db.Transition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTodoTransitions indicates an expected call of GetTodoTransitions.
+func (mr *MockDBMockRecorder) GetTodoTransitions(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTodoTransitions", reflect.TypeOf((*MockDB)(nil).GetTodoTransitions), arg0, arg1)
+}
+
+// GetTransitionsOfTodos mocks base method.
+func (m *MockDB) GetTransitionsOfTodos(arg0 context.Context, arg1 []int64) ([]db.Transition, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransitionsOfTodos", arg0, arg1)
+	ret0, _ := ret[0].([]db.Transition)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransitionsOfTodos indicates an expected call of GetTransitionsOfTodos.
+func (mr *MockDBMockRecorder) GetTransitionsOfTodos(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransitionsOfTodos", reflect.TypeOf((*MockDB)(nil).GetTransitionsOfTodos), arg0, arg1)
+}
+
+// GetTrashedTodos mocks base method.
+func (m *MockDB) GetTrashedTodos(arg0 context.Context) ([]db.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrashedTodos", arg0)
 	ret0, _ := ret[0].([]db.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetManyTodos indicates an expected call of GetManyTodos
-func (mr *MockDBMockRecorder) GetManyTodos(arg0, arg1 interface{}) *gomock.Call {
+// GetTrashedTodos indicates an expected call of GetTrashedTodos.
+func (mr *MockDBMockRecorder) GetTrashedTodos(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetManyTodos", reflect.TypeOf((*MockDB)(nil).GetManyTodos), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrashedTodos", reflect.TypeOf((*MockDB)(nil).GetTrashedTodos), arg0)
 }
 
-// GetOneTodoById mocks base method
-func (m *MockDB) GetOneTodoById(arg0 int64) (db.Todo, error) {
+// GetUserByKeyHash mocks base method.
+func (m *MockDB) GetUserByKeyHash(arg0 context.Context, arg1 string) (db.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetOneTodoById", arg0)
+	ret := m.ctrl.Call(m, "GetUserByKeyHash", arg0, arg1)
+	ret0, _ := ret[0].(db.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserByKeyHash indicates an expected call of GetUserByKeyHash.
+func (mr *MockDBMockRecorder) GetUserByKeyHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByKeyHash", reflect.TypeOf((*MockDB)(nil).GetUserByKeyHash), arg0, arg1)
+}
+
+// PurgeOneTodo mocks base method.
+func (m *MockDB) PurgeOneTodo(arg0 context.Context, arg1 int64, arg2 string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeOneTodo", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// PurgeOneTodo indicates an expected call of PurgeOneTodo.
+func (mr *MockDBMockRecorder) PurgeOneTodo(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeOneTodo", reflect.TypeOf((*MockDB)(nil).PurgeOneTodo), arg0, arg1, arg2)
+}
+
+// PurgeTrash mocks base method.
+func (m *MockDB) PurgeTrash(arg0 context.Context, arg1 time.Time, arg2 string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeTrash", arg0, arg1, arg2)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeTrash indicates an expected call of PurgeTrash.
+func (mr *MockDBMockRecorder) PurgeTrash(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeTrash", reflect.TypeOf((*MockDB)(nil).PurgeTrash), arg0, arg1, arg2)
+}
+
+// RestoreOneTodo mocks base method.
+func (m *MockDB) RestoreOneTodo(arg0 context.Context, arg1 int64, arg2 string) (db.Todo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreOneTodo", arg0, arg1, arg2)
 	ret0, _ := ret[0].(db.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// GetOneTodoById indicates an expected call of GetOneTodoById
-func (mr *MockDBMockRecorder) GetOneTodoById(arg0 interface{}) *gomock.Call {
+// RestoreOneTodo indicates an expected call of RestoreOneTodo.
+func (mr *MockDBMockRecorder) RestoreOneTodo(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOneTodoById", reflect.TypeOf((*MockDB)(nil).GetOneTodoById), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreOneTodo", reflect.TypeOf((*MockDB)(nil).RestoreOneTodo), arg0, arg1, arg2)
 }
 
-// UpdateOneTodo mocks base method
-func (m *MockDB) UpdateOneTodo(arg0 db.Todo) (db.Todo, error) {
+// UpdateOneTodo mocks base method.
+func (m *MockDB) UpdateOneTodo(arg0 context.Context, arg1 db.Todo, arg2 string) (db.Todo, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateOneTodo", arg0)
+	ret := m.ctrl.Call(m, "UpdateOneTodo", arg0, arg1, arg2)
 	ret0, _ := ret[0].(db.Todo)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
-// UpdateOneTodo indicates an expected call of UpdateOneTodo
-func (mr *MockDBMockRecorder) UpdateOneTodo(arg0 interface{}) *gomock.Call {
+// UpdateOneTodo indicates an expected call of UpdateOneTodo.
+func (mr *MockDBMockRecorder) UpdateOneTodo(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOneTodo", reflect.TypeOf((*MockDB)(nil).UpdateOneTodo), arg0, arg1, arg2)
+}
+
+// WithTx mocks base method.
+func (m *MockDB) WithTx(arg0 context.Context, arg1 func(db.DB) error) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithTx", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithTx indicates an expected call of WithTx.
+func (mr *MockDBMockRecorder) WithTx(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOneTodo", reflect.TypeOf((*MockDB)(nil).UpdateOneTodo), arg0)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithTx", reflect.TypeOf((*MockDB)(nil).WithTx), arg0, arg1)
 }
@@ -0,0 +1,127 @@
+package ratelimit
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Token bucket parameters.
+//
+// Rate is the number of tokens added to the bucket per second
+// and Burst is the maximum number of tokens the bucket can hold.
+//
+// Limit with zero Rate disables limiting.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Returns true when Limit does not restrict anything.
+func (l Limit) Disabled() bool {
+	return l.Rate <= 0 || l.Burst <= 0
+}
+
+// Returns Limit that allows n requests per given period
+// with burst of the same size.
+func Every(n int, period time.Duration) Limit {
+	return Limit{
+		Rate:  float64(n) / period.Seconds(),
+		Burst: n,
+	}
+}
+
+// Outcome of a single attempt to take a token from the bucket.
+//
+// Limit and Remaining are used for RateLimit-Limit and RateLimit-Remaining headers.
+//
+// Reset is the time until the bucket is full again
+// and RetryAfter is the time until next token is available.
+// RetryAfter is zero when request was allowed.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Parses limit in format "n/period[:burst]".
+//
+// Period must be one of [ "s" , "m" , "h" ].
+// When burst is omitted it is equal to n, otherwise it must be positive.
+//
+// Examples:
+//
+//	"10/s"	- 10 requests per second
+//	"100/m:20"	- 100 requests per minute with burst of 20
+//	"0/s"	- no limit
+func ParseLimit(s string) (Limit, error) {
+	rate, burst, hasBurst := strings.Cut(strings.TrimSpace(s), ":")
+
+	count, unit, ok := strings.Cut(rate, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q", s)
+	}
+
+	n, err := strconv.Atoi(count)
+	if err != nil || n < 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q", s)
+	}
+
+	var period time.Duration
+	switch unit {
+	case "s":
+		period = time.Second
+	case "m":
+		period = time.Minute
+	case "h":
+		period = time.Hour
+	default:
+		return Limit{}, fmt.Errorf("invalid rate limit period %q", unit)
+	}
+
+	limit := Every(n, period)
+	if hasBurst {
+		b, err := strconv.Atoi(burst)
+		if err != nil || b < 1 {
+			return Limit{}, fmt.Errorf("invalid rate limit burst %q", burst)
+		}
+		limit.Burst = b
+	}
+	return limit, nil
+}
+
+// Bucket state kept by a Store.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// Refills the bucket up to now and tries to take a single token.
+func (b *bucket) take(limit Limit, now time.Time) Result {
+	elapsed := now.Sub(b.updated).Seconds()
+	if elapsed > 0 {
+		b.tokens = math.Min(float64(limit.Burst), b.tokens+elapsed*limit.Rate)
+		b.updated = now
+	}
+
+	res := Result{Limit: limit.Burst}
+	if b.tokens >= 1 {
+		b.tokens--
+		res.Allowed = true
+	} else {
+		res.RetryAfter = seconds((1 - b.tokens) / limit.Rate)
+	}
+
+	res.Remaining = int(b.tokens)
+	res.Reset = seconds((float64(limit.Burst) - b.tokens) / limit.Rate)
+	return res
+}
+
+// Converts fractional seconds to time.Duration.
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
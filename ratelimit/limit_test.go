@@ -0,0 +1,84 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	testCases := []struct {
+		name  string
+		input string
+		limit Limit
+		fails bool
+	}{
+		{name: "per second", input: "10/s", limit: Limit{Rate: 10, Burst: 10}},
+		{name: "per minute with burst", input: "120/m:5", limit: Limit{Rate: 2, Burst: 5}},
+		{name: "per hour", input: "3600/h", limit: Limit{Rate: 1, Burst: 3600}},
+		{name: "disabled", input: "0/s", limit: Limit{Rate: 0, Burst: 0}},
+		{name: "missing period", input: "10", fails: true},
+		{name: "unknown period", input: "10/d", fails: true},
+		{name: "negative count", input: "-1/s", fails: true},
+		{name: "invalid burst", input: "10/s:x", fails: true},
+		{name: "zero burst", input: "10/s:0", fails: true},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			limit, err := ParseLimit(tc.input)
+			if tc.fails {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.limit, limit)
+		})
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	now := time.Now()
+	limiter := New("test", Limit{Rate: 1, Burst: 2}, NewMemoryStore())
+	limiter.Now = func() time.Time { return now }
+
+	res, err := limiter.Allow("client")
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, 2, res.Limit)
+	require.Equal(t, 1, res.Remaining)
+
+	res, err = limiter.Allow("client")
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+	require.Equal(t, 0, res.Remaining)
+	require.Equal(t, 2*time.Second, res.Reset)
+
+	res, err = limiter.Allow("client")
+	require.NoError(t, err)
+	require.False(t, res.Allowed)
+	require.Equal(t, time.Second, res.RetryAfter)
+
+	// Other clients have their own buckets
+	res, err = limiter.Allow("other")
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+
+	now = now.Add(time.Second)
+	res, err = limiter.Allow("client")
+	require.NoError(t, err)
+	require.True(t, res.Allowed)
+}
+
+func TestLimiterDisabled(t *testing.T) {
+	limiter := New("test", Limit{}, NewMemoryStore())
+
+	for i := 0; i < 100; i++ {
+		res, err := limiter.Allow("client")
+		require.NoError(t, err)
+		require.True(t, res.Allowed)
+	}
+}
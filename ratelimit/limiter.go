@@ -0,0 +1,33 @@
+package ratelimit
+
+import "time"
+
+// Applies single Limit to requests identified by keys.
+//
+// Name separates buckets of different limiters that share the same Store.
+type Limiter struct {
+	Name  string
+	Limit Limit
+	Store Store
+	Now   func() time.Time
+}
+
+// Returns Limiter with given name and limit backed by store.
+func New(name string, limit Limit, store Store) *Limiter {
+	return &Limiter{
+		Name:  name,
+		Limit: limit,
+		Store: store,
+		Now:   time.Now,
+	}
+}
+
+// Takes a token for given key.
+//
+// Always allows the request when Limit is disabled.
+func (l *Limiter) Allow(key string) (Result, error) {
+	if l.Limit.Disabled() {
+		return Result{Allowed: true}, nil
+	}
+	return l.Store.Take(l.Name+":"+key, l.Limit, l.Now())
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Storage of token buckets.
+//
+// Take must refill the bucket stored under key and take a single token
+// from it atomically, so implementations shared between many instances
+// of the application (e.g. Redis) can be plugged in later.
+type Store interface {
+	Take(key string, limit Limit, now time.Time) (Result, error)
+}
+
+// How many calls to Take can pass before idle buckets are removed.
+const sweepEvery = 1024
+
+// In-memory implementation of Store interface.
+//
+// Buckets are kept per process, so every instance of the application
+// counts requests on its own.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	calls   int
+}
+
+// Returns empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Takes a token from the bucket stored under key.
+//
+// New buckets start full.
+func (m *MemoryStore) Take(key string, limit Limit, now time.Time) (Result, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls++
+	if m.calls%sweepEvery == 0 {
+		m.sweep(now)
+	}
+
+	b, ok := m.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(limit.Burst), updated: now}
+		m.buckets[key] = b
+	}
+	return b.take(limit, now), nil
+}
+
+// Removes buckets that were not used for over an hour.
+//
+// Any bucket refills completely within this time for all practical limits,
+// so removing it is the same as keeping a full one.
+func (m *MemoryStore) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if now.Sub(b.updated) > time.Hour {
+			delete(m.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package rpc
+
+import (
+	"context"
+	"net"
+
+	"github.com/vilderxyz/todos/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata key that carries client's API key
+const apiKeyMetadata = "x-api-key"
+
+// Context key under which authenticated user's name is stored
+type userContextKey struct{}
+
+// Returns context of call authenticated by API key from its metadata.
+//
+// Calls without API key are authenticated by client certificate
+// mapped in ClientCertUsers, or are anonymous.
+//
+// API keys are looked up within AuthLimiter of client's IP address,
+// so made up keys cannot flood the database.
+//
+// Throws ResourceExhausted status when there are too many lookups and
+// Unauthenticated status when API key is unknown or its user is disabled.
+func (s *Server) authenticate(ctx context.Context) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	keys := md.Get(apiKeyMetadata)
+	if len(keys) == 0 || keys[0] == "" {
+		if p, ok := peer.FromContext(ctx); ok {
+			if info, ok := p.AuthInfo.(credentials.TLSInfo); ok {
+				if user := s.ClientCertUsers.Lookup(&info.State); user != "" {
+					return context.WithValue(ctx, userContextKey{}, user), nil
+				}
+			}
+		}
+		return ctx, nil
+	}
+
+	res, err := s.AuthLimiter.Allow(peerAddress(ctx))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if !res.Allowed {
+		return nil, status.Error(codes.ResourceExhausted, "rate limit exceeded")
+	}
+
+	user, err := s.Queries.GetUserByKeyHash(ctx, api.HashAPIKey(keys[0]))
+	if err != nil {
+		if err.Error() == "not found" {
+			return nil, status.Error(codes.Unauthenticated, "invalid api key")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if user.DisabledAt != nil {
+		return nil, status.Error(codes.Unauthenticated, "user is disabled")
+	}
+	return context.WithValue(ctx, userContextKey{}, user.Name), nil
+}
+
+func (s *Server) authenticateUnary(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	ctx, err := s.authenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (s *Server) authenticateStream(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	ctx, err := s.authenticate(stream.Context())
+	if err != nil {
+		return err
+	}
+	return handler(srv, &authenticatedStream{ServerStream: stream, ctx: ctx})
+}
+
+// Server stream with authenticated context.
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+// Returns name of the actor performing the call, recorded in Todo's history.
+//
+// It is authenticated user's name when available.
+// Otherwise it is client's IP address, the same as for anonymous http requests.
+func actor(ctx context.Context) string {
+	if user, ok := ctx.Value(userContextKey{}).(string); ok {
+		return user
+	}
+	return peerAddress(ctx)
+}
+
+// Returns client's IP address, formatted like rate limiting keys of http requests.
+func peerAddress(ctx context.Context) string {
+	if p, ok := peer.FromContext(ctx); ok {
+		if host, _, err := net.SplitHostPort(p.Addr.String()); err == nil {
+			return "ip:" + host
+		}
+		return "ip:" + p.Addr.String()
+	}
+	return "ip:unknown"
+}
@@ -0,0 +1,275 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+
+	"github.com/vilderxyz/todos/api"
+	"github.com/vilderxyz/todos/certs"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/ratelimit"
+	"github.com/vilderxyz/todos/rpc/todospb"
+	"github.com/vilderxyz/todos/service"
+	"github.com/vilderxyz/todos/watch"
+	"github.com/vilderxyz/todos/workflow"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Number of events that can wait for slow watcher before its stream ends.
+const DefaultWatchBuffer = 64
+
+// gRPC implementation of TodoService.
+//
+// Requests are validated with the same rules as requests of http API.
+type Server struct {
+	todospb.UnimplementedTodoServiceServer
+
+	Queries db.DB
+
+	// Allowed transitions between Todo statuses
+	Workflow *workflow.Machine
+
+	// Source of events streamed to watchers, Queries must publish to it
+	Broker      *watch.Broker
+	WatchBuffer int
+
+	// Users of clients authenticated by verified TLS certificates
+	ClientCertUsers certs.Users
+
+	// Limiter of API key lookups per IP address, taken before authentication
+	AuthLimiter *ratelimit.Limiter
+}
+
+// Creates a new Server using given queries and broker of their changes.
+func NewServer(queries db.DB, broker *watch.Broker) *Server {
+	return &Server{
+		Queries:     queries,
+		Workflow:    workflow.Default,
+		Broker:      broker,
+		WatchBuffer: DefaultWatchBuffer,
+		AuthLimiter: ratelimit.New("auth", api.DefaultAuthLimit, ratelimit.NewMemoryStore()),
+	}
+}
+
+// Returns grpc.Server serving TodoService with authentication of calls.
+func (s *Server) GRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(s.authenticateUnary),
+		grpc.ChainStreamInterceptor(s.authenticateStream),
+	)
+	server := grpc.NewServer(opts...)
+	todospb.RegisterTodoServiceServer(server, s)
+	return server
+}
+
+// Returns Service applying rules of Todos with server's queries and workflow.
+func (s *Server) todos() *service.Service {
+	return service.New(s.Queries, s.Workflow)
+}
+
+func (s *Server) CreateTodo(ctx context.Context, req *todospb.CreateTodoRequest) (*todospb.Todo, error) {
+	params := api.CreateTodoRequest{
+		Title:       req.Title,
+		Description: req.Description,
+		Expiry:      req.Expiry,
+	}
+	if err := api.Validate(params); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	expiry, err := service.ParseExpiry(req.Expiry)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	todo, err := s.todos().CreateTodo(ctx, service.TodoInfo{
+		Title:       req.Title,
+		Description: req.Description,
+		Expiry:      expiry,
+	}, actor(ctx))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toProto(todo), nil
+}
+
+func (s *Server) GetTodo(ctx context.Context, req *todospb.GetTodoRequest) (*todospb.Todo, error) {
+	if err := api.Validate(api.GetTodoByIdRequest{Id: req.Id}); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	todo, err := s.todos().GetTodo(ctx, req.Id)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toProto(todo), nil
+}
+
+func (s *Server) ListTodos(ctx context.Context, req *todospb.ListTodosRequest) (*todospb.ListTodosResponse, error) {
+	if err := api.Validate(api.GetTodosRequest{Period: req.Period, Archived: req.Archived}); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	todos, err := s.todos().ListTodos(ctx, service.ListParams{
+		Period:   req.Period,
+		Archived: req.Archived,
+		Status:   req.Status,
+	})
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	res := &todospb.ListTodosResponse{}
+	for _, todo := range todos {
+		res.Todos = append(res.Todos, toProto(todo))
+	}
+	return res, nil
+}
+
+func (s *Server) UpdateTodo(ctx context.Context, req *todospb.UpdateTodoRequest) (*todospb.Todo, error) {
+	params := api.UpdateTodoInfoRequest{
+		Id:          req.Id,
+		Title:       req.Title,
+		Description: req.Description,
+		Expiry:      req.Expiry,
+	}
+	if err := api.Validate(params); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	expiry, err := service.ParseExpiry(req.Expiry)
+	if err != nil {
+		return nil, statusError(err)
+	}
+
+	return s.update(ctx, req.Id, service.Info(service.TodoInfo{
+		Title:       req.Title,
+		Description: req.Description,
+		Expiry:      expiry,
+	}))
+}
+
+func (s *Server) UpdateCompletion(ctx context.Context, req *todospb.UpdateCompletionRequest) (*todospb.Todo, error) {
+	params := api.UpdateTodoCompletionRequest{Id: req.Id, Completion: req.Completion}
+	if err := api.Validate(params); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return s.update(ctx, req.Id, service.Completion(req.Completion))
+}
+
+func (s *Server) SetDone(ctx context.Context, req *todospb.SetDoneRequest) (*todospb.Todo, error) {
+	params := api.UpdateTodoDoneRequest{Id: req.Id, IsDone: &req.Done}
+	if err := api.Validate(params); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return s.update(ctx, req.Id, service.Done(req.Done))
+}
+
+// Applies change to Todo with given Id and returns its protobuf message.
+func (s *Server) update(ctx context.Context, id int64, change service.Change) (*todospb.Todo, error) {
+	todo, err := s.todos().Update(ctx, id, change, actor(ctx))
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return toProto(todo), nil
+}
+
+func (s *Server) DeleteTodo(ctx context.Context, req *todospb.DeleteTodoRequest) (*todospb.DeleteTodoResponse, error) {
+	if err := api.Validate(api.DeleteTodoRequest{Id: req.Id}); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if err := s.todos().DeleteTodo(ctx, req.Id, actor(ctx)); err != nil {
+		return nil, statusError(err)
+	}
+	return &todospb.DeleteTodoResponse{}, nil
+}
+
+// Kinds of events sent to watchers.
+var eventKinds = map[string]todospb.TodoEvent_Kind{
+	watch.Created:  todospb.TodoEvent_CREATED,
+	watch.Updated:  todospb.TodoEvent_UPDATED,
+	watch.Deleted:  todospb.TodoEvent_DELETED,
+	watch.Restored: todospb.TodoEvent_RESTORED,
+	watch.Purged:   todospb.TodoEvent_PURGED,
+}
+
+// Streams changes of Todos until client cancels the call.
+//
+// Headers are sent once the watcher is subscribed,
+// so client receiving them does not miss any later change.
+func (s *Server) WatchTodos(req *todospb.WatchTodosRequest, stream todospb.TodoService_WatchTodosServer) error {
+	ids := make(map[int64]bool, len(req.Ids))
+	for _, id := range req.Ids {
+		ids[id] = true
+	}
+
+	events, cancel := s.Broker.Subscribe(s.WatchBuffer)
+	defer cancel()
+
+	if err := stream.SendHeader(nil); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "watcher does not keep up with changes")
+			}
+			if len(ids) > 0 && !ids[event.Todo.Id] {
+				continue
+			}
+
+			err := stream.Send(&todospb.TodoEvent{
+				Kind: eventKinds[event.Kind],
+				Todo: toProto(event.Todo),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Returns status error of failed call. Missing Todos get NotFound status,
+// invalid arguments InvalidArgument status, changes not allowed in Todo's state
+// FailedPrecondition status and other errors Internal status.
+func statusError(err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalid):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, service.ErrConflict):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// Converts Todo to its protobuf message.
+func toProto(todo db.Todo) *todospb.Todo {
+	res := &todospb.Todo{
+		Id:          todo.Id,
+		Title:       todo.Title,
+		Description: todo.Description,
+		Completion:  todo.Completion,
+		IsDone:      todo.IsDone,
+		Status:      todo.Status,
+	}
+	if !todo.Expiry.IsZero() {
+		res.Expiry = timestamppb.New(todo.Expiry)
+	}
+	if todo.DoneAt != nil {
+		res.DoneAt = timestamppb.New(*todo.DoneAt)
+	}
+	if todo.ArchivedAt != nil {
+		res.ArchivedAt = timestamppb.New(*todo.ArchivedAt)
+	}
+	return res
+}
@@ -0,0 +1,370 @@
+package rpc
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/api"
+	"github.com/vilderxyz/todos/certs"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/mock"
+	"github.com/vilderxyz/todos/ratelimit"
+	"github.com/vilderxyz/todos/rpc/todospb"
+	"github.com/vilderxyz/todos/service"
+	"github.com/vilderxyz/todos/watch"
+	"github.com/vilderxyz/todos/workflow"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+var todo = db.Todo{
+	Id:          123,
+	Title:       "title",
+	Description: "desc",
+	Expiry:      time.Now().Add(time.Hour),
+	Completion:  50,
+	Status:      workflow.Backlog,
+}
+
+// Returns client of Server using given mock, served over in-process listener.
+func newTestClient(t *testing.T, model *mock.MockDB) todospb.TodoServiceClient {
+	broker := watch.NewBroker()
+	return serveTestClient(t, NewServer(watch.DB(model, broker), broker))
+}
+
+// Returns client of given Server, served over in-process listener.
+func serveTestClient(t *testing.T, server *Server) todospb.TodoServiceClient {
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := server.GRPCServer()
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	return todospb.NewTodoServiceClient(conn)
+}
+
+// Expects WithTx calls running given function with the mock itself.
+func expectTx(model *mock.MockDB) {
+	model.EXPECT().
+		WithTx(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ context.Context, fn func(db.DB) error) error {
+			return fn(model)
+		})
+}
+
+func requireCode(t *testing.T, code codes.Code, err error) {
+	t.Helper()
+	require.Error(t, err)
+	require.Equal(t, code, status.Code(err), err.Error())
+}
+
+func TestCreateAndGet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	model := mock.NewMockDB(ctrl)
+	client := newTestClient(t, model)
+	expiry := time.Now().AddDate(0, 0, 2).Format("2006-01-02")
+
+	model.EXPECT().
+		CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, params db.CreateTodoParams, actor string) (db.Todo, error) {
+			require.Equal(t, "title", params.Title)
+			require.Equal(t, workflow.Backlog, params.Status)
+			require.Equal(t, "ip:bufconn", actor)
+			return todo, nil
+		})
+	created, err := client.CreateTodo(ctx, &todospb.CreateTodoRequest{Title: "title", Description: "desc", Expiry: expiry})
+	require.NoError(t, err)
+	require.Equal(t, todo.Id, created.Id)
+	require.Equal(t, todo.Expiry.Unix(), created.Expiry.AsTime().Unix())
+	require.Nil(t, created.DoneAt)
+
+	// Validation rules of http API
+	_, err = client.CreateTodo(ctx, &todospb.CreateTodoRequest{Description: "desc", Expiry: expiry})
+	requireCode(t, codes.InvalidArgument, err)
+	_, err = client.CreateTodo(ctx, &todospb.CreateTodoRequest{Title: "title", Description: "desc", Expiry: "2020-01-01"})
+	requireCode(t, codes.InvalidArgument, err)
+
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		Times(1).
+		Return(todo, nil)
+	got, err := client.GetTodo(ctx, &todospb.GetTodoRequest{Id: todo.Id})
+	require.NoError(t, err)
+	require.Equal(t, todo.Title, got.Title)
+
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(int64(1))).
+		Times(1).
+		Return(db.Todo{}, fmt.Errorf("not found"))
+	_, err = client.GetTodo(ctx, &todospb.GetTodoRequest{Id: 1})
+	requireCode(t, codes.NotFound, err)
+
+	_, err = client.GetTodo(ctx, &todospb.GetTodoRequest{})
+	requireCode(t, codes.InvalidArgument, err)
+}
+
+func TestListTodos(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	model := mock.NewMockDB(ctrl)
+	client := newTestClient(t, model)
+
+	done := todo
+	done.Id = 124
+	done.Status = workflow.Done
+	done.IsDone = true
+
+	model.EXPECT().
+		GetAllTodos(gomock.Any()).
+		Times(2).
+		Return([]db.Todo{todo, done}, nil)
+	res, err := client.ListTodos(ctx, &todospb.ListTodosRequest{})
+	require.NoError(t, err)
+	require.Len(t, res.Todos, 2)
+
+	res, err = client.ListTodos(ctx, &todospb.ListTodosRequest{Status: workflow.Done})
+	require.NoError(t, err)
+	require.Len(t, res.Todos, 1)
+	require.Equal(t, done.Id, res.Todos[0].Id)
+
+	model.EXPECT().
+		GetManyTodos(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, start, end time.Time) ([]db.Todo, error) {
+			require.Equal(t, service.PeriodEnd(service.Week, start), end)
+			return []db.Todo{todo}, nil
+		})
+	res, err = client.ListTodos(ctx, &todospb.ListTodosRequest{Period: "week"})
+	require.NoError(t, err)
+	require.Len(t, res.Todos, 1)
+
+	model.EXPECT().
+		GetArchivedTodos(gomock.Any()).
+		Times(1).
+		Return(nil, nil)
+	res, err = client.ListTodos(ctx, &todospb.ListTodosRequest{Archived: true})
+	require.NoError(t, err)
+	require.Empty(t, res.Todos)
+
+	for _, req := range []*todospb.ListTodosRequest{
+		{Period: "month"},
+		{Period: "week", Archived: true},
+		{Status: "unknown"},
+	} {
+		_, err = client.ListTodos(ctx, req)
+		requireCode(t, codes.InvalidArgument, err)
+	}
+}
+
+func TestUpdates(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	model := mock.NewMockDB(ctrl)
+	client := newTestClient(t, model)
+	expectTx(model)
+
+	model.EXPECT().
+		GetOneTodoById(gomock.Any(), gomock.Eq(todo.Id)).
+		AnyTimes().
+		Return(todo, nil)
+	model.EXPECT().
+		UpdateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(3).
+		DoAndReturn(func(_ context.Context, todo db.Todo, actor string) (db.Todo, error) {
+			return todo, nil
+		})
+
+	expiry := time.Now().AddDate(0, 0, 2).Format("2006-01-02")
+	updated, err := client.UpdateTodo(ctx, &todospb.UpdateTodoRequest{Id: todo.Id, Title: "new", Description: "desc", Expiry: expiry})
+	require.NoError(t, err)
+	require.Equal(t, "new", updated.Title)
+
+	updated, err = client.UpdateCompletion(ctx, &todospb.UpdateCompletionRequest{Id: todo.Id, Completion: 80})
+	require.NoError(t, err)
+	require.Equal(t, float32(80), updated.Completion)
+
+	// Completion cannot decrease
+	_, err = client.UpdateCompletion(ctx, &todospb.UpdateCompletionRequest{Id: todo.Id, Completion: 20})
+	requireCode(t, codes.FailedPrecondition, err)
+	_, err = client.UpdateCompletion(ctx, &todospb.UpdateCompletionRequest{Id: todo.Id, Completion: 101})
+	requireCode(t, codes.InvalidArgument, err)
+
+	updated, err = client.SetDone(ctx, &todospb.SetDoneRequest{Id: todo.Id, Done: true})
+	require.NoError(t, err)
+	require.True(t, updated.IsDone)
+	require.Equal(t, workflow.Done, updated.Status)
+
+	// Todo that is not done cannot be reopened
+	_, err = client.SetDone(ctx, &todospb.SetDoneRequest{Id: todo.Id, Done: false})
+	requireCode(t, codes.FailedPrecondition, err)
+
+	model.EXPECT().
+		DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Any()).
+		Times(1).
+		Return(nil)
+	_, err = client.DeleteTodo(ctx, &todospb.DeleteTodoRequest{Id: todo.Id})
+	require.NoError(t, err)
+}
+
+func TestAuthentication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	client := newTestClient(t, model)
+	withKey := func(key string) context.Context {
+		return metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, key)
+	}
+
+	disabled := time.Now()
+	model.EXPECT().
+		GetUserByKeyHash(gomock.Any(), gomock.Eq(api.HashAPIKey("alice"))).
+		AnyTimes().
+		Return(db.User{Name: "alice"}, nil)
+	model.EXPECT().
+		GetUserByKeyHash(gomock.Any(), gomock.Eq(api.HashAPIKey("bob"))).
+		AnyTimes().
+		Return(db.User{Name: "bob", DisabledAt: &disabled}, nil)
+	model.EXPECT().
+		GetUserByKeyHash(gomock.Any(), gomock.Eq(api.HashAPIKey("unknown"))).
+		AnyTimes().
+		Return(db.User{}, fmt.Errorf("not found"))
+
+	model.EXPECT().
+		DeleteOneTodo(gomock.Any(), gomock.Eq(todo.Id), gomock.Eq("alice")).
+		Times(1).
+		Return(nil)
+	_, err := client.DeleteTodo(withKey("alice"), &todospb.DeleteTodoRequest{Id: todo.Id})
+	require.NoError(t, err)
+
+	_, err = client.DeleteTodo(withKey("bob"), &todospb.DeleteTodoRequest{Id: todo.Id})
+	requireCode(t, codes.Unauthenticated, err)
+
+	stream, err := client.WatchTodos(withKey("unknown"), &todospb.WatchTodosRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	requireCode(t, codes.Unauthenticated, err)
+}
+
+func TestAuthenticationRateLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		GetUserByKeyHash(gomock.Any(), gomock.Any()).
+		Times(2).
+		Return(db.User{}, fmt.Errorf("not found"))
+
+	server := NewServer(model, watch.NewBroker())
+	server.AuthLimiter.Limit = ratelimit.Limit{Rate: 1, Burst: 2}
+	client := serveTestClient(t, server)
+
+	for _, key := range []string{"first", "second"} {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, key)
+		_, err := client.GetTodo(ctx, &todospb.GetTodoRequest{Id: todo.Id})
+		requireCode(t, codes.Unauthenticated, err)
+	}
+
+	// Made up keys are not looked up any more
+	ctx := metadata.AppendToOutgoingContext(context.Background(), apiKeyMetadata, "third")
+	_, err := client.GetTodo(ctx, &todospb.GetTodoRequest{Id: todo.Id})
+	requireCode(t, codes.ResourceExhausted, err)
+}
+
+func TestClientCertificate(t *testing.T) {
+	server := NewServer(nil, nil)
+	server.ClientCertUsers = certs.Users{"billing": "billing-service"}
+
+	// Returns context of call over TLS connection with verified client certificate
+	withCert := func(name string) context.Context {
+		state := tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{
+			{{Subject: pkix.Name{CommonName: name}}},
+		}}
+		return peer.NewContext(context.Background(), &peer.Peer{
+			Addr:     &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234},
+			AuthInfo: credentials.TLSInfo{State: state},
+		})
+	}
+
+	ctx, err := server.authenticate(withCert("billing"))
+	require.NoError(t, err)
+	require.Equal(t, "billing-service", actor(ctx))
+
+	// Unmapped certificates are anonymous
+	ctx, err = server.authenticate(withCert("reports"))
+	require.NoError(t, err)
+	require.Equal(t, "ip:10.0.0.1", actor(ctx))
+}
+
+func TestWatchTodos(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	model := mock.NewMockDB(ctrl)
+	client := newTestClient(t, model)
+
+	all, err := client.WatchTodos(ctx, &todospb.WatchTodosRequest{})
+	require.NoError(t, err)
+	_, err = all.Header()
+	require.NoError(t, err)
+
+	other, err := client.WatchTodos(ctx, &todospb.WatchTodosRequest{Ids: []int64{todo.Id + 1}})
+	require.NoError(t, err)
+	_, err = other.Header()
+	require.NoError(t, err)
+
+	model.EXPECT().
+		DeleteOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+		Times(2).
+		Return(nil)
+	_, err = client.DeleteTodo(ctx, &todospb.DeleteTodoRequest{Id: todo.Id})
+	require.NoError(t, err)
+	_, err = client.DeleteTodo(ctx, &todospb.DeleteTodoRequest{Id: todo.Id + 1})
+	require.NoError(t, err)
+
+	event, err := all.Recv()
+	require.NoError(t, err)
+	require.Equal(t, todospb.TodoEvent_DELETED, event.Kind)
+	require.Equal(t, todo.Id, event.Todo.Id)
+	event, err = all.Recv()
+	require.NoError(t, err)
+	require.Equal(t, todo.Id+1, event.Todo.Id)
+
+	// Watcher of other Todo gets only its changes
+	event, err = other.Recv()
+	require.NoError(t, err)
+	require.Equal(t, todo.Id+1, event.Todo.Id)
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/workflow"
+)
+
+// Titles and descriptions of sample Todos created by seed command.
+var sampleTodos = []struct {
+	title       string
+	description string
+}{
+	{"Clean house", "Vacuum living room and clean the kitchen"},
+	{"Wash car", "Take car to the car wash and clean inside"},
+	{"Buy groceries", "Milk, eggs, bread, apples and coffee"},
+	{"Pay bills", "Electricity, internet and phone bills"},
+	{"Book dentist", "Call the clinic and book a check-up"},
+	{"Renew passport", "Fill in the form and take new photos"},
+	{"Prepare presentation", "Slides for quarterly review meeting"},
+	{"Fix bike", "Replace the chain and pump tyres"},
+	{"Water plants", "Balcony and living room plants"},
+	{"Call parents", "Ask about weekend plans"},
+	{"Read book", "Finish the last three chapters"},
+	{"Plan vacation", "Compare flights and book hotel"},
+	{"Update resume", "Add latest project and skills"},
+	{"Back up laptop", "Copy photos and documents to external drive"},
+	{"Cook dinner", "Try the new pasta recipe"},
+	{"Go running", "Five kilometres around the park"},
+}
+
+// Creates given number of sample Todos with random expiry dates,
+// statuses and completion progress.
+//
+//	seed [-n count]
+func seed(args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	count := flags.Int("n", 20, "number of todos to create")
+	flags.Parse(args)
+
+	if *count < 1 {
+		return fmt.Errorf("number of todos must be positive")
+	}
+
+	conn, err := openDB()
+	if err != nil {
+		return err
+	}
+	machine, err := loadWorkflow()
+	if err != nil {
+		return err
+	}
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	if err := seedTodos(context.Background(), db.New(conn), machine, random, *count); err != nil {
+		return err
+	}
+
+	fmt.Printf("Created %d todos\n", *count)
+	return nil
+}
+
+// Creates count sample Todos in a single transaction.
+func seedTodos(ctx context.Context, queries db.DB, machine *workflow.Machine, random *rand.Rand, count int) error {
+	err := queries.WithTx(ctx, func(tx db.DB) error {
+		for i := 0; i < count; i++ {
+			if err := seedTodo(ctx, tx, machine, random); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("cannot seed todos: %w", err)
+	}
+	return nil
+}
+
+// Creates single sample Todo and moves it through a few random statuses.
+func seedTodo(ctx context.Context, queries db.DB, machine *workflow.Machine, random *rand.Rand) error {
+	sample := sampleTodos[random.Intn(len(sampleTodos))]
+	expiry := time.Now().Truncate(24*time.Hour).AddDate(0, 0, random.Intn(37)-7)
+
+	todo, err := queries.CreateOneTodo(ctx, db.CreateTodoParams{
+		Title:       sample.title,
+		Description: sample.description,
+		Expiry:      expiry,
+		Status:      machine.Initial(),
+	}, cliActor)
+	if err != nil {
+		return err
+	}
+
+	for steps := random.Intn(4); steps > 0; steps-- {
+		next := machine.Next(todo.Status)
+		if len(next) == 0 {
+			break
+		}
+		todo.Status = next[random.Intn(len(next))]
+		todo.IsDone = todo.Status == workflow.Done
+
+		switch {
+		case todo.IsDone:
+			todo.Completion = 100
+		case todo.Completion < 90:
+			todo.Completion += float32(random.Intn(90-int(todo.Completion)) + 1)
+		}
+
+		if todo, err = queries.UpdateOneTodo(ctx, todo, cliActor); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/mock"
+	"github.com/vilderxyz/todos/workflow"
+)
+
+func TestSeed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(db.DB) error) error {
+			return fn(model)
+		})
+
+	titles := map[string]bool{}
+	for _, sample := range sampleTodos {
+		titles[sample.title] = true
+	}
+
+	// Statuses of created Todos by their Ids
+	statuses := map[int64]string{}
+	model.EXPECT().
+		CreateOneTodo(gomock.Any(), gomock.Any(), cliActor).
+		Times(50).
+		DoAndReturn(func(_ context.Context, params db.CreateTodoParams, _ string) (db.Todo, error) {
+			require.True(t, titles[params.Title])
+			require.Equal(t, workflow.Default.Initial(), params.Status)
+
+			id := int64(len(statuses) + 1)
+			statuses[id] = params.Status
+			return db.Todo{Id: id, Title: params.Title, Description: params.Description, Status: params.Status}, nil
+		})
+	model.EXPECT().
+		UpdateOneTodo(gomock.Any(), gomock.Any(), cliActor).
+		AnyTimes().
+		DoAndReturn(func(_ context.Context, todo db.Todo, _ string) (db.Todo, error) {
+			require.NoError(t, workflow.Default.Transition(statuses[todo.Id], todo.Status))
+			require.Equal(t, todo.Status == workflow.Done, todo.IsDone)
+			require.True(t, todo.Completion >= 0 && todo.Completion <= 100)
+			if todo.IsDone {
+				require.Equal(t, float32(100), todo.Completion)
+			}
+
+			statuses[todo.Id] = todo.Status
+			return todo, nil
+		})
+
+	random := rand.New(rand.NewSource(1))
+	require.NoError(t, seedTodos(context.Background(), model, workflow.Default, random, 50))
+	require.Len(t, statuses, 50)
+}
+
+func TestSeedCount(t *testing.T) {
+	require.EqualError(t, seed([]string{"-n", "0"}), "number of todos must be positive")
+}
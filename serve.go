@@ -0,0 +1,276 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/vilderxyz/todos/api"
+	"github.com/vilderxyz/todos/cache"
+	"github.com/vilderxyz/todos/certs"
+	"github.com/vilderxyz/todos/config"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/logging"
+	"github.com/vilderxyz/todos/metrics"
+	"github.com/vilderxyz/todos/rpc"
+	"github.com/vilderxyz/todos/tracing"
+	"github.com/vilderxyz/todos/watch"
+	"github.com/vilderxyz/todos/worker"
+	"golang.org/x/exp/slog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"gorm.io/gorm"
+)
+
+// Runs http server, and gRPC server when its address is set,
+// configured with file, environment variables and flags given in args.
+//
+// Applies pending migrations first unless MIGRATE_ON_START is "false".
+// Both servers use TLS when TLS_CERT_FILE is set.
+//
+// On SIGINT or SIGTERM /readyz fails for SHUTDOWN_DELAY, then servers stop accepting
+// new requests and those in progress get the rest of SHUTDOWN_TIMEOUT to finish,
+// before workers stop and db connections close.
+func serve(args []string) error {
+	cfg, err := config.Load(args)
+	if err != nil {
+		return err
+	}
+
+	// Records of standard log package are written by logger as well
+	logger, err := logging.New(os.Stderr, cfg.Log.Options())
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(logger)
+
+	// Spans are exported by stdout exporter to stdout, separately from logs
+	stopTracing, err := tracing.Setup(context.Background(), cfg.Tracing.Options(), os.Stdout)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := stopTracing(ctx); err != nil {
+			logger.Error("cannot flush spans", "error", err)
+		}
+	}()
+
+	conn, err := connect(cfg.DB, logging.NewGormLogger(logger, cfg.Log.Options()))
+	if err != nil {
+		return err
+	}
+	if err := conn.Use(tracing.GormPlugin{}); err != nil {
+		return err
+	}
+
+	migrator, err := db.NewMigrator(conn)
+	if err != nil {
+		return fmt.Errorf("cannot load migrations: %w", err)
+	}
+	if cfg.DB.MigrateOnStart {
+		if _, err := migrator.Up(context.Background()); err != nil {
+			return fmt.Errorf("cannot migrate db: %w", err)
+		}
+	}
+
+	var m *metrics.Metrics
+	if cfg.Features.Metrics {
+		m = metrics.New()
+	}
+
+	server := api.NewServer(conn, func(s *api.Server) {
+		s.CORS = api.CORS{
+			AllowOrigins:     cfg.CORS.AllowOrigins,
+			AllowMethods:     cfg.CORS.AllowMethods,
+			AllowHeaders:     cfg.CORS.AllowHeaders,
+			AllowCredentials: cfg.CORS.AllowCredentials,
+			MaxAge:           cfg.CORS.MaxAge,
+		}
+		s.SecurityHeaders = api.SecurityHeaders{
+			HSTS:         cfg.Security.HSTS,
+			FrameOptions: cfg.Security.FrameOptions,
+		}
+		s.GraphQLEnabled = cfg.Features.GraphQL
+		s.DocsEnabled = cfg.Features.Docs
+		s.Metrics = m
+		s.Logger = logger
+	})
+
+	server.Queries = tracing.DB(server.Queries)
+
+	if m != nil {
+		pool, err := conn.DB()
+		if err != nil {
+			return err
+		}
+		if err := m.RegisterPool(pool); err != nil {
+			return err
+		}
+		if err := m.RegisterTodoCounts(server.Queries, cfg.Server.QueryTimeout); err != nil {
+			return err
+		}
+		server.Queries = metrics.DB(server.Queries, m)
+	}
+
+	// Changes of todos made through any API are streamed to gRPC watchers
+	broker := watch.NewBroker()
+	server.Queries = watch.DB(server.Queries, broker)
+
+	// Cache is cleared by changes made through any API and by workers
+	if cfg.Cache.Size > 0 {
+		server.Queries = cache.DB(server.Queries, cfg.Cache.Size, cfg.Cache.TTL, m)
+	}
+
+	server.ReadLimiter.Limit, server.WriteLimiter.Limit, server.AuthLimiter.Limit, err = cfg.RateLimit.Limits()
+	if err != nil {
+		return err
+	}
+	server.IdempotencyTTL = cfg.Server.IdempotencyTTL
+	server.QueryTimeout = cfg.Server.QueryTimeout
+	server.GraphQLMaxDepth = cfg.GraphQL.MaxDepth
+	server.GraphQLMaxComplexity = cfg.GraphQL.MaxComplexity
+
+	server.Workflow, err = cfg.StatusWorkflow()
+	if err != nil {
+		return err
+	}
+	server.ClientCertUsers, err = cfg.TLS.Users()
+	if err != nil {
+		return err
+	}
+
+	var workers []*worker.Worker
+	if retention := cfg.Workers.TrashRetention; retention > 0 {
+		workers = append(workers, worker.Start(worker.PurgeTrash(server.Queries, retention, time.Hour)))
+	}
+	if age := cfg.Workers.ArchiveAfter; age > 0 {
+		workers = append(workers, worker.Start(worker.ArchiveDone(server.Queries, age, time.Hour)))
+	}
+
+	server.HealthCheckTimeout = cfg.Server.HealthCheckTimeout
+	server.ShutdownDelay = cfg.Server.ShutdownDelay
+	server.ReadinessChecks = append(server.ReadinessChecks, api.MigrationsCheck(migrator), api.WorkersCheck(workers...))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Errors of servers, each of them sends at most one
+	errs := make(chan error, 2)
+
+	// Both servers share TLS configuration and its reloaded certificate
+	var grpcOpts []grpc.ServerOption
+	if cfg.TLS.Enabled() {
+		tlsConfig, reloader, err := certs.NewConfig(cfg.TLS.Options())
+		if err != nil {
+			shutdown(nil, nil, workers, conn, cfg.Server.ShutdownTimeout)
+			return fmt.Errorf("cannot configure TLS: %w", err)
+		}
+		if cfg.TLS.ReloadInterval > 0 {
+			go reloader.Watch(ctx, cfg.TLS.ReloadInterval, logger)
+		}
+		server.TLSConfig = tlsConfig
+		grpcOpts = append(grpcOpts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	var grpcServer *grpc.Server
+	if addr := cfg.GRPC.Addr; addr != "" {
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			shutdown(nil, nil, workers, conn, cfg.Server.ShutdownTimeout)
+			return fmt.Errorf("cannot listen on GRPC_ADDR: %w", err)
+		}
+
+		rpcServer := rpc.NewServer(server.Queries, broker)
+		rpcServer.Workflow = server.Workflow
+		rpcServer.ClientCertUsers = server.ClientCertUsers
+		rpcServer.AuthLimiter = server.AuthLimiter
+		grpcServer = rpcServer.GRPCServer(grpcOpts...)
+
+		go func() {
+			logger.Info("serving gRPC", "addr", addr)
+			if err := grpcServer.Serve(listener); err != nil {
+				errs <- fmt.Errorf("gRPC server stopped: %w", err)
+			}
+		}()
+	}
+
+	server.Timeouts = api.Timeouts{
+		Read:       cfg.Server.ReadTimeout,
+		ReadHeader: cfg.Server.ReadHeaderTimeout,
+		Write:      cfg.Server.WriteTimeout,
+		Idle:       cfg.Server.IdleTimeout,
+	}
+	if err := server.Listen(cfg.Server.Addr); err != nil {
+		shutdown(nil, grpcServer, workers, conn, cfg.Server.ShutdownTimeout)
+		return fmt.Errorf("cannot start server: %w", err)
+	}
+	if cfg.Server.PublicURL != "" {
+		logger.Info("API is available", "url", cfg.Server.PublicURL)
+	}
+	go func() {
+		if err := server.Serve(); err != nil {
+			errs <- fmt.Errorf("server stopped: %w", err)
+		}
+	}()
+
+	select {
+	case <-ctx.Done():
+		logger.Info("shutting down, waiting for requests in progress", "timeout", cfg.Server.ShutdownTimeout)
+		err = nil
+	case err = <-errs:
+	}
+	// Second signal kills the process without waiting
+	stop()
+
+	if shutdownErr := shutdown(server, grpcServer, workers, conn, cfg.Server.ShutdownTimeout); err == nil {
+		err = shutdownErr
+	}
+	return err
+}
+
+// Stops serving and releases resources in order: drains http server,
+// then gRPC server, stops background workers and finally closes db connections.
+//
+// Servers get timeout to finish requests in progress, remaining ones are cut off.
+// Nil server is skipped.
+func shutdown(server *api.Server, grpcServer *grpc.Server, workers []*worker.Worker, conn *gorm.DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var err error
+	if server != nil {
+		if err = server.Shutdown(ctx); err != nil {
+			err = fmt.Errorf("requests in progress did not finish: %w", err)
+		}
+	}
+
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			grpcServer.Stop()
+		}
+	}
+
+	for _, w := range workers {
+		w.Stop()
+	}
+
+	if sqlDB, dbErr := conn.DB(); dbErr == nil {
+		if dbErr = sqlDB.Close(); dbErr != nil && err == nil {
+			err = fmt.Errorf("cannot close db connections: %w", dbErr)
+		}
+	}
+	return err
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"time"
+
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/workflow"
+)
+
+// Layout of dates accepted by Service
+const DateLayout = "2006-01-02"
+
+// Change of Todo following rules of a workflow.
+//
+// Arguments of change are checked before Todo is loaded,
+// rules depending on Todo's state when it is applied.
+type Change struct {
+	check func(machine *workflow.Machine) error
+	apply func(machine *workflow.Machine, todo *db.Todo) error
+}
+
+// Throws ErrInvalid error when arguments of change break rules of given workflow.
+func (c Change) Validate(machine *workflow.Machine) error {
+	if c.check == nil {
+		return nil
+	}
+	return c.check(machine)
+}
+
+// Applies change to Todo following rules of given workflow.
+//
+// Throws an error and leaves Todo untouched when change breaks any rule.
+func (c Change) Apply(machine *workflow.Machine, todo *db.Todo) error {
+	if err := c.Validate(machine); err != nil {
+		return err
+	}
+	return c.apply(machine, todo)
+}
+
+// Text information and expiry of Todo.
+//
+// Title and Description cannot be empty and Expiry must be a future date.
+type TodoInfo struct {
+	Title       string
+	Description string
+	Expiry      time.Time
+}
+
+// Throws ErrInvalid error when info breaks any rule.
+func (info TodoInfo) validate() error {
+	if info.Title == "" || info.Description == "" {
+		return invalid("title and description are required")
+	}
+	if info.Expiry.Before(time.Now()) {
+		return invalid("wrong date")
+	}
+	return nil
+}
+
+// Parses expiry date in DateLayout.
+//
+// Throws ErrInvalid error when date is malformed.
+func ParseExpiry(expiry string) (time.Time, error) {
+	expiryTime, err := time.Parse(DateLayout, expiry)
+	if err != nil {
+		return expiryTime, invalid("%v", err)
+	}
+	return expiryTime, nil
+}
+
+// Returns Todo's status in given workflow.
+//
+// Todos created before statuses were introduced have none,
+// so it is derived from IsDone for them.
+func CurrentStatus(machine *workflow.Machine, todo db.Todo) string {
+	if todo.Status != "" {
+		return todo.Status
+	}
+	if todo.IsDone {
+		return workflow.Done
+	}
+	return machine.Initial()
+}
+
+// Moves Todo to given status and keeps IsDone consistent with it.
+func setStatus(todo *db.Todo, status string) {
+	todo.Status = status
+	todo.IsDone = status == workflow.Done
+}
+
+// Replaces Title, Description and Expiry of Todo.
+func Info(info TodoInfo) Change {
+	return Change{
+		check: func(_ *workflow.Machine) error {
+			return info.validate()
+		},
+		apply: func(_ *workflow.Machine, todo *db.Todo) error {
+			todo.Title = info.Title
+			todo.Description = info.Description
+			todo.Expiry = info.Expiry
+			return nil
+		},
+	}
+}
+
+// Replaces Todo's completion progress.
+//
+// Throws ErrConflict error when requested completion value is lower than the actual one,
+// unless Todo is in the status reopened Todos move to.
+func Completion(completion float32) Change {
+	return Change{
+		check: func(_ *workflow.Machine) error {
+			if completion < 0 || completion > 100 {
+				return invalid("completion must be between 0 and 100")
+			}
+			return nil
+		},
+		apply: func(machine *workflow.Machine, todo *db.Todo) error {
+			reopened, ok := machine.Reopen()
+			if todo.Completion >= completion && (!ok || CurrentStatus(machine, *todo) != reopened) {
+				return conflict("requsted completion progress is lower then the actual one")
+			}
+			todo.Completion = completion
+			return nil
+		},
+	}
+}
+
+// Moves Todo to "done" status or reopens it.
+//
+// Throws ErrConflict error when Todo is already finished or not finished when reopening,
+// and when workflow does not allow such transition.
+func Done(done bool) Change {
+	return Change{apply: func(machine *workflow.Machine, todo *db.Todo) error {
+		current := CurrentStatus(machine, *todo)
+		status := workflow.Done
+		if done {
+			if current == workflow.Done {
+				return conflict("todo is already done")
+			}
+		} else {
+			if current != workflow.Done {
+				return conflict("todo is not done")
+			}
+			var ok bool
+			if status, ok = machine.Reopen(); !ok {
+				return conflict("todo cannot be reopened")
+			}
+		}
+
+		if err := machine.Transition(current, status); err != nil {
+			return conflict("%v", err)
+		}
+
+		setStatus(todo, status)
+		return nil
+	}}
+}
+
+// Moves Todo to given status.
+//
+// Throws ErrInvalid error when workflow has no such status
+// and ErrConflict error when it does not allow such transition.
+func Status(status string) Change {
+	return Change{
+		check: func(machine *workflow.Machine) error {
+			if !machine.Valid(status) {
+				return invalid("unknown status %q", status)
+			}
+			return nil
+		},
+		apply: func(machine *workflow.Machine, todo *db.Todo) error {
+			if err := machine.Transition(CurrentStatus(machine, *todo), status); err != nil {
+				return conflict("%v", err)
+			}
+
+			setStatus(todo, status)
+			return nil
+		},
+	}
+}
+
+// Archives or unarchives Todo.
+//
+// Throws ErrConflict error when Todo already is in requested state.
+func Archived(archived bool) Change {
+	return Change{apply: func(_ *workflow.Machine, todo *db.Todo) error {
+		if archived {
+			if todo.ArchivedAt != nil {
+				return conflict("todo is already archived")
+			}
+			now := time.Now()
+			todo.ArchivedAt = &now
+		} else {
+			if todo.ArchivedAt == nil {
+				return conflict("todo is not archived")
+			}
+			todo.ArchivedAt = nil
+		}
+		return nil
+	}}
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of errors returned by Service, matched with errors.Is.
+var (
+	// Todo does not exist
+	ErrNotFound = errors.New("not found")
+
+	// Input breaks rules of Todos, e.g. expiry date is in the past
+	ErrInvalid = errors.New("invalid")
+
+	// Change is not allowed in Todo's current state, e.g. finishing already done Todo
+	ErrConflict = errors.New("conflict")
+)
+
+// Error of broken rule.
+//
+// Message describes what was broken and can be sent to clients as it is.
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Reports whether target is the kind of e.
+func (e *Error) Is(target error) bool {
+	return e.Kind == target
+}
+
+func invalid(format string, args ...any) error {
+	return &Error{Kind: ErrInvalid, Message: fmt.Sprintf(format, args...)}
+}
+
+func conflict(format string, args ...any) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// Returns ErrNotFound error for queries that found no Todo.
+// Other errors are returned as they are.
+func queryError(err error) error {
+	if err != nil && err.Error() == "not found" {
+		return &Error{Kind: ErrNotFound, Message: err.Error()}
+	}
+	return err
+}
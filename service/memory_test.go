@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/vilderxyz/todos/db"
+)
+
+// In-memory db.DB storing Todos used by Service.
+//
+// Methods that Service does not use are left to nil embedded DB and panic.
+type memoryDB struct {
+	db.DB
+
+	todos  map[int64]db.Todo
+	nextId int64
+
+	// Number of started transactions
+	txs int
+}
+
+func newMemoryDB(todos ...db.Todo) *memoryDB {
+	m := &memoryDB{todos: map[int64]db.Todo{}}
+	for _, todo := range todos {
+		m.todos[todo.Id] = todo
+		if todo.Id > m.nextId {
+			m.nextId = todo.Id
+		}
+	}
+	return m
+}
+
+func (m *memoryDB) CreateOneTodo(_ context.Context, params db.CreateTodoParams, _ string) (db.Todo, error) {
+	m.nextId++
+	todo := db.Todo{
+		Id:          m.nextId,
+		Title:       params.Title,
+		Description: params.Description,
+		Expiry:      params.Expiry,
+		Status:      params.Status,
+	}
+	m.todos[todo.Id] = todo
+	return todo, nil
+}
+
+func (m *memoryDB) GetOneTodoById(_ context.Context, id int64) (db.Todo, error) {
+	todo, ok := m.todos[id]
+	if !ok {
+		return db.Todo{}, errors.New("not found")
+	}
+	return todo, nil
+}
+
+func (m *memoryDB) UpdateOneTodo(_ context.Context, todo db.Todo, _ string) (db.Todo, error) {
+	m.todos[todo.Id] = todo
+	return todo, nil
+}
+
+func (m *memoryDB) DeleteOneTodo(_ context.Context, id int64, _ string) error {
+	if _, ok := m.todos[id]; !ok {
+		return errors.New("not found")
+	}
+	delete(m.todos, id)
+	return nil
+}
+
+func (m *memoryDB) GetAllTodos(context.Context) ([]db.Todo, error) {
+	return m.list(func(todo db.Todo) bool { return todo.ArchivedAt == nil }), nil
+}
+
+func (m *memoryDB) GetArchivedTodos(context.Context) ([]db.Todo, error) {
+	return m.list(func(todo db.Todo) bool { return todo.ArchivedAt != nil }), nil
+}
+
+func (m *memoryDB) GetManyTodos(_ context.Context, start, end time.Time) ([]db.Todo, error) {
+	return m.list(func(todo db.Todo) bool {
+		return todo.ArchivedAt == nil && !todo.IsDone && todo.Expiry.After(start) && todo.Expiry.Before(end)
+	}), nil
+}
+
+// Runs fn on the same storage and restores its previous state when fn fails.
+func (m *memoryDB) WithTx(_ context.Context, fn func(db.DB) error) error {
+	m.txs++
+	saved := make(map[int64]db.Todo, len(m.todos))
+	for id, todo := range m.todos {
+		saved[id] = todo
+	}
+
+	err := fn(m)
+	if err != nil {
+		m.todos = saved
+	}
+	return err
+}
+
+// Returns Todos accepted by filter ordered by Id.
+func (m *memoryDB) list(filter func(db.Todo) bool) []db.Todo {
+	res := []db.Todo{}
+	for id := int64(1); id <= m.nextId; id++ {
+		if todo, ok := m.todos[id]; ok && filter(todo) {
+			res = append(res, todo)
+		}
+	}
+	return res
+}
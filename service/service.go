@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/workflow"
+)
+
+// Periods of time that Todos can be listed for.
+const (
+	Today    = "today"
+	Tomorrow = "tomorrow"
+	Week     = "week"
+)
+
+// Use cases of Todos shared by all APIs.
+//
+// It owns rules of Todos, so handlers only translate requests to its calls
+// and its typed errors to responses.
+type Service struct {
+	Queries db.DB
+
+	// Allowed transitions between Todo statuses
+	Workflow *workflow.Machine
+}
+
+// Creates a new Service using given queries and workflow.
+func New(queries db.DB, machine *workflow.Machine) *Service {
+	return &Service{
+		Queries:  queries,
+		Workflow: machine,
+	}
+}
+
+// Stores new Todo in workflow's initial status.
+//
+// Throws ErrInvalid error when info breaks any rule.
+func (s *Service) CreateTodo(ctx context.Context, info TodoInfo, actor string) (db.Todo, error) {
+	if err := info.validate(); err != nil {
+		return db.Todo{}, err
+	}
+
+	return s.Queries.CreateOneTodo(ctx, db.CreateTodoParams{
+		Title:       info.Title,
+		Description: info.Description,
+		Expiry:      info.Expiry,
+		Status:      s.Workflow.Initial(),
+	}, actor)
+}
+
+// Returns Todo with given Id.
+//
+// Throws ErrNotFound error when there is none.
+func (s *Service) GetTodo(ctx context.Context, id int64) (db.Todo, error) {
+	todo, err := s.Queries.GetOneTodoById(ctx, id)
+	return todo, queryError(err)
+}
+
+// Filters of ListTodos, all of them can be omitted.
+//
+// Period is one of [ "today" , "tomorrow" , "week" ] and selects
+// unfinished Todos expiring before its end. Archived selects only
+// archived Todos and cannot be combined with Period.
+// Status selects Todos in given status of the workflow.
+type ListParams struct {
+	Period   string
+	Archived bool
+	Status   string
+}
+
+// Returns Todos selected by given filters.
+//
+// Throws ErrInvalid error when filters are unknown or cannot be combined.
+func (s *Service) ListTodos(ctx context.Context, params ListParams) ([]db.Todo, error) {
+	if params.Archived && params.Period != "" {
+		return nil, invalid("period cannot be used with archived")
+	}
+	if params.Status != "" && !s.Workflow.Valid(params.Status) {
+		return nil, invalid("unknown status %q", params.Status)
+	}
+
+	var todos []db.Todo
+	var err error
+	switch params.Period {
+	case Today, Tomorrow, Week:
+		now := time.Now()
+		todos, err = s.Queries.GetManyTodos(ctx, now, PeriodEnd(params.Period, now))
+	case "":
+		if params.Archived {
+			todos, err = s.Queries.GetArchivedTodos(ctx)
+		} else {
+			todos, err = s.Queries.GetAllTodos(ctx)
+		}
+	default:
+		return nil, invalid("wrong param")
+	}
+	if err != nil || params.Status == "" {
+		return todos, err
+	}
+
+	res := []db.Todo{}
+	for _, todo := range todos {
+		if CurrentStatus(s.Workflow, todo) == params.Status {
+			res = append(res, todo)
+		}
+	}
+	return res, nil
+}
+
+// Returns midnight ending given period that starts at now.
+//
+// Period must be one of [ "today" , "tomorrow" , "week" ].
+func PeriodEnd(period string, now time.Time) time.Time {
+	days := 1
+	switch period {
+	case Tomorrow:
+		days = 2
+	case Week:
+		days = 8 - int(now.Weekday())
+	}
+	end := now.AddDate(0, 0, days)
+	return time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Applies change to Todo with given Id in transaction and stores it back in database.
+//
+// Throws ErrNotFound error when there is no such Todo and errors of change
+// when it breaks any rule, nothing is stored then.
+func (s *Service) Update(ctx context.Context, id int64, change Change, actor string) (db.Todo, error) {
+	if err := change.Validate(s.Workflow); err != nil {
+		return db.Todo{}, err
+	}
+
+	var res db.Todo
+	err := s.Queries.WithTx(ctx, func(tx db.DB) error {
+		var err error
+		res, err = New(tx, s.Workflow).Apply(ctx, id, change, actor)
+		return err
+	})
+	return res, err
+}
+
+// Applies change to Todo with given Id like Update, but without starting a transaction.
+//
+// It is meant for callers managing transactions themselves, like batches of changes.
+func (s *Service) Apply(ctx context.Context, id int64, change Change, actor string) (db.Todo, error) {
+	if err := change.Validate(s.Workflow); err != nil {
+		return db.Todo{}, err
+	}
+
+	todo, err := s.Queries.GetOneTodoById(ctx, id)
+	if err != nil {
+		return db.Todo{}, queryError(err)
+	}
+
+	if err := change.apply(s.Workflow, &todo); err != nil {
+		return db.Todo{}, err
+	}
+
+	return s.Queries.UpdateOneTodo(ctx, todo, actor)
+}
+
+// Moves Todo with given Id to trash.
+//
+// Throws ErrNotFound error when there is no such Todo.
+func (s *Service) DeleteTodo(ctx context.Context, id int64, actor string) error {
+	return queryError(s.Queries.DeleteOneTodo(ctx, id, actor))
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/workflow"
+)
+
+const testActor = "tester"
+
+var (
+	ctx      = context.Background()
+	tomorrow = time.Now().AddDate(0, 0, 1)
+)
+
+func TestCreateTodo(t *testing.T) {
+	queries := newMemoryDB()
+	service := New(queries, workflow.Default)
+
+	todo, err := service.CreateTodo(ctx, TodoInfo{Title: "title", Description: "desc", Expiry: tomorrow}, testActor)
+	require.NoError(t, err)
+	require.Equal(t, workflow.Backlog, todo.Status)
+	require.Len(t, queries.todos, 1)
+
+	_, err = service.CreateTodo(ctx, TodoInfo{Title: "title", Description: "desc", Expiry: time.Now().AddDate(0, 0, -1)}, testActor)
+	require.ErrorIs(t, err, ErrInvalid)
+	require.EqualError(t, err, "wrong date")
+
+	_, err = service.CreateTodo(ctx, TodoInfo{Title: "title", Expiry: tomorrow}, testActor)
+	require.ErrorIs(t, err, ErrInvalid)
+	require.Len(t, queries.todos, 1)
+}
+
+func TestParseExpiry(t *testing.T) {
+	expiry, err := ParseExpiry("2022-12-23")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2022, 12, 23, 0, 0, 0, 0, time.UTC), expiry)
+
+	_, err = ParseExpiry("2022-13-23")
+	require.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestChanges(t *testing.T) {
+	archivedAt := time.Now()
+
+	testCases := []struct {
+		name   string
+		todo   db.Todo
+		change Change
+		err    error
+		check  func(t *testing.T, todo db.Todo)
+	}{
+		{
+			name:   "Info",
+			change: Info(TodoInfo{Title: "new", Description: "desc", Expiry: tomorrow}),
+			check: func(t *testing.T, todo db.Todo) {
+				require.Equal(t, "new", todo.Title)
+				require.Equal(t, tomorrow, todo.Expiry)
+			},
+		},
+		{
+			name:   "Info - expiry in the past",
+			change: Info(TodoInfo{Title: "new", Description: "desc", Expiry: time.Now().AddDate(0, 0, -1)}),
+			err:    ErrInvalid,
+		},
+		{
+			name:   "Completion",
+			todo:   db.Todo{Completion: 10},
+			change: Completion(50),
+			check: func(t *testing.T, todo db.Todo) {
+				require.Equal(t, float32(50), todo.Completion)
+			},
+		},
+		{
+			name:   "Completion - lower",
+			todo:   db.Todo{Completion: 50},
+			change: Completion(10),
+			err:    ErrConflict,
+		},
+		{
+			name:   "Completion - lower after reopening",
+			todo:   db.Todo{Completion: 50, Status: workflow.Reopened},
+			change: Completion(10),
+			check: func(t *testing.T, todo db.Todo) {
+				require.Equal(t, float32(10), todo.Completion)
+			},
+		},
+		{
+			name:   "Completion - out of range",
+			change: Completion(101),
+			err:    ErrInvalid,
+		},
+		{
+			name:   "Done",
+			todo:   db.Todo{Status: workflow.InProgress},
+			change: Done(true),
+			check: func(t *testing.T, todo db.Todo) {
+				require.Equal(t, workflow.Done, todo.Status)
+				require.True(t, todo.IsDone)
+			},
+		},
+		{
+			name:   "Done - twice",
+			todo:   db.Todo{Status: workflow.Done, IsDone: true},
+			change: Done(true),
+			err:    ErrConflict,
+		},
+		{
+			name:   "Done - status derived from IsDone",
+			todo:   db.Todo{IsDone: true},
+			change: Done(true),
+			err:    ErrConflict,
+		},
+		{
+			name:   "Done - not allowed by workflow",
+			todo:   db.Todo{Status: workflow.Blocked},
+			change: Done(true),
+			err:    ErrConflict,
+		},
+		{
+			name:   "Reopen",
+			todo:   db.Todo{Status: workflow.Done, IsDone: true},
+			change: Done(false),
+			check: func(t *testing.T, todo db.Todo) {
+				require.Equal(t, workflow.Reopened, todo.Status)
+				require.False(t, todo.IsDone)
+			},
+		},
+		{
+			name:   "Reopen - not done",
+			todo:   db.Todo{Status: workflow.InProgress},
+			change: Done(false),
+			err:    ErrConflict,
+		},
+		{
+			name:   "Status",
+			todo:   db.Todo{Status: workflow.Backlog},
+			change: Status(workflow.InProgress),
+			check: func(t *testing.T, todo db.Todo) {
+				require.Equal(t, workflow.InProgress, todo.Status)
+			},
+		},
+		{
+			name:   "Status - unknown",
+			change: Status("waiting"),
+			err:    ErrInvalid,
+		},
+		{
+			name:   "Status - not allowed",
+			todo:   db.Todo{Status: workflow.Done},
+			change: Status(workflow.InProgress),
+			err:    ErrConflict,
+		},
+		{
+			name:   "Archived",
+			change: Archived(true),
+			check: func(t *testing.T, todo db.Todo) {
+				require.NotNil(t, todo.ArchivedAt)
+			},
+		},
+		{
+			name:   "Archived - twice",
+			todo:   db.Todo{ArchivedAt: &archivedAt},
+			change: Archived(true),
+			err:    ErrConflict,
+		},
+		{
+			name:   "Unarchived - not archived",
+			change: Archived(false),
+			err:    ErrConflict,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			todo := tc.todo
+			err := tc.change.Apply(workflow.Default, &todo)
+			if tc.err != nil {
+				require.ErrorIs(t, err, tc.err)
+				require.Equal(t, tc.todo, todo)
+				return
+			}
+			require.NoError(t, err)
+			tc.check(t, todo)
+		})
+	}
+}
+
+func TestCompletionCustomWorkflow(t *testing.T) {
+	machine, err := workflow.Parse("todo>doing,done;doing>todo,done;done>again;again>done")
+	require.NoError(t, err)
+
+	// Todos moved to the status reopened ones move to may lower their completion
+	todo := db.Todo{Completion: 50, Status: "again"}
+	require.NoError(t, Completion(10).Apply(machine, &todo))
+	require.Equal(t, float32(10), todo.Completion)
+
+	todo = db.Todo{Completion: 50, Status: "doing"}
+	require.ErrorIs(t, Completion(10).Apply(machine, &todo), ErrConflict)
+
+	// Without reopening nothing can lower completion
+	machine, err = workflow.Parse("todo>done;done>")
+	require.NoError(t, err)
+	todo = db.Todo{Completion: 50, Status: "todo"}
+	require.ErrorIs(t, Completion(10).Apply(machine, &todo), ErrConflict)
+}
+
+func TestUpdate(t *testing.T) {
+	queries := newMemoryDB(db.Todo{Id: 1, Title: "title", Completion: 50, Status: workflow.InProgress})
+	service := New(queries, workflow.Default)
+
+	todo, err := service.Update(ctx, 1, Done(true), testActor)
+	require.NoError(t, err)
+	require.Equal(t, workflow.Done, queries.todos[1].Status)
+	require.Equal(t, todo, queries.todos[1])
+	require.Equal(t, 1, queries.txs)
+
+	// Broken rules store nothing
+	_, err = service.Update(ctx, 1, Completion(10), testActor)
+	require.ErrorIs(t, err, ErrConflict)
+	require.Equal(t, float32(50), queries.todos[1].Completion)
+
+	// Invalid arguments are rejected before transaction
+	_, err = service.Update(ctx, 1, Status("waiting"), testActor)
+	require.ErrorIs(t, err, ErrInvalid)
+	require.Equal(t, 2, queries.txs)
+
+	_, err = service.Update(ctx, 2, Done(true), testActor)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.EqualError(t, err, "not found")
+}
+
+func TestGetAndDeleteTodo(t *testing.T) {
+	queries := newMemoryDB(db.Todo{Id: 1, Title: "title"})
+	service := New(queries, workflow.Default)
+
+	todo, err := service.GetTodo(ctx, 1)
+	require.NoError(t, err)
+	require.Equal(t, "title", todo.Title)
+
+	require.NoError(t, service.DeleteTodo(ctx, 1, testActor))
+
+	_, err = service.GetTodo(ctx, 1)
+	require.ErrorIs(t, err, ErrNotFound)
+	require.ErrorIs(t, service.DeleteTodo(ctx, 1, testActor), ErrNotFound)
+}
+
+func TestListTodos(t *testing.T) {
+	archivedAt := time.Now()
+	queries := newMemoryDB(
+		db.Todo{Id: 1, Expiry: time.Now().Add(time.Minute), Status: workflow.Backlog},
+		db.Todo{Id: 2, Expiry: time.Now().AddDate(0, 1, 0), Status: workflow.InProgress},
+		db.Todo{Id: 3, Expiry: time.Now().Add(time.Minute), Status: workflow.Done, IsDone: true},
+		db.Todo{Id: 4, Expiry: time.Now().Add(time.Minute), ArchivedAt: &archivedAt},
+	)
+	service := New(queries, workflow.Default)
+
+	ids := func(params ListParams) []int64 {
+		todos, err := service.ListTodos(ctx, params)
+		require.NoError(t, err)
+		res := []int64{}
+		for _, todo := range todos {
+			res = append(res, todo.Id)
+		}
+		return res
+	}
+
+	require.Equal(t, []int64{1, 2, 3}, ids(ListParams{}))
+	require.Equal(t, []int64{4}, ids(ListParams{Archived: true}))
+	require.Equal(t, []int64{1}, ids(ListParams{Period: Week}))
+	require.Equal(t, []int64{2}, ids(ListParams{Status: workflow.InProgress}))
+	require.Equal(t, []int64{3}, ids(ListParams{Status: workflow.Done}))
+
+	_, err := service.ListTodos(ctx, ListParams{Period: Today, Archived: true})
+	require.ErrorIs(t, err, ErrInvalid)
+	_, err = service.ListTodos(ctx, ListParams{Period: "month"})
+	require.ErrorIs(t, err, ErrInvalid)
+	_, err = service.ListTodos(ctx, ListParams{Status: "waiting"})
+	require.ErrorIs(t, err, ErrInvalid)
+}
+
+func TestPeriodEnd(t *testing.T) {
+	// Wednesday
+	now := time.Date(2022, 12, 21, 15, 4, 5, 0, time.UTC)
+
+	require.Equal(t, time.Date(2022, 12, 22, 0, 0, 0, 0, time.UTC), PeriodEnd(Today, now))
+	require.Equal(t, time.Date(2022, 12, 23, 0, 0, 0, 0, time.UTC), PeriodEnd(Tomorrow, now))
+	require.Equal(t, time.Date(2022, 12, 26, 0, 0, 0, 0, time.UTC), PeriodEnd(Week, now))
+}
@@ -0,0 +1,188 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/vilderxyz/todos/db"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Decorator of db.DB that runs every query in its own span named "db.<method>",
+// a child of the span carried by query's context.
+//
+// Queries run within WithTx get their own spans as well, children of spans
+// carried by their contexts. Missing records are not recorded as errors.
+type traced struct {
+	queries db.DB
+	tracer  trace.Tracer
+}
+
+// Returns queries tracing their calls with global tracer provider.
+func DB(queries db.DB) db.DB {
+	return &traced{queries: queries, tracer: Tracer()}
+}
+
+// Starts span of query.
+func (t *traced) start(ctx context.Context, query string) (context.Context, trace.Span) {
+	return t.tracer.Start(ctx, "db."+query, trace.WithSpanKind(trace.SpanKindInternal))
+}
+
+// Records error of query and ends its span.
+func end(span trace.Span, err error) {
+	if err != nil && err.Error() != "not found" {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (t *traced) GetAllTodos(ctx context.Context) ([]db.Todo, error) {
+	ctx, span := t.start(ctx, "GetAllTodos")
+	res, err := t.queries.GetAllTodos(ctx)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) GetManyTodos(ctx context.Context, from, to time.Time) ([]db.Todo, error) {
+	ctx, span := t.start(ctx, "GetManyTodos")
+	res, err := t.queries.GetManyTodos(ctx, from, to)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) GetOneTodoById(ctx context.Context, id int64) (db.Todo, error) {
+	ctx, span := t.start(ctx, "GetOneTodoById")
+	res, err := t.queries.GetOneTodoById(ctx, id)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) UpdateOneTodo(ctx context.Context, todo db.Todo, actor string) (db.Todo, error) {
+	ctx, span := t.start(ctx, "UpdateOneTodo")
+	res, err := t.queries.UpdateOneTodo(ctx, todo, actor)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) DeleteOneTodo(ctx context.Context, id int64, actor string) error {
+	ctx, span := t.start(ctx, "DeleteOneTodo")
+	err := t.queries.DeleteOneTodo(ctx, id, actor)
+	end(span, err)
+	return err
+}
+
+func (t *traced) CreateOneTodo(ctx context.Context, params db.CreateTodoParams, actor string) (db.Todo, error) {
+	ctx, span := t.start(ctx, "CreateOneTodo")
+	res, err := t.queries.CreateOneTodo(ctx, params, actor)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) GetTodoHistory(ctx context.Context, id int64, limit, offset int) ([]db.History, int64, error) {
+	ctx, span := t.start(ctx, "GetTodoHistory")
+	res, total, err := t.queries.GetTodoHistory(ctx, id, limit, offset)
+	end(span, err)
+	return res, total, err
+}
+
+func (t *traced) GetHistoryOfTodos(ctx context.Context, ids []int64) ([]db.History, error) {
+	ctx, span := t.start(ctx, "GetHistoryOfTodos")
+	res, err := t.queries.GetHistoryOfTodos(ctx, ids)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) GetTrashedTodos(ctx context.Context) ([]db.Todo, error) {
+	ctx, span := t.start(ctx, "GetTrashedTodos")
+	res, err := t.queries.GetTrashedTodos(ctx)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) RestoreOneTodo(ctx context.Context, id int64, actor string) (db.Todo, error) {
+	ctx, span := t.start(ctx, "RestoreOneTodo")
+	res, err := t.queries.RestoreOneTodo(ctx, id, actor)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) PurgeOneTodo(ctx context.Context, id int64, actor string) error {
+	ctx, span := t.start(ctx, "PurgeOneTodo")
+	err := t.queries.PurgeOneTodo(ctx, id, actor)
+	end(span, err)
+	return err
+}
+
+func (t *traced) PurgeTrash(ctx context.Context, before time.Time, actor string) (int64, error) {
+	ctx, span := t.start(ctx, "PurgeTrash")
+	res, err := t.queries.PurgeTrash(ctx, before, actor)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) GetTodoTransitions(ctx context.Context, id int64) ([]db.Transition, error) {
+	ctx, span := t.start(ctx, "GetTodoTransitions")
+	res, err := t.queries.GetTodoTransitions(ctx, id)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) GetTransitionsOfTodos(ctx context.Context, ids []int64) ([]db.Transition, error) {
+	ctx, span := t.start(ctx, "GetTransitionsOfTodos")
+	res, err := t.queries.GetTransitionsOfTodos(ctx, ids)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) GetArchivedTodos(ctx context.Context) ([]db.Todo, error) {
+	ctx, span := t.start(ctx, "GetArchivedTodos")
+	res, err := t.queries.GetArchivedTodos(ctx)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) ArchiveDoneTodos(ctx context.Context, before time.Time, actor string) (int64, error) {
+	ctx, span := t.start(ctx, "ArchiveDoneTodos")
+	res, err := t.queries.ArchiveDoneTodos(ctx, before, actor)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) CountTodos(ctx context.Context, now time.Time) (db.TodoCounts, error) {
+	ctx, span := t.start(ctx, "CountTodos")
+	res, err := t.queries.CountTodos(ctx, now)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) CreateUser(ctx context.Context, name, keyHash string) (db.User, error) {
+	ctx, span := t.start(ctx, "CreateUser")
+	res, err := t.queries.CreateUser(ctx, name, keyHash)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) GetUserByKeyHash(ctx context.Context, keyHash string) (db.User, error) {
+	ctx, span := t.start(ctx, "GetUserByKeyHash")
+	res, err := t.queries.GetUserByKeyHash(ctx, keyHash)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) DisableUser(ctx context.Context, name string) (db.User, error) {
+	ctx, span := t.start(ctx, "DisableUser")
+	res, err := t.queries.DisableUser(ctx, name)
+	end(span, err)
+	return res, err
+}
+
+func (t *traced) WithTx(ctx context.Context, fn func(db.DB) error) error {
+	ctx, span := t.start(ctx, "WithTx")
+	err := t.queries.WithTx(ctx, func(tx db.DB) error {
+		return fn(&traced{queries: tx, tracer: t.tracer})
+	})
+	end(span, err)
+	return err
+}
@@ -0,0 +1,97 @@
+// Package tracing sets up OpenTelemetry tracing of http requests and database queries
+// with W3C trace context propagation.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Name of instrumentation creating spans of the application.
+const instrumentationName = "github.com/vilderxyz/todos"
+
+// Exporters of finished spans.
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// Settings of tracing.
+type Options struct {
+	// Name of the service recorded in spans
+	ServiceName string
+
+	// Exporter of spans: ExporterNone, ExporterStdout or ExporterOTLP
+	Exporter string
+
+	// Address of OTLP gRPC collector, e.g. "localhost:4317", and whether
+	// to connect to it without TLS
+	Endpoint string
+	Insecure bool
+
+	// Fraction of traces started by the application that are sampled,
+	// traces started by callers follow their decision
+	SampleRatio float64
+}
+
+// Installs global tracer provider exporting spans as given in opts and
+// W3C trace context and baggage propagators. Stdout exporter writes spans to w.
+//
+// Returned function flushes remaining spans and stops the exporter.
+// With ExporterNone only propagators are installed, so trace context
+// of incoming requests is still passed on.
+//
+// Throws an error when exporter is unknown or cannot be created.
+func Setup(ctx context.Context, opts Options, w io.Writer) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var err error
+	switch opts.Exporter {
+	case ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(w))
+	case ExporterOTLP:
+		clientOpts := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.Endpoint)}
+		if opts.Insecure {
+			clientOpts = append(clientOpts, otlptracegrpc.WithInsecure())
+		}
+		exporter, err = otlptracegrpc.New(ctx, clientOpts...)
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", opts.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot create tracing exporter: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(opts.ServiceName),
+		)),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(opts.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Returns tracer of the application from global tracer provider.
+func Tracer() trace.Tracer {
+	return otel.Tracer(instrumentationName)
+}
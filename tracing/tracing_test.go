@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/mock"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Installs global tracer provider recording all spans and returns the recorder.
+func record(t *testing.T) *tracetest.SpanRecorder {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	})
+	return recorder
+}
+
+func TestSetup(t *testing.T) {
+	var buf bytes.Buffer
+	shutdown, err := Setup(context.Background(), Options{
+		ServiceName: "todos",
+		Exporter:    ExporterStdout,
+		SampleRatio: 1,
+	}, &buf)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		otel.SetTracerProvider(trace.NewNoopTracerProvider())
+	})
+
+	_, span := Tracer().Start(context.Background(), "test")
+	span.End()
+
+	// Spans are exported in batches, shutdown flushes them
+	require.NoError(t, shutdown(context.Background()))
+	require.Contains(t, buf.String(), `"Name":"test"`)
+	require.Contains(t, buf.String(), `"Value":"todos"`)
+
+	shutdown, err = Setup(context.Background(), Options{Exporter: ExporterNone}, &buf)
+	require.NoError(t, err)
+	require.NoError(t, shutdown(context.Background()))
+	require.Contains(t, otel.GetTextMapPropagator().Fields(), "traceparent")
+
+	_, err = Setup(context.Background(), Options{Exporter: "jaeger"}, &buf)
+	require.Error(t, err)
+}
+
+func TestDB(t *testing.T) {
+	recorder := record(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		WithTx(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, fn func(db.DB) error) error {
+			return fn(model)
+		})
+	model.EXPECT().GetOneTodoById(gomock.Any(), int64(1)).Return(db.Todo{}, errors.New("not found"))
+	model.EXPECT().DeleteOneTodo(gomock.Any(), int64(1), "actor").Return(errors.New("connection refused"))
+
+	queries := DB(model)
+	ctx, parent := Tracer().Start(context.Background(), "request")
+	err := queries.WithTx(ctx, func(tx db.DB) error {
+		if _, err := tx.GetOneTodoById(ctx, 1); err == nil {
+			return errors.New("todo should be missing")
+		}
+		return tx.DeleteOneTodo(ctx, 1, "actor")
+	})
+	require.EqualError(t, err, "connection refused")
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 4)
+	byName := map[string]sdktrace.ReadOnlySpan{}
+	for _, span := range spans {
+		byName[span.Name()] = span
+	}
+
+	tx := byName["db.WithTx"]
+	require.Equal(t, parent.SpanContext().SpanID(), tx.Parent().SpanID())
+	require.Equal(t, codes.Error, tx.Status().Code)
+
+	// Queries are children of the span carried by their context, not of db.WithTx
+	get := byName["db.GetOneTodoById"]
+	require.Equal(t, parent.SpanContext().SpanID(), get.Parent().SpanID())
+	require.Equal(t, codes.Unset, get.Status().Code)
+	require.Equal(t, codes.Error, byName["db.DeleteOneTodo"].Status().Code)
+}
+
+func TestGormPlugin(t *testing.T) {
+	recorder := record(t)
+
+	// Statements are built but not executed, so no database is needed
+	conn, err := gorm.Open(postgres.Open("host=localhost"), &gorm.Config{
+		DryRun:                 true,
+		DisableAutomaticPing:   true,
+		SkipDefaultTransaction: true,
+	})
+	require.NoError(t, err)
+	require.NoError(t, conn.Use(GormPlugin{}))
+
+	ctx, parent := Tracer().Start(context.Background(), "request")
+	var todo db.Todo
+	conn.WithContext(ctx).Where("description = ?", "secret").Find(&todo)
+	conn.WithContext(ctx).Create(&db.Todo{Title: "title", Description: "secret"})
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	require.Equal(t, "gorm.query", spans[0].Name())
+	require.Equal(t, "gorm.create", spans[1].Name())
+
+	for _, span := range spans[:2] {
+		require.Equal(t, parent.SpanContext().SpanID(), span.Parent().SpanID())
+		require.Equal(t, trace.SpanKindClient, span.SpanKind())
+		require.Contains(t, span.Attributes(), semconv.DBSystemPostgreSQL)
+
+		// SQL is recorded with placeholders instead of values
+		for _, attr := range span.Attributes() {
+			if attr.Key == semconv.DBStatementKey {
+				require.NotContains(t, attr.Value.AsString(), "secret")
+				require.Contains(t, attr.Value.AsString(), "$1")
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/vilderxyz/todos/db"
+)
+
+// Permanently removes Todos moved to trash longer than given time ago,
+// all of them by default.
+//
+//	purge-trash [-older-than duration]
+func purgeTrash(args []string) error {
+	flags := flag.NewFlagSet("purge-trash", flag.ExitOnError)
+	olderThan := flags.Duration("older-than", 0, "purge only todos trashed longer than this ago, e.g. 720h")
+	flags.Parse(args)
+
+	conn, err := openDB()
+	if err != nil {
+		return err
+	}
+
+	purged, err := db.New(conn).PurgeTrash(context.Background(), time.Now().Add(-*olderThan), cliActor)
+	if err != nil {
+		return fmt.Errorf("cannot purge trash: %w", err)
+	}
+
+	fmt.Printf("Purged %d todos\n", purged)
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/vilderxyz/todos/api"
+	"github.com/vilderxyz/todos/db"
+)
+
+// Manages users authenticating with API keys.
+//
+//	user create <name>   - creates user and prints its API key
+//	user disable <name>  - disables user, so its API key is no longer accepted
+func user(args []string) error {
+	if len(args) != 2 || args[1] == "" {
+		return fmt.Errorf("usage: user create|disable <name>")
+	}
+	name := args[1]
+
+	conn, err := openDB()
+	if err != nil {
+		return err
+	}
+	queries := db.New(conn)
+	ctx := context.Background()
+
+	switch args[0] {
+	case "create":
+		key, hash, err := api.NewAPIKey()
+		if err != nil {
+			return err
+		}
+		if _, err := queries.CreateUser(ctx, name, hash); err != nil {
+			return fmt.Errorf("cannot create user: %w", err)
+		}
+		fmt.Printf("Created user %v with API key:\n%v\n", name, key)
+		fmt.Println("Store the key now, it cannot be shown again.")
+		return nil
+
+	case "disable":
+		if _, err := queries.DisableUser(ctx, name); err != nil {
+			return fmt.Errorf("cannot disable user: %w", err)
+		}
+		fmt.Printf("Disabled user %v\n", name)
+		return nil
+	}
+	return fmt.Errorf("unknown user command %q", args[0])
+}
@@ -9,6 +9,9 @@ const (
 	ALL      = ""
 )
 
+// All valid periods
+var Periods = []string{TODAY, TOMORROW, WEEK, ALL}
+
 // Custom validator that returns false when string is not one of
 //
 // [ "today" , "tomorrow" , "week" , ""]
@@ -0,0 +1,78 @@
+package watch
+
+import (
+	"sync"
+
+	"github.com/vilderxyz/todos/db"
+)
+
+// Kinds of Todo changes.
+const (
+	Created  = "created"
+	Updated  = "updated"
+	Deleted  = "deleted"
+	Restored = "restored"
+	Purged   = "purged"
+)
+
+// Change of single Todo.
+//
+// Todo holds only Id for deleted and purged Todos.
+type Event struct {
+	Kind string
+	Todo db.Todo
+}
+
+// Delivers published events to all subscribers.
+//
+// Publishing never blocks. Subscribers that do not keep up get
+// their channel closed, so they can subscribe again and resync.
+type Broker struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+}
+
+// Returns Broker without subscribers.
+func NewBroker() *Broker {
+	return &Broker{subscribers: make(map[chan Event]struct{})}
+}
+
+// Returns channel receiving events published from now on
+// and function that cancels the subscription and closes the channel.
+//
+// Buffer is the number of events that can wait for receiver.
+func (b *Broker) Subscribe(buffer int) (<-chan Event, func()) {
+	ch := make(chan Event, buffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(ch)
+	}
+}
+
+// Sends event to all subscribers.
+func (b *Broker) Publish(event Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			b.remove(ch)
+		}
+	}
+}
+
+// Closes subscriber's channel unless it is already removed. Must be called with mu held.
+func (b *Broker) remove(ch chan Event) {
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
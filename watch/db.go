@@ -0,0 +1,89 @@
+package watch
+
+import (
+	"context"
+
+	"github.com/vilderxyz/todos/db"
+)
+
+// Decorator of db.DB that publishes changes of single Todos to broker.
+//
+// Changes made inside WithTx are published after the transaction commits.
+// Bulk operations, purging and archiving many Todos at once, are not published.
+type publisher struct {
+	db.DB
+	broker *Broker
+
+	// Events of uncommitted transaction, nil outside of it
+	pending *[]Event
+}
+
+// Returns queries publishing every change of single Todo made with them to broker.
+func DB(queries db.DB, broker *Broker) db.DB {
+	return &publisher{DB: queries, broker: broker}
+}
+
+// Publishes event or holds it until transaction commits.
+func (p *publisher) publish(kind string, todo db.Todo) {
+	event := Event{Kind: kind, Todo: todo}
+	if p.pending != nil {
+		*p.pending = append(*p.pending, event)
+		return
+	}
+	p.broker.Publish(event)
+}
+
+func (p *publisher) CreateOneTodo(ctx context.Context, params db.CreateTodoParams, actor string) (db.Todo, error) {
+	todo, err := p.DB.CreateOneTodo(ctx, params, actor)
+	if err == nil {
+		p.publish(Created, todo)
+	}
+	return todo, err
+}
+
+func (p *publisher) UpdateOneTodo(ctx context.Context, todo db.Todo, actor string) (db.Todo, error) {
+	todo, err := p.DB.UpdateOneTodo(ctx, todo, actor)
+	if err == nil {
+		p.publish(Updated, todo)
+	}
+	return todo, err
+}
+
+func (p *publisher) DeleteOneTodo(ctx context.Context, id int64, actor string) error {
+	err := p.DB.DeleteOneTodo(ctx, id, actor)
+	if err == nil {
+		p.publish(Deleted, db.Todo{Id: id})
+	}
+	return err
+}
+
+func (p *publisher) RestoreOneTodo(ctx context.Context, id int64, actor string) (db.Todo, error) {
+	todo, err := p.DB.RestoreOneTodo(ctx, id, actor)
+	if err == nil {
+		p.publish(Restored, todo)
+	}
+	return todo, err
+}
+
+func (p *publisher) PurgeOneTodo(ctx context.Context, id int64, actor string) error {
+	err := p.DB.PurgeOneTodo(ctx, id, actor)
+	if err == nil {
+		p.publish(Purged, db.Todo{Id: id})
+	}
+	return err
+}
+
+func (p *publisher) WithTx(ctx context.Context, fn func(db.DB) error) error {
+	var pending []Event
+	err := p.DB.WithTx(ctx, func(tx db.DB) error {
+		pending = nil
+		return fn(&publisher{DB: tx, broker: p.broker, pending: &pending})
+	})
+	if err != nil {
+		return err
+	}
+	for _, event := range pending {
+		p.broker.Publish(event)
+	}
+	return nil
+}
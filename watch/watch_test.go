@@ -0,0 +1,92 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/db"
+	"github.com/vilderxyz/todos/mock"
+)
+
+func TestBroker(t *testing.T) {
+	broker := NewBroker()
+	events, cancel := broker.Subscribe(1)
+	slow, _ := broker.Subscribe(1)
+
+	broker.Publish(Event{Kind: Created, Todo: db.Todo{Id: 1}})
+	require.Equal(t, Event{Kind: Created, Todo: db.Todo{Id: 1}}, <-events)
+
+	// Subscriber with full buffer is dropped
+	broker.Publish(Event{Kind: Updated, Todo: db.Todo{Id: 1}})
+	require.Equal(t, Updated, (<-events).Kind)
+	_, ok := <-slow
+	require.True(t, ok)
+	_, ok = <-slow
+	require.False(t, ok)
+
+	cancel()
+	_, ok = <-events
+	require.False(t, ok)
+	cancel()
+	broker.Publish(Event{Kind: Deleted})
+}
+
+func TestDB(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		WithTx(gomock.Any(), gomock.Any()).
+		AnyTimes().
+		DoAndReturn(func(_ context.Context, fn func(db.DB) error) error {
+			return fn(model)
+		})
+	model.EXPECT().
+		CreateOneTodo(gomock.Any(), gomock.Any(), gomock.Any()).
+		AnyTimes().
+		Return(db.Todo{Id: 1}, nil)
+	model.EXPECT().
+		DeleteOneTodo(gomock.Any(), gomock.Eq(int64(2)), gomock.Any()).
+		Times(1).
+		Return(fmt.Errorf("not found"))
+	model.EXPECT().
+		PurgeOneTodo(gomock.Any(), gomock.Eq(int64(1)), gomock.Any()).
+		Times(1).
+		Return(nil)
+
+	broker := NewBroker()
+	events, cancel := broker.Subscribe(10)
+	defer cancel()
+	queries := DB(model, broker)
+
+	_, err := queries.CreateOneTodo(ctx, db.CreateTodoParams{}, "alice")
+	require.NoError(t, err)
+	require.Equal(t, Event{Kind: Created, Todo: db.Todo{Id: 1}}, <-events)
+
+	// Failed changes are not published
+	require.Error(t, queries.DeleteOneTodo(ctx, 2, "alice"))
+	require.Empty(t, events)
+
+	// Rolled back transaction publishes nothing
+	err = queries.WithTx(ctx, func(tx db.DB) error {
+		if _, err := tx.CreateOneTodo(ctx, db.CreateTodoParams{}, "alice"); err != nil {
+			return err
+		}
+		require.Empty(t, events)
+		return fmt.Errorf("rollback")
+	})
+	require.Error(t, err)
+	require.Empty(t, events)
+
+	// Committed transaction publishes its changes
+	err = queries.WithTx(ctx, func(tx db.DB) error {
+		return tx.PurgeOneTodo(ctx, 1, "alice")
+	})
+	require.NoError(t, err)
+	require.Equal(t, Event{Kind: Purged, Todo: db.Todo{Id: 1}}, <-events)
+}
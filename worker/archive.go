@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/vilderxyz/todos/db"
+	"golang.org/x/exp/slog"
+)
+
+// Actor recorded in history of Todos archived automatically.
+const ArchiverActor = "system:archiver"
+
+// Returns Job that archives Todos finished longer than age ago.
+func ArchiveDone(queries db.DB, age, interval time.Duration) Job {
+	return Job{
+		Name:     "archive-done",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			archived, err := queries.ArchiveDoneTodos(ctx, time.Now().Add(-age), ArchiverActor)
+			if err != nil {
+				return err
+			}
+			if archived > 0 {
+				slog.InfoCtx(ctx, "archived done todos", "count", archived)
+			}
+			return nil
+		},
+	}
+}
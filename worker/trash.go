@@ -0,0 +1,30 @@
+package worker
+
+import (
+	"context"
+	"time"
+
+	"github.com/vilderxyz/todos/db"
+	"golang.org/x/exp/slog"
+)
+
+// Actor recorded in history of Todos purged by retention policy.
+const RetentionActor = "system:retention"
+
+// Returns Job that permanently removes Todos kept in trash longer than retention.
+func PurgeTrash(queries db.DB, retention, interval time.Duration) Job {
+	return Job{
+		Name:     "purge-trash",
+		Interval: interval,
+		Run: func(ctx context.Context) error {
+			purged, err := queries.PurgeTrash(ctx, time.Now().Add(-retention), RetentionActor)
+			if err != nil {
+				return err
+			}
+			if purged > 0 {
+				slog.InfoCtx(ctx, "purged todos from trash", "count", purged)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package worker
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"golang.org/x/exp/slog"
+)
+
+// Background task run periodically by Worker.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Runs single Job in its own goroutine until stopped.
+type Worker struct {
+	job    Job
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu      sync.Mutex
+	running bool
+}
+
+// Starts Worker that runs job immediately and then every job's Interval.
+//
+// Errors returned by the job are logged and do not stop the Worker.
+func Start(job Job) *Worker {
+	ctx, cancel := context.WithCancel(context.Background())
+	w := &Worker{
+		job:     job,
+		cancel:  cancel,
+		done:    make(chan struct{}),
+		running: true,
+	}
+	go w.loop(ctx)
+	return w
+}
+
+func (w *Worker) loop(ctx context.Context) {
+	defer close(w.done)
+	defer w.setRunning(false)
+
+	ticker := time.NewTicker(w.job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.job.Run(ctx); err != nil && ctx.Err() == nil {
+			slog.ErrorCtx(ctx, "job failed", "job", w.job.Name, "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Stops the Worker and waits for the job in progress to finish.
+func (w *Worker) Stop() {
+	w.cancel()
+	<-w.done
+}
+
+// Returns name of the Worker's job.
+func (w *Worker) Name() string {
+	return w.job.Name
+}
+
+// Returns true until the Worker is stopped.
+func (w *Worker) Running() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.running
+}
+
+func (w *Worker) setRunning(running bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.running = running
+}
@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"github.com/vilderxyz/todos/mock"
+)
+
+func TestWorker(t *testing.T) {
+	var runs int32
+	w := Start(Job{
+		Name:     "test",
+		Interval: time.Millisecond,
+		Run: func(ctx context.Context) error {
+			atomic.AddInt32(&runs, 1)
+			return errors.New("errors do not stop the worker")
+		},
+	})
+	require.True(t, w.Running())
+	require.Equal(t, "test", w.Name())
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt32(&runs) >= 3
+	}, time.Second, time.Millisecond)
+
+	w.Stop()
+	require.False(t, w.Running())
+
+	stopped := atomic.LoadInt32(&runs)
+	time.Sleep(10 * time.Millisecond)
+	require.Equal(t, stopped, atomic.LoadInt32(&runs))
+}
+
+func TestPurgeTrash(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		PurgeTrash(gomock.Any(), gomock.Any(), gomock.Eq(RetentionActor)).
+		Times(1).
+		DoAndReturn(func(_ context.Context, before time.Time, actor string) (int64, error) {
+			require.WithinDuration(t, time.Now().Add(-time.Hour), before, time.Second)
+			return 2, nil
+		})
+
+	job := PurgeTrash(model, time.Hour, time.Minute)
+	require.NoError(t, job.Run(context.Background()))
+}
+
+func TestArchiveDone(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	model := mock.NewMockDB(ctrl)
+	model.EXPECT().
+		ArchiveDoneTodos(gomock.Any(), gomock.Any(), gomock.Eq(ArchiverActor)).
+		Times(1).
+		DoAndReturn(func(_ context.Context, before time.Time, actor string) (int64, error) {
+			require.WithinDuration(t, time.Now().Add(-24*time.Hour), before, time.Second)
+			return 0, nil
+		})
+
+	job := ArchiveDone(model, 24*time.Hour, time.Minute)
+	require.NoError(t, job.Run(context.Background()))
+}
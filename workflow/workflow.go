@@ -0,0 +1,144 @@
+package workflow
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Statuses of the default workflow.
+const (
+	Backlog    = "backlog"
+	InProgress = "in_progress"
+	Blocked    = "blocked"
+	Done       = "done"
+	Reopened   = "reopened"
+)
+
+// Default workflow.
+//
+//	backlog     -> in_progress, blocked, done
+//	in_progress -> blocked, done, backlog
+//	blocked     -> in_progress, backlog
+//	done        -> reopened
+//	reopened    -> in_progress, blocked, done
+var Default = &Machine{
+	initial: Backlog,
+	transitions: map[string][]string{
+		Backlog:    {InProgress, Blocked, Done},
+		InProgress: {Blocked, Done, Backlog},
+		Blocked:    {InProgress, Backlog},
+		Done:       {Reopened},
+		Reopened:   {InProgress, Blocked, Done},
+	},
+}
+
+// State machine of Todo statuses.
+//
+// New Todos start in initial status and may only move
+// to statuses allowed by transitions.
+type Machine struct {
+	initial     string
+	transitions map[string][]string
+}
+
+// Error returned when status cannot be changed.
+type TransitionError struct {
+	From    string
+	To      string
+	Allowed []string
+}
+
+func (e *TransitionError) Error() string {
+	if len(e.Allowed) == 0 {
+		return fmt.Sprintf("cannot change status from %q to %q", e.From, e.To)
+	}
+	return fmt.Sprintf("cannot change status from %q to %q, allowed: %v",
+		e.From, e.To, strings.Join(e.Allowed, ", "))
+}
+
+// Parses workflow in format "from>to,to;from>to".
+//
+// Status on the left side of the first rule is the initial one.
+// Every status must have its own rule and workflow must contain "done" status.
+//
+// Example:
+//
+//	"todo>doing,done;doing>todo,done;done>doing"
+func Parse(spec string) (*Machine, error) {
+	m := &Machine{transitions: map[string][]string{}}
+
+	for _, rule := range strings.Split(spec, ";") {
+		from, to, ok := strings.Cut(strings.TrimSpace(rule), ">")
+		from = strings.TrimSpace(from)
+		if !ok || from == "" {
+			return nil, fmt.Errorf("invalid workflow rule %q", rule)
+		}
+		if _, exists := m.transitions[from]; exists {
+			return nil, fmt.Errorf("duplicated workflow rule for %q", from)
+		}
+		if m.initial == "" {
+			m.initial = from
+		}
+
+		targets := []string{}
+		for _, target := range strings.Split(to, ",") {
+			if target = strings.TrimSpace(target); target != "" {
+				targets = append(targets, target)
+			}
+		}
+		m.transitions[from] = targets
+	}
+
+	for from, targets := range m.transitions {
+		for _, target := range targets {
+			if !m.Valid(target) {
+				return nil, fmt.Errorf("status %q allowed after %q has no workflow rule", target, from)
+			}
+		}
+	}
+	if !m.Valid(Done) {
+		return nil, fmt.Errorf("workflow must contain %q status", Done)
+	}
+	return m, nil
+}
+
+// Returns status of newly created Todos.
+func (m *Machine) Initial() string {
+	return m.initial
+}
+
+// Returns true when status belongs to the workflow.
+func (m *Machine) Valid(status string) bool {
+	_, ok := m.transitions[status]
+	return ok
+}
+
+// Returns statuses allowed after given one.
+func (m *Machine) Next(from string) []string {
+	return m.transitions[from]
+}
+
+// Checks whether status can be changed from one to another.
+//
+// Returns *TransitionError when it cannot.
+func (m *Machine) Transition(from, to string) error {
+	allowed := m.Next(from)
+	for _, status := range allowed {
+		if status == to {
+			return nil
+		}
+	}
+	return &TransitionError{From: from, To: to, Allowed: allowed}
+}
+
+// Returns status that finished Todos move to when they are reopened,
+// which is the first one allowed after "done".
+//
+// Returns false when finished Todos cannot be reopened.
+func (m *Machine) Reopen() (string, bool) {
+	next := m.Next(Done)
+	if len(next) == 0 {
+		return "", false
+	}
+	return next[0], true
+}
@@ -0,0 +1,57 @@
+package workflow
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultWorkflow(t *testing.T) {
+	require.Equal(t, Backlog, Default.Initial())
+
+	require.NoError(t, Default.Transition(Backlog, InProgress))
+	require.NoError(t, Default.Transition(InProgress, Done))
+	require.NoError(t, Default.Transition(Done, Reopened))
+	require.NoError(t, Default.Transition(Reopened, Done))
+
+	err := Default.Transition(Done, InProgress)
+	require.Error(t, err)
+
+	var transitionErr *TransitionError
+	require.True(t, errors.As(err, &transitionErr))
+	require.Equal(t, Done, transitionErr.From)
+	require.Equal(t, InProgress, transitionErr.To)
+	require.Equal(t, []string{Reopened}, transitionErr.Allowed)
+
+	status, ok := Default.Reopen()
+	require.True(t, ok)
+	require.Equal(t, Reopened, status)
+}
+
+func TestParse(t *testing.T) {
+	m, err := Parse("todo>doing,done; doing>todo,done; done>")
+	require.NoError(t, err)
+	require.Equal(t, "todo", m.Initial())
+	require.True(t, m.Valid("doing"))
+	require.False(t, m.Valid(Backlog))
+	require.NoError(t, m.Transition("doing", Done))
+	require.Error(t, m.Transition(Done, "todo"))
+
+	_, ok := m.Reopen()
+	require.False(t, ok)
+
+	testCases := map[string]string{
+		"missing arrow":    "todo",
+		"missing done":     "todo>doing;doing>todo",
+		"unknown target":   "todo>done,doing;done>todo",
+		"duplicated rule":  "todo>done;done>todo;todo>done",
+		"empty from state": ">done;done>",
+	}
+	for name, spec := range testCases {
+		t.Run(name, func(t *testing.T) {
+			_, err := Parse(spec)
+			require.Error(t, err)
+		})
+	}
+}